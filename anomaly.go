@@ -0,0 +1,295 @@
+package main
+
+import (
+	"io"
+	"math"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/fsnotify/fsnotify"
+)
+
+type AnomalyOptions struct {
+	// WindowSeconds is how far back changes are counted; 0 means 10.
+	WindowSeconds int `json:"windowSeconds"`
+	// Threshold is how many files must change suspiciously within the
+	// window to raise an alert; 0 means 20.
+	Threshold int `json:"threshold"`
+	// MinEntropy, in bits per byte, is how random rewritten contents must
+	// look to count; 0 means 7.2. Encrypted data is close to 8.
+	MinEntropy float64 `json:"minEntropy"`
+}
+
+type ProcessInfo struct {
+	PID   int      `json:"pid"`
+	Name  string   `json:"name"`
+	Paths []string `json:"paths"`
+}
+
+type AnomalyAlert struct {
+	Time      time.Time `json:"time" ts_type:"string"`
+	Root      string    `json:"root"`
+	Renamed   int       `json:"renamed"`
+	Rewritten int       `json:"rewritten"`
+	// Entropy is the mean entropy of the rewritten files, in bits per byte.
+	Entropy float64  `json:"entropy"`
+	Paths   []string `json:"paths"`
+	// Processes had affected files open when the alert was raised, where
+	// the platform allows finding out.
+	Processes []ProcessInfo `json:"processes"`
+}
+
+type WatchAnomaliesRequest struct {
+	Root    string         `json:"root"`
+	Options AnomalyOptions `json:"options"`
+}
+
+type AnomalyAlertList struct {
+	Alerts []AnomalyAlert `json:"alerts"`
+}
+
+const (
+	maxAnomalyAlerts  = 100
+	entropySampleSize = 64 << 10
+)
+
+// compressedExts are formats whose contents already look random, so a
+// high entropy on first sight means nothing for them.
+var compressedExts = map[string]bool{
+	".zip": true, ".gz": true, ".tgz": true, ".bz2": true, ".xz": true, ".zst": true, ".7z": true, ".rar": true,
+	".jpg": true, ".jpeg": true, ".png": true, ".gif": true, ".webp": true, ".heic": true,
+	".mp3": true, ".mp4": true, ".mkv": true, ".mov": true, ".webm": true, ".ogg": true, ".flac": true,
+	".pdf": true, ".docx": true, ".xlsx": true, ".pptx": true, ".odt": true, ".jar": true, ".apk": true,
+}
+
+// WatchAnomalies watches req.Root for bursts of renames and rewrites to
+// random-looking contents, as ransomware or a runaway script would cause,
+// and emits an "anomaly:alert" event with an AnomalyAlert for each. It
+// replaces any previous watch.
+func (a *App) WatchAnomalies(req WatchAnomaliesRequest) error {
+	root := filepath.Clean(req.Root)
+	a.StopWatchAnomalies()
+	d := newAnomalyDetector(root, req.Options)
+
+	tw, err := newTreeWatcher(root, func(ev fsnotify.Event) {
+		e := anomalyEvent{at: time.Now(), path: ev.Name}
+		switch {
+		case ev.Has(fsnotify.Rename):
+			e.renamed = true
+		case ev.Has(fsnotify.Create), ev.Has(fsnotify.Write):
+			// The file is read before taking the lock, so a slow disk
+			// doesn't hold up AnomalyAlerts.
+			ent, ok := writeEntropy(ev.Name)
+			if !ok {
+				return
+			}
+			e.entropy = ent
+		default:
+			return
+		}
+
+		a.anomalyMu.Lock()
+		alert := d.observe(e)
+		a.anomalyMu.Unlock()
+		if alert == nil {
+			return
+		}
+		alert.Processes = processesHolding(alert.Paths)
+		if alert.Processes == nil {
+			alert.Processes = []ProcessInfo{}
+		}
+		a.anomalyMu.Lock()
+		a.anomalies = append(a.anomalies, *alert)
+		if n := len(a.anomalies); n > maxAnomalyAlerts {
+			a.anomalies = append([]AnomalyAlert(nil), a.anomalies[n-maxAnomalyAlerts:]...)
+		}
+		a.anomalyMu.Unlock()
+		a.emit("anomaly:alert", *alert)
+	})
+	if err != nil {
+		return err
+	}
+	a.anomalyMu.Lock()
+	a.anomalyWatch = tw
+	a.anomalyMu.Unlock()
+	return nil
+}
+
+func (a *App) StopWatchAnomalies() {
+	a.anomalyMu.Lock()
+	tw := a.anomalyWatch
+	a.anomalyWatch = nil
+	a.anomalyMu.Unlock()
+	if tw != nil {
+		tw.Close()
+	}
+}
+
+// AnomalyAlerts returns the alerts raised so far, newest first.
+func (a *App) AnomalyAlerts() AnomalyAlertList {
+	a.anomalyMu.Lock()
+	defer a.anomalyMu.Unlock()
+	out := make([]AnomalyAlert, len(a.anomalies))
+	for i, al := range a.anomalies {
+		out[len(a.anomalies)-1-i] = al
+	}
+	return AnomalyAlertList{Alerts: out}
+}
+
+type anomalyEvent struct {
+	at      time.Time
+	path    string
+	renamed bool
+	entropy float64
+}
+
+// anomalyDetector counts suspicious changes in a sliding window. A rename
+// is suspicious on its own; a write is when the file's contents become
+// near-random, having been less random before, or for a file not seen
+// before, when its type isn't one that is compressed anyway.
+type anomalyDetector struct {
+	root      string
+	window    time.Duration
+	threshold int
+	minEnt    float64
+
+	// entropy holds the last write seen to each path within the window.
+	entropy   map[string]anomalyEvent
+	events    []anomalyEvent
+	lastAlert time.Time
+	lastSweep time.Time
+}
+
+func newAnomalyDetector(root string, opts AnomalyOptions) *anomalyDetector {
+	d := &anomalyDetector{
+		root:      root,
+		window:    time.Duration(opts.WindowSeconds) * time.Second,
+		threshold: opts.Threshold,
+		minEnt:    opts.MinEntropy,
+		entropy:   make(map[string]anomalyEvent),
+	}
+	if d.window <= 0 {
+		d.window = 10 * time.Second
+	}
+	if d.threshold <= 0 {
+		d.threshold = 20
+	}
+	if d.minEnt <= 0 {
+		d.minEnt = 7.2
+	}
+	return d
+}
+
+// observe records a rename, or a write with the entropy writeEntropy
+// found, and returns an alert if it tips the window over the threshold.
+// After an alert the window starts afresh, so one burst raises one alert
+// per window.
+func (d *anomalyDetector) observe(ev anomalyEvent) *AnomalyAlert {
+	now, path := ev.at, ev.path
+	keep := d.events[:0]
+	for _, e := range d.events {
+		if now.Sub(e.at) <= d.window {
+			keep = append(keep, e)
+		}
+	}
+	d.events = keep
+	if now.Sub(d.lastSweep) >= d.window {
+		for p, e := range d.entropy {
+			if now.Sub(e.at) > d.window {
+				delete(d.entropy, p)
+			}
+		}
+		d.lastSweep = now
+	}
+
+	if ev.renamed {
+		delete(d.entropy, path)
+		d.events = append(d.events, ev)
+	} else {
+		prev, known := d.entropy[path]
+		known = known && now.Sub(prev.at) <= d.window
+		d.entropy[path] = ev
+		rising := known && ev.entropy-prev.entropy >= 1 || !known && !compressedExts[strings.ToLower(filepath.Ext(path))]
+		if ev.entropy < d.minEnt || !rising {
+			return nil
+		}
+		d.events = append(d.events, ev)
+	}
+
+	paths := make(map[string]bool)
+	for _, e := range d.events {
+		paths[e.path] = true
+	}
+	if len(paths) < d.threshold || now.Sub(d.lastAlert) < d.window {
+		return nil
+	}
+
+	alert := &AnomalyAlert{Time: now, Root: d.root, Paths: make([]string, 0, len(paths))}
+	var sum float64
+	for _, e := range d.events {
+		if e.renamed {
+			alert.Renamed++
+		} else {
+			alert.Rewritten++
+			sum += e.entropy
+		}
+	}
+	if alert.Rewritten > 0 {
+		alert.Entropy = sum / float64(alert.Rewritten)
+	}
+	for p := range paths {
+		alert.Paths = append(alert.Paths, p)
+	}
+	sort.Strings(alert.Paths)
+	d.lastAlert = now
+	d.events = nil
+	return alert
+}
+
+// writeEntropy returns the entropy of path after a write, or false when it
+// is gone, empty or not a regular file.
+func writeEntropy(path string) (float64, bool) {
+	info, err := os.Stat(path)
+	if err != nil || !info.Mode().IsRegular() || info.Size() == 0 {
+		return 0, false
+	}
+	ent, err := fileEntropy(path)
+	return ent, err == nil
+}
+
+// fileEntropy returns the Shannon entropy, in bits per byte, of the start
+// of path.
+func fileEntropy(path string) (float64, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return 0, err
+	}
+	defer f.Close()
+	buf := make([]byte, entropySampleSize)
+	n, err := io.ReadFull(f, buf)
+	if err != nil && err != io.ErrUnexpectedEOF {
+		return 0, err
+	}
+	return shannonEntropy(buf[:n]), nil
+}
+
+func shannonEntropy(data []byte) float64 {
+	if len(data) == 0 {
+		return 0
+	}
+	var counts [256]int
+	for _, b := range data {
+		counts[b]++
+	}
+	var h float64
+	for _, c := range counts {
+		if c > 0 {
+			p := float64(c) / float64(len(data))
+			h -= p * math.Log2(p)
+		}
+	}
+	return h
+}
@@ -0,0 +1,148 @@
+package main
+
+import (
+	"fmt"
+	"math/rand"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+func randomBytes(n int) string {
+	b := make([]byte, n)
+	rand.New(rand.NewSource(1)).Read(b)
+	return string(b)
+}
+
+func TestShannonEntropy(t *testing.T) {
+	tests := []struct {
+		data     string
+		min, max float64
+	}{
+		{"", 0, 0},
+		{"aaaa", 0, 0},
+		{"abab", 1, 1},
+		{strings.Repeat("the quick brown fox ", 100), 3, 4.5},
+		{randomBytes(1 << 16), 7.9, 8},
+	}
+	for _, tt := range tests {
+		if h := shannonEntropy([]byte(tt.data)); h < tt.min-1e-9 || h > tt.max+1e-9 {
+			t.Errorf("entropy of %.10q = %f, want %f..%f", tt.data, h, tt.min, tt.max)
+		}
+	}
+}
+
+func TestAnomalyDetector(t *testing.T) {
+	root := t.TempDir()
+	files := map[string]string{}
+	for i := 0; i < 5; i++ {
+		files[fmt.Sprintf("doc%d.txt", i)] = strings.Repeat("plain text ", 200)
+		files[fmt.Sprintf("photo%d.jpg", i)] = randomBytes(8 << 10)
+	}
+	writeFiles(t, root, files)
+	path := func(name string) string { return filepath.Join(root, name) }
+	start := time.Now()
+	rename := func(d *anomalyDetector, at time.Time, name string) *AnomalyAlert {
+		return d.observe(anomalyEvent{at: at, path: path(name), renamed: true})
+	}
+	write := func(d *anomalyDetector, at time.Time, name string) *AnomalyAlert {
+		ent, ok := writeEntropy(path(name))
+		if !ok {
+			return nil
+		}
+		return d.observe(anomalyEvent{at: at, path: path(name), entropy: ent})
+	}
+
+	tests := []struct {
+		name   string
+		opts   AnomalyOptions
+		events func(d *anomalyDetector) *AnomalyAlert
+		want   int
+	}{
+		{"plain writes", AnomalyOptions{Threshold: 3}, func(d *anomalyDetector) *AnomalyAlert {
+			var alert *AnomalyAlert
+			for i := 0; i < 5; i++ {
+				if al := write(d, start, fmt.Sprintf("doc%d.txt", i)); al != nil {
+					alert = al
+				}
+			}
+			return alert
+		}, 0},
+		{"compressed files", AnomalyOptions{Threshold: 3}, func(d *anomalyDetector) *AnomalyAlert {
+			var alert *AnomalyAlert
+			for i := 0; i < 5; i++ {
+				if al := write(d, start, fmt.Sprintf("photo%d.jpg", i)); al != nil {
+					alert = al
+				}
+			}
+			return alert
+		}, 0},
+		{"renames", AnomalyOptions{Threshold: 3}, func(d *anomalyDetector) *AnomalyAlert {
+			rename(d, start, "a")
+			rename(d, start, "b")
+			return rename(d, start, "c")
+		}, 3},
+		{"renames outside the window", AnomalyOptions{Threshold: 3, WindowSeconds: 1}, func(d *anomalyDetector) *AnomalyAlert {
+			rename(d, start, "a")
+			rename(d, start, "b")
+			return rename(d, start.Add(2*time.Second), "c")
+		}, 0},
+		{"encrypted rewrites", AnomalyOptions{Threshold: 3}, func(d *anomalyDetector) *AnomalyAlert {
+			for i := 0; i < 3; i++ {
+				write(d, start, fmt.Sprintf("doc%d.txt", i))
+			}
+			for i := 0; i < 3; i++ {
+				os.WriteFile(path(fmt.Sprintf("doc%d.txt", i)), []byte(randomBytes(8<<10)), 0o644)
+			}
+			write(d, start, "doc0.txt")
+			write(d, start, "doc1.txt")
+			return write(d, start, "doc2.txt")
+		}, 3},
+	}
+	for _, tt := range tests {
+		alert := tt.events(newAnomalyDetector(root, tt.opts))
+		got := 0
+		if alert != nil {
+			got = len(alert.Paths)
+		}
+		if got != tt.want {
+			t.Errorf("%s: alert on %d paths, want %d", tt.name, got, tt.want)
+		}
+	}
+
+	// Writes seen longer than a window ago are forgotten.
+	d := newAnomalyDetector(root, AnomalyOptions{WindowSeconds: 1})
+	for i := 0; i < 5; i++ {
+		write(d, start, fmt.Sprintf("doc%d.txt", i))
+	}
+	write(d, start.Add(2*time.Second), "doc0.txt")
+	if len(d.entropy) != 1 {
+		t.Errorf("%d files remembered, want 1", len(d.entropy))
+	}
+}
+
+func TestWatchAnomalies(t *testing.T) {
+	root := t.TempDir()
+	a := NewApp()
+	if err := a.WatchAnomalies(WatchAnomaliesRequest{Root: root, Options: AnomalyOptions{Threshold: 5}}); err != nil {
+		t.Fatal(err)
+	}
+	defer a.StopWatchAnomalies()
+
+	for i := 0; i < 6; i++ {
+		writeFiles(t, root, map[string]string{fmt.Sprintf("f%d.locked", i): randomBytes(8 << 10)})
+	}
+	deadline := time.Now().Add(5 * time.Second)
+	for len(a.AnomalyAlerts().Alerts) == 0 {
+		if time.Now().After(deadline) {
+			t.Fatal("no alert raised")
+		}
+		time.Sleep(20 * time.Millisecond)
+	}
+	alert := a.AnomalyAlerts().Alerts[0]
+	if alert.Rewritten < 5 || alert.Entropy < 7.9 || alert.Root != root {
+		t.Errorf("alert = %+v", alert)
+	}
+}
@@ -0,0 +1,137 @@
+package main
+
+import (
+	"errors"
+	"os"
+	"reflect"
+	goruntime "runtime"
+	"sort"
+)
+
+// APIVersion is the version of the methods bound to the frontend and the
+// types they use. The major number changes when one changes incompatibly,
+// the minor number when methods or fields are added.
+const APIVersion = "2.0"
+
+// NodeType is what a FileNode stands for.
+type NodeType string
+
+const (
+	NodeFile   NodeType = "file"
+	NodeFolder NodeType = "folder"
+	// NodeData is a value inside a JSON, YAML or TOML document.
+	NodeData NodeType = "data"
+	// NodeDecl is a declaration in a Go package.
+	NodeDecl NodeType = "decl"
+)
+
+// AllNodeTypes lists every NodeType. FileNode.Type reaches TypeScript as a
+// union of these, which TestTSUnions keeps in step.
+var AllNodeTypes = []NodeType{NodeFile, NodeFolder, NodeData, NodeDecl}
+
+// NodeKind refines a NodeType: the type of a data value, the kind of a Go
+// declaration, or where a folder came from.
+type NodeKind string
+
+const (
+	KindObject   NodeKind = "object"
+	KindArray    NodeKind = "array"
+	KindString   NodeKind = "string"
+	KindNumber   NodeKind = "number"
+	KindBoolean  NodeKind = "boolean"
+	KindNull     NodeKind = "null"
+	KindDatetime NodeKind = "datetime"
+	// KindStream is a YAML file holding several documents.
+	KindStream NodeKind = "stream"
+
+	KindPackage NodeKind = "package"
+	KindFunc    NodeKind = "func"
+	KindMethod  NodeKind = "method"
+	KindType    NodeKind = "type"
+	KindConst   NodeKind = "const"
+	KindVar     NodeKind = "var"
+
+	// KindPathEntry is a folder listed in the PATH variable.
+	KindPathEntry NodeKind = "path-entry"
+)
+
+// AllNodeKinds lists every NodeKind, which reach TypeScript the same way.
+var AllNodeKinds = []NodeKind{
+	KindObject, KindArray, KindString, KindNumber, KindBoolean, KindNull, KindDatetime, KindStream,
+	KindPackage, KindFunc, KindMethod, KindType, KindConst, KindVar,
+	KindPathEntry,
+}
+
+// Bound methods take at most one argument, a request struct, and return
+// a struct, so fields can be added without breaking callers. These are
+// shared by the methods that need nothing more.
+
+type PathRequest struct {
+	Path string `json:"path"`
+}
+
+type RootRequest struct {
+	Root string `json:"root"`
+}
+
+type NodeList struct {
+	Nodes []FileNode `json:"nodes"`
+}
+
+type APIInfo struct {
+	Version string `json:"version"`
+	// Platform is the operating system the backend runs on, as GOOS.
+	Platform string `json:"platform"`
+	// Capabilities names the optional features that work on this platform:
+	// "diskSpace" for filesystem sizes, "processes" for the processes
+	// holding files in anomaly alerts.
+	Capabilities []string    `json:"capabilities"`
+	Methods      []APIMethod `json:"methods"`
+}
+
+// APIMethod describes a bound method by the request and response types it
+// takes and returns.
+type APIMethod struct {
+	Name    string   `json:"name"`
+	Params  []string `json:"params"`
+	Results []string `json:"results"`
+}
+
+// APIInfo describes the backend, so the frontend can check it talks to a
+// compatible version and hide what the platform doesn't support.
+func (a *App) APIInfo() APIInfo {
+	info := APIInfo{
+		Version:      APIVersion,
+		Platform:     goruntime.GOOS,
+		Capabilities: []string{},
+		Methods:      apiMethods(),
+	}
+	if _, _, err := diskSpace(os.TempDir()); !errors.Is(err, errors.ErrUnsupported) {
+		info.Capabilities = append(info.Capabilities, "diskSpace")
+	}
+	if canFindProcesses {
+		info.Capabilities = append(info.Capabilities, "processes")
+	}
+	return info
+}
+
+// apiMethods lists the exported methods of App, which are the ones bound
+// to the frontend, sorted by name.
+func apiMethods() []APIMethod {
+	t := reflect.TypeOf(&App{})
+	methods := make([]APIMethod, 0, t.NumMethod())
+	for i := 0; i < t.NumMethod(); i++ {
+		m := t.Method(i)
+		am := APIMethod{Name: m.Name, Params: []string{}, Results: []string{}}
+		// The first parameter is the receiver.
+		for j := 1; j < m.Type.NumIn(); j++ {
+			am.Params = append(am.Params, m.Type.In(j).String())
+		}
+		for j := 0; j < m.Type.NumOut(); j++ {
+			am.Results = append(am.Results, m.Type.Out(j).String())
+		}
+		methods = append(methods, am)
+	}
+	sort.Slice(methods, func(i, j int) bool { return methods[i].Name < methods[j].Name })
+	return methods
+}
@@ -0,0 +1,203 @@
+package main
+
+import (
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestAPIInfoMethods(t *testing.T) {
+	info := NewApp().APIInfo()
+	if info.Version != APIVersion {
+		t.Errorf("version = %q, want %q", info.Version, APIVersion)
+	}
+	byName := make(map[string]APIMethod)
+	for _, m := range info.Methods {
+		byName[m.Name] = m
+	}
+	tests := []struct {
+		name    string
+		params  []string
+		results []string
+	}{
+		{"ReadDir", []string{"main.PathRequest"}, []string{"main.NodeList", "error"}},
+		{"StartScan", []string{"main.ScanOptions"}, []string{"error"}},
+		{"StopScan", []string{}, []string{}},
+		{"APIInfo", []string{}, []string{"main.APIInfo"}},
+	}
+	for _, tt := range tests {
+		m, ok := byName[tt.name]
+		if !ok {
+			t.Errorf("%s not listed", tt.name)
+			continue
+		}
+		if !reflect.DeepEqual(m.Params, tt.params) || !reflect.DeepEqual(m.Results, tt.results) {
+			t.Errorf("%s(%v) %v, want (%v) %v", tt.name, m.Params, m.Results, tt.params, tt.results)
+		}
+	}
+	if _, ok := byName["startup"]; ok {
+		t.Error("unexported methods are listed")
+	}
+}
+
+// TestAPITypes keeps the bound surface fully typed: no interface values,
+// which would reach TypeScript as any, and a JSON name on every field. It
+// also holds methods to one request struct in and one struct out, so
+// either can grow fields without breaking callers.
+func TestAPITypes(t *testing.T) {
+	errType := reflect.TypeOf((*error)(nil)).Elem()
+	timeType := reflect.TypeOf(time.Time{})
+	seen := make(map[reflect.Type]bool)
+
+	var check func(where string, typ reflect.Type)
+	check = func(where string, typ reflect.Type) {
+		if seen[typ] || typ == timeType {
+			return
+		}
+		seen[typ] = true
+		switch typ.Kind() {
+		case reflect.Interface:
+			t.Errorf("%s: untyped %s", where, typ)
+		case reflect.Pointer, reflect.Slice, reflect.Array:
+			check(where, typ.Elem())
+		case reflect.Map:
+			check(where, typ.Key())
+			check(where, typ.Elem())
+		case reflect.Struct:
+			for i := 0; i < typ.NumField(); i++ {
+				f := typ.Field(i)
+				if !f.IsExported() {
+					continue
+				}
+				tag := f.Tag.Get("json")
+				if tag == "" || strings.HasPrefix(tag, ",") {
+					t.Errorf("%s: %s.%s has no JSON name", where, typ.Name(), f.Name)
+				}
+				if f.Type == timeType && f.Tag.Get("ts_type") != "string" {
+					t.Errorf("%s: %s.%s needs ts_type:\"string\"", where, typ.Name(), f.Name)
+				}
+				check(where, f.Type)
+			}
+		}
+	}
+
+	app := reflect.TypeOf(&App{})
+	for i := 0; i < app.NumMethod(); i++ {
+		m := app.Method(i)
+		if m.Type.NumIn() > 2 {
+			t.Errorf("%s: takes %d arguments, want a request struct", m.Name, m.Type.NumIn()-1)
+		}
+		for j := 1; j < m.Type.NumIn(); j++ {
+			if in := m.Type.In(j); in.Kind() != reflect.Struct {
+				t.Errorf("%s: takes %s, want a request struct", m.Name, in)
+			}
+			check(m.Name, m.Type.In(j))
+		}
+		for j := 0; j < m.Type.NumOut(); j++ {
+			out := m.Type.Out(j)
+			if out == errType {
+				if j != m.Type.NumOut()-1 {
+					t.Errorf("%s: error is not the last result", m.Name)
+				}
+				continue
+			}
+			if out.Kind() != reflect.Struct || j > 0 {
+				t.Errorf("%s: returns %s, want one struct", m.Name, out)
+			}
+			check(m.Name, out)
+		}
+	}
+}
+
+func TestNodeEnums(t *testing.T) {
+	types := make(map[NodeType]bool)
+	for _, v := range AllNodeTypes {
+		if types[v] {
+			t.Errorf("node type %q listed twice", v)
+		}
+		types[v] = true
+	}
+	kinds := make(map[NodeKind]bool)
+	for _, v := range AllNodeKinds {
+		if kinds[v] {
+			t.Errorf("node kind %q listed twice", v)
+		}
+		kinds[v] = true
+	}
+
+	dir := t.TempDir()
+	writeFiles(t, dir, map[string]string{
+		"a.go":      "package demo\n\nconst C = 1\n\nvar V int\n\ntype T struct{}\n\nfunc (T) M() {}\n\nfunc F() {}\n",
+		"data.json": `{"s": "x", "n": 1, "b": true, "z": null, "a": [{}]}`,
+		"docs.yaml": "a: 2024-01-02T03:04:05Z\n---\nb: 1\n",
+	})
+	app := NewApp()
+	tests := []struct {
+		name string
+		read func() (NodeList, error)
+	}{
+		{"dir", func() (NodeList, error) { return app.ReadDir(PathRequest{Path: dir}) }},
+		{"outline", func() (NodeList, error) { return app.ReadGoOutline(PathRequest{Path: dir}) }},
+		{"json", func() (NodeList, error) { return app.ReadDataNode(PathRequest{Path: filepath.Join(dir, "data.json")}) }},
+		{"yaml", func() (NodeList, error) { return app.ReadDataNode(PathRequest{Path: filepath.Join(dir, "docs.yaml")}) }},
+	}
+	for _, tt := range tests {
+		list, err := tt.read()
+		nodes := list.Nodes
+		if err != nil || len(nodes) == 0 {
+			t.Errorf("%s: %d nodes, %v", tt.name, len(nodes), err)
+			continue
+		}
+		var walk func(nodes []FileNode)
+		walk = func(nodes []FileNode) {
+			for _, n := range nodes {
+				if !types[n.Type] {
+					t.Errorf("%s: %s has unlisted type %q", tt.name, n.Name, n.Type)
+				}
+				if n.Kind != "" && !kinds[n.Kind] {
+					t.Errorf("%s: %s has unlisted kind %q", tt.name, n.Name, n.Kind)
+				}
+				for _, c := range n.Children {
+					walk([]FileNode{*c})
+				}
+			}
+		}
+		walk(nodes)
+	}
+}
+
+// TestTSUnions keeps the TypeScript unions on FileNode in step with the
+// node types and kinds the backend produces.
+func TestTSUnions(t *testing.T) {
+	union := func(values []string) string {
+		quoted := make([]string, len(values))
+		for i, v := range values {
+			quoted[i] = "'" + v + "'"
+		}
+		return strings.Join(quoted, " | ")
+	}
+	var types, kinds []string
+	for _, v := range AllNodeTypes {
+		types = append(types, string(v))
+	}
+	for _, v := range AllNodeKinds {
+		kinds = append(kinds, string(v))
+	}
+
+	node := reflect.TypeOf(FileNode{})
+	tests := []struct {
+		field string
+		want  string
+	}{
+		{"Type", union(types)},
+		{"Kind", union(kinds)},
+	}
+	for _, tt := range tests {
+		f, _ := node.FieldByName(tt.field)
+		if got := f.Tag.Get("ts_type"); got != tt.want {
+			t.Errorf("FileNode.%s ts_type = %q, want %q", tt.field, got, tt.want)
+		}
+	}
+}
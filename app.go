@@ -4,18 +4,72 @@ import (
 	"context"
 	"os"
 	"path/filepath"
+	"sync"
+	"time"
+
+	"github.com/wailsapp/wails/v2/pkg/runtime"
 )
 
 type FileNode struct {
 	Name     string      `json:"name"`
 	Path     string      `json:"path"`
-	Type     string      `json:"type"` 
+	Type     NodeType    `json:"type" ts_type:"'file' | 'folder' | 'data' | 'decl'"`
+	Kind     NodeKind    `json:"kind,omitempty" ts_type:"'object' | 'array' | 'string' | 'number' | 'boolean' | 'null' | 'datetime' | 'stream' | 'package' | 'func' | 'method' | 'type' | 'const' | 'var' | 'path-entry'"`
 	Size     int64       `json:"size"`
+	Line     int         `json:"line,omitempty"`
+	EndLine  int         `json:"endLine,omitempty"`
+	Detail   string      `json:"detail,omitempty"`
 	Children []*FileNode `json:"children,omitempty"`
 }
 
 type App struct {
 	ctx context.Context
+
+	dataMu    sync.Mutex
+	dataCache map[string]*cachedDataFile
+
+	recentMu    sync.Mutex
+	recentWatch *treeWatcher
+	recentLive  []RecentFile
+
+	scanMu     sync.Mutex
+	scanCancel context.CancelFunc
+	scanDone   chan struct{}
+
+	pathIndexMu sync.Mutex
+	pathIndex   map[string][]string
+
+	contentMu      sync.Mutex
+	contentIndexes map[string]*contentIndex
+	contentWatch   *treeWatcher
+	contentPending map[string]bool
+	contentTimer   *time.Timer
+
+	historyMu      sync.Mutex
+	history        *historyStore
+	historyWatch   *treeWatcher
+	historyPending map[string]bool
+	historyTimer   *time.Timer
+
+	tailMu  sync.Mutex
+	tails   map[string]*logTail
+	tailSeq int
+
+	rulesMu      sync.Mutex
+	rules        []compiledRule
+	rulesDryRun  bool
+	rulesWatch   []*treeWatcher
+	rulesPending map[string]string
+	rulesTimer   *time.Timer
+	rulesFirst   time.Time
+	rulesMade    map[string]time.Time
+	rulesLog     []RuleLogEntry
+
+	anomalyMu    sync.Mutex
+	anomalyWatch *treeWatcher
+	anomalies    []AnomalyAlert
+
+	dirs dirCache
 }
 
 func NewApp() *App {
@@ -26,7 +80,33 @@ func (a *App) startup(ctx context.Context) {
 	a.ctx = ctx
 }
 
-func (a *App) ReadDir(path string) ([]FileNode, error) {
+func (a *App) shutdown(ctx context.Context) {
+	a.StopWatchRecent()
+	a.StopScan()
+	a.StopWatchContentIndex()
+	a.StopWatchHistory()
+	a.stopTails()
+	a.StopRules()
+	a.StopWatchAnomalies()
+	a.dirs.close()
+}
+
+// emit sends an event to the frontend. It does nothing before startup, so
+// the App can be used without a window.
+func (a *App) emit(name string, data ...interface{}) {
+	if a.ctx != nil {
+		runtime.EventsEmit(a.ctx, name, data...)
+	}
+}
+
+// ReadDir lists the entries of the directory at path. Concurrent calls for
+// the same path share one read, and listings are cached briefly.
+func (a *App) ReadDir(req PathRequest) (NodeList, error) {
+	nodes, err := a.dirs.read(filepath.Clean(req.Path))
+	return NodeList{Nodes: nodes}, err
+}
+
+func readDir(path string) ([]FileNode, error) {
 	var nodes []FileNode
 
 	entries, err := os.ReadDir(path)
@@ -38,11 +118,11 @@ func (a *App) ReadDir(path string) ([]FileNode, error) {
 		node := FileNode{
 			Name: entry.Name(),
 			Path: filepath.Join(path, entry.Name()),
-			Type: "file",
+			Type: NodeFile,
 		}
 
 		if entry.IsDir() {
-			node.Type = "folder"
+			node.Type = NodeFolder
 		} else {
 			info, _ := entry.Info()
 			node.Size = info.Size()
@@ -0,0 +1,437 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+	"unicode/utf8"
+
+	"gopkg.in/yaml.v3"
+)
+
+// dataValue is one key or array item of a parsed data file. Size is the
+// number of bytes the value takes up when serialized in the file's format.
+type dataValue struct {
+	key      string
+	kind     NodeKind
+	size     int64
+	children []*dataValue
+}
+
+// ReadDataNode lists the children of a JSON, YAML or TOML file, or of a
+// value inside one. Values are addressed with a JSON pointer after '#',
+// e.g. "package-lock.json#/packages/node_modules~1react".
+func (a *App) ReadDataNode(req PathRequest) (NodeList, error) {
+	path := req.Path
+	file, pointer := splitDataPath(path)
+
+	root, err := a.loadDataFile(file)
+	if err != nil {
+		return NodeList{}, err
+	}
+
+	value, err := root.lookup(pointer)
+	if err != nil {
+		return NodeList{}, fmt.Errorf("%s: %w", path, err)
+	}
+
+	nodes := make([]FileNode, 0, len(value.children))
+	for _, child := range value.children {
+		name := child.key
+		switch value.kind {
+		case KindArray:
+			name = "[" + child.key + "]"
+		case KindStream:
+			name = "document " + child.key
+		}
+		nodes = append(nodes, FileNode{
+			Name: name,
+			Path: file + "#" + pointer + "/" + escapePointerToken(child.key),
+			Type: NodeData,
+			Kind: child.kind,
+			Size: child.size,
+		})
+	}
+	return NodeList{Nodes: nodes}, nil
+}
+
+func splitDataPath(path string) (string, string) {
+	for i := 0; i < len(path); i++ {
+		if path[i] != '#' {
+			continue
+		}
+		if info, err := os.Stat(path[:i]); err == nil && !info.IsDir() {
+			return path[:i], path[i+1:]
+		}
+	}
+	return path, ""
+}
+
+func escapePointerToken(token string) string {
+	return strings.ReplaceAll(strings.ReplaceAll(token, "~", "~0"), "/", "~1")
+}
+
+func unescapePointerToken(token string) string {
+	return strings.ReplaceAll(strings.ReplaceAll(token, "~1", "/"), "~0", "~")
+}
+
+func (v *dataValue) lookup(pointer string) (*dataValue, error) {
+	if pointer == "" {
+		return v, nil
+	}
+	if !strings.HasPrefix(pointer, "/") {
+		return nil, fmt.Errorf("invalid pointer %q", pointer)
+	}
+
+	current := v
+	for _, token := range strings.Split(pointer[1:], "/") {
+		key := unescapePointerToken(token)
+		var next *dataValue
+		for _, child := range current.children {
+			if child.key == key {
+				next = child
+				break
+			}
+		}
+		if next == nil {
+			return nil, fmt.Errorf("no value at %q", pointer)
+		}
+		current = next
+	}
+	return current, nil
+}
+
+func dataFormat(path string) string {
+	switch strings.ToLower(filepath.Ext(path)) {
+	case ".json":
+		return "json"
+	case ".yaml", ".yml":
+		return "yaml"
+	case ".toml":
+		return "toml"
+	}
+	switch filepath.Base(path) {
+	case "Cargo.lock", "poetry.lock", "uv.lock":
+		return "toml"
+	case "Pipfile.lock", "composer.lock", "flake.lock":
+		return "json"
+	}
+	return ""
+}
+
+type cachedDataFile struct {
+	modTime time.Time
+	size    int64
+	root    *dataValue
+}
+
+// loadDataFile parses path, reusing the previous parse while the file's
+// size and modification time are unchanged so that expanding nested values
+// doesn't re-read large files.
+func (a *App) loadDataFile(path string) (*dataValue, error) {
+	info, err := os.Stat(path)
+	if err != nil {
+		return nil, err
+	}
+
+	a.dataMu.Lock()
+	cached, ok := a.dataCache[path]
+	a.dataMu.Unlock()
+	if ok && cached.modTime.Equal(info.ModTime()) && cached.size == info.Size() {
+		return cached.root, nil
+	}
+
+	root, err := parseDataFile(path)
+	if err != nil {
+		return nil, err
+	}
+
+	a.dataMu.Lock()
+	if a.dataCache == nil || len(a.dataCache) >= maxCachedDataFiles {
+		a.dataCache = make(map[string]*cachedDataFile)
+	}
+	a.dataCache[path] = &cachedDataFile{modTime: info.ModTime(), size: info.Size(), root: root}
+	a.dataMu.Unlock()
+	return root, nil
+}
+
+const maxCachedDataFiles = 8
+
+func parseDataFile(path string) (*dataValue, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+
+	switch dataFormat(path) {
+	case "json":
+		return parseJSONTree(data)
+	case "yaml":
+		return parseYAMLTree(data)
+	case "toml":
+		return parseTOMLTree(data)
+	}
+	return nil, fmt.Errorf("%s: not a JSON, YAML or TOML file", path)
+}
+
+func parseJSONTree(data []byte) (*dataValue, error) {
+	dec := json.NewDecoder(bytes.NewReader(data))
+	dec.UseNumber()
+	root, err := readJSONValue(dec, data, "")
+	if err != nil {
+		return nil, err
+	}
+	if _, err := dec.Token(); err != io.EOF {
+		return nil, fmt.Errorf("unexpected data after top-level value at offset %d", dec.InputOffset())
+	}
+	return root, nil
+}
+
+// readJSONValue consumes one value from dec and measures it against the raw
+// input, so sizes reflect the file as written rather than a re-encoding.
+func readJSONValue(dec *json.Decoder, data []byte, key string) (*dataValue, error) {
+	start := dec.InputOffset()
+	for start < int64(len(data)) && strings.IndexByte(" \t\r\n,:", data[start]) >= 0 {
+		start++
+	}
+
+	tok, err := dec.Token()
+	if err != nil {
+		return nil, err
+	}
+
+	v := &dataValue{key: key}
+	switch t := tok.(type) {
+	case json.Delim:
+		if t == '{' {
+			v.kind = KindObject
+			for dec.More() {
+				keyTok, err := dec.Token()
+				if err != nil {
+					return nil, err
+				}
+				child, err := readJSONValue(dec, data, keyTok.(string))
+				if err != nil {
+					return nil, err
+				}
+				v.children = append(v.children, child)
+			}
+		} else {
+			v.kind = KindArray
+			for i := 0; dec.More(); i++ {
+				child, err := readJSONValue(dec, data, strconv.Itoa(i))
+				if err != nil {
+					return nil, err
+				}
+				v.children = append(v.children, child)
+			}
+		}
+		if _, err := dec.Token(); err != nil {
+			return nil, err
+		}
+	case string:
+		v.kind = KindString
+	case json.Number:
+		v.kind = KindNumber
+	case bool:
+		v.kind = KindBoolean
+	case nil:
+		v.kind = KindNull
+	}
+
+	v.size = dec.InputOffset() - start
+	return v, nil
+}
+
+// yamlSource maps yaml.Node line and column marks back to byte offsets so
+// values can be measured against the file as written.
+type yamlSource struct {
+	data    []byte
+	lines   []int
+	anchors map[*yaml.Node]*dataValue
+}
+
+func newYAMLSource(data []byte) *yamlSource {
+	s := &yamlSource{data: data, lines: []int{0}, anchors: make(map[*yaml.Node]*dataValue)}
+	for i, b := range data {
+		if b == '\n' {
+			s.lines = append(s.lines, i+1)
+		}
+	}
+	return s
+}
+
+func (s *yamlSource) offset(node *yaml.Node) int {
+	if node.Line < 1 || node.Line > len(s.lines) {
+		return len(s.data)
+	}
+	off := s.lines[node.Line-1]
+	for col := 1; col < node.Column && off < len(s.data) && s.data[off] != '\n'; col++ {
+		_, n := utf8.DecodeRune(s.data[off:])
+		off += n
+	}
+	return off
+}
+
+// trimTail moves end back over whatever separates a value from the one that
+// follows it: whitespace, flow commas, the "- " of the next sequence item,
+// comment lines and document markers.
+func (s *yamlSource) trimTail(start, end int) int {
+	for {
+		for end > start && strings.IndexByte(" \t\r\n,", s.data[end-1]) >= 0 {
+			end--
+		}
+		ls := bytes.LastIndexByte(s.data[start:end], '\n')
+		if ls < 0 {
+			return end
+		}
+		ls += start + 1
+		line := strings.TrimSpace(string(s.data[ls:end]))
+		if line == "-" || line == "---" || line == "..." || strings.HasPrefix(line, "#") || strings.HasPrefix(line, "--- ") {
+			end = ls
+			continue
+		}
+		return end
+	}
+}
+
+// flowEnd returns the offset just past the bracket closing the flow
+// collection that opens at or after start.
+func (s *yamlSource) flowEnd(start int) int {
+	depth := 0
+	var quote byte
+	for i := start; i < len(s.data); i++ {
+		c := s.data[i]
+		switch {
+		case quote != 0:
+			if c == '\\' && quote == '"' {
+				i++
+			} else if c == quote {
+				quote = 0
+			}
+		case c == '"' || c == '\'':
+			quote = c
+		case c == '[' || c == '{':
+			depth++
+		case c == ']' || c == '}':
+			depth--
+			if depth == 0 {
+				return i + 1
+			}
+		}
+	}
+	return len(s.data)
+}
+
+func parseYAMLTree(data []byte) (*dataValue, error) {
+	var docs []*yaml.Node
+	dec := yaml.NewDecoder(bytes.NewReader(data))
+	for {
+		var doc yaml.Node
+		err := dec.Decode(&doc)
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return nil, err
+		}
+		docs = append(docs, &doc)
+	}
+
+	src := newYAMLSource(data)
+	switch len(docs) {
+	case 0:
+		return &dataValue{kind: KindNull}, nil
+	case 1:
+		return yamlDocument(src, docs[0], "", len(data)), nil
+	}
+
+	root := &dataValue{kind: KindStream, size: int64(len(data))}
+	for i, doc := range docs {
+		limit := len(data)
+		if i+1 < len(docs) && len(docs[i+1].Content) > 0 {
+			limit = src.offset(docs[i+1].Content[0])
+		}
+		root.children = append(root.children, yamlDocument(src, doc, strconv.Itoa(i), limit))
+	}
+	return root, nil
+}
+
+func yamlDocument(src *yamlSource, doc *yaml.Node, key string, limit int) *dataValue {
+	if len(doc.Content) == 0 {
+		return &dataValue{key: key, kind: KindNull}
+	}
+	return yamlValue(src, doc.Content[0], key, limit)
+}
+
+// yamlValue builds the value at node. limit is where the next sibling
+// starts, or where the enclosing collection ends. Aliases take their kind
+// and children from the anchored value but are sized as written.
+func yamlValue(src *yamlSource, node *yaml.Node, key string, limit int) *dataValue {
+	start := src.offset(node)
+	if start > limit {
+		limit = start
+	}
+
+	v := &dataValue{key: key}
+	end := src.trimTail(start, limit)
+	if node.Kind == yaml.ScalarNode && node.Style == 0 && bytes.HasPrefix(src.data[start:], []byte(node.Value)) {
+		end = start + len(node.Value)
+	}
+	childLimit := end
+	if node.Style&yaml.FlowStyle != 0 && (node.Kind == yaml.MappingNode || node.Kind == yaml.SequenceNode) {
+		end = src.flowEnd(start)
+		childLimit = end - 1
+	}
+	v.size = int64(end - start)
+
+	switch node.Kind {
+	case yaml.AliasNode:
+		if anchored, ok := src.anchors[node.Alias]; ok {
+			v.kind = anchored.kind
+			v.children = anchored.children
+		}
+	case yaml.MappingNode:
+		v.kind = KindObject
+		for i := 0; i+1 < len(node.Content); i += 2 {
+			next := childLimit
+			if i+2 < len(node.Content) {
+				next = src.offset(node.Content[i+2])
+			}
+			v.children = append(v.children, yamlValue(src, node.Content[i+1], node.Content[i].Value, next))
+		}
+	case yaml.SequenceNode:
+		v.kind = KindArray
+		for i, item := range node.Content {
+			next := childLimit
+			if i+1 < len(node.Content) {
+				next = src.offset(node.Content[i+1])
+			}
+			v.children = append(v.children, yamlValue(src, item, strconv.Itoa(i), next))
+		}
+	default:
+		switch node.ShortTag() {
+		case "!!int", "!!float":
+			v.kind = KindNumber
+		case "!!bool":
+			v.kind = KindBoolean
+		case "!!null":
+			v.kind = KindNull
+		case "!!timestamp":
+			v.kind = KindDatetime
+		default:
+			v.kind = KindString
+		}
+	}
+
+	if node.Anchor != "" {
+		src.anchors[node] = v
+	}
+	return v
+}
@@ -0,0 +1,113 @@
+package main
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+)
+
+func TestReadDataNode(t *testing.T) {
+	tests := []struct {
+		name    string
+		file    string
+		content string
+		pointer string
+		want    map[string]int64
+		order   []string
+		wantErr bool
+	}{
+		{
+			name:    "json sizes",
+			file:    "a.json",
+			content: `{"a": [1, 22], "b": "xy"}`,
+			want:    map[string]int64{"a": 7, "b": 4},
+		},
+		{
+			name:    "json array items",
+			file:    "a.json",
+			content: `{"a": [1, 22]}`,
+			pointer: "/a",
+			want:    map[string]int64{"[0]": 1, "[1]": 2},
+		},
+		{
+			name:    "json trailing data",
+			file:    "a.json",
+			content: `{"a":1} trailing garbage`,
+			wantErr: true,
+		},
+		{
+			name:    "yaml flow mapping",
+			file:    "a.yaml",
+			content: "a: {x: 1, y: [1,2]}\n",
+			want:    map[string]int64{"a": 16},
+		},
+		{
+			name:    "yaml flow children",
+			file:    "a.yaml",
+			content: "a: {x: 1, y: [1,2]}\n",
+			pointer: "/a",
+			want:    map[string]int64{"x": 1, "y": 5},
+		},
+		{
+			name:    "yaml block sequence",
+			file:    "a.yml",
+			content: "list:\n  - one\n  - two # note\n  # comment\n  - three\nnext: 1\n",
+			pointer: "/list",
+			want:    map[string]int64{"[0]": 3, "[1]": 3, "[2]": 5},
+		},
+		{
+			name:    "yaml multiple documents",
+			file:    "a.yaml",
+			content: "a: 1\n---\nb: [1, 2]\n",
+			want:    map[string]int64{"document 0": 4, "document 1": 9},
+		},
+		{
+			name:    "toml file order",
+			file:    "a.toml",
+			content: "zeta = 1\nalpha = \"ab\"\n[table]\nk = [1, 2]\n",
+			want:    map[string]int64{"zeta": 1, "alpha": 4, "table": 18},
+			order:   []string{"zeta", "alpha", "table"},
+		},
+		{
+			name:    "toml array of tables",
+			file:    "Cargo.lock",
+			content: "[[package]]\nname = \"a\"\n\n[[package]]\nname = \"bc\"\n",
+			pointer: "/package",
+			want:    map[string]int64{"[0]": 22, "[1]": 23},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			path := filepath.Join(t.TempDir(), tt.file)
+			if err := os.WriteFile(path, []byte(tt.content), 0o644); err != nil {
+				t.Fatal(err)
+			}
+
+			list, err := NewApp().ReadDataNode(PathRequest{Path: path + "#" + tt.pointer})
+			if tt.wantErr {
+				if err == nil {
+					t.Fatal("ReadDataNode succeeded, want error")
+				}
+				return
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			got := make(map[string]int64)
+			var order []string
+			for _, n := range list.Nodes {
+				got[n.Name] = n.Size
+				order = append(order, n.Name)
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("sizes = %v, want %v", got, tt.want)
+			}
+			if tt.order != nil && !reflect.DeepEqual(order, tt.order) {
+				t.Errorf("order = %v, want %v", order, tt.order)
+			}
+		})
+	}
+}
@@ -0,0 +1,347 @@
+package main
+
+import (
+	"bytes"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/BurntSushi/toml"
+)
+
+// tomlTree turns a decoded TOML document into dataValues. Keys are ordered
+// as they appear in the file and sizes come from scanning the source, both
+// indexed by the value's path joined with NUL.
+type tomlTree struct {
+	order map[string]int
+	sizes map[string]int64
+}
+
+func parseTOMLTree(data []byte) (*dataValue, error) {
+	var doc map[string]interface{}
+	md, err := toml.Decode(string(data), &doc)
+	if err != nil {
+		return nil, err
+	}
+
+	t := &tomlTree{order: make(map[string]int), sizes: scanTOMLSizes(data)}
+	for _, key := range md.Keys() {
+		for n := 1; n <= len(key); n++ {
+			k := strings.Join(key[:n], "\x00")
+			if _, ok := t.order[k]; !ok {
+				t.order[k] = len(t.order)
+			}
+		}
+	}
+
+	root := t.value(doc, "", nil, nil)
+	root.size = int64(len(data))
+	return root, nil
+}
+
+// value builds the dataValue for v. path includes array indexes and keys
+// the sizes; plain leaves them out and keys the order, which md.Keys()
+// records without indexes.
+func (t *tomlTree) value(value interface{}, key string, path, plain []string) *dataValue {
+	v := &dataValue{key: key, size: t.sizes[strings.Join(path, "\x00")]}
+
+	switch x := value.(type) {
+	case map[string]interface{}:
+		v.kind = KindObject
+		keys := make([]string, 0, len(x))
+		for k := range x {
+			keys = append(keys, k)
+		}
+		sort.Slice(keys, func(i, j int) bool { return t.less(plain, keys[i], keys[j]) })
+		for _, k := range keys {
+			v.children = append(v.children, t.value(x[k], k, appendPath(path, k), appendPath(plain, k)))
+		}
+	case []map[string]interface{}:
+		v.kind = KindArray
+		for i, item := range x {
+			v.children = append(v.children, t.value(item, strconv.Itoa(i), appendPath(path, strconv.Itoa(i)), plain))
+		}
+	case []interface{}:
+		v.kind = KindArray
+		for i, item := range x {
+			v.children = append(v.children, t.value(item, strconv.Itoa(i), appendPath(path, strconv.Itoa(i)), plain))
+		}
+	case string:
+		v.kind = KindString
+	case int64, float64:
+		v.kind = KindNumber
+	case bool:
+		v.kind = KindBoolean
+	case time.Time:
+		v.kind = KindDatetime
+	}
+	return v
+}
+
+func (t *tomlTree) less(parent []string, a, b string) bool {
+	oa, okA := t.order[strings.Join(appendPath(parent, a), "\x00")]
+	ob, okB := t.order[strings.Join(appendPath(parent, b), "\x00")]
+	switch {
+	case okA && okB:
+		return oa < ob
+	case okA != okB:
+		return okA
+	}
+	return a < b
+}
+
+func appendPath(path []string, elem string) []string {
+	return append(path[:len(path):len(path)], elem)
+}
+
+// tomlScanner walks TOML source that the decoder has already accepted and
+// records the byte span of every value. Tables are sized by the statements,
+// header lines included, that they contain.
+type tomlScanner struct {
+	data   []byte
+	i      int
+	sizes  map[string]int64
+	arrays map[string]int
+}
+
+func scanTOMLSizes(data []byte) map[string]int64 {
+	s := &tomlScanner{data: data, sizes: make(map[string]int64), arrays: make(map[string]int)}
+	var table []string
+	sectionEnd := -1
+
+	for {
+		s.skipSpace(true)
+		if s.i >= len(s.data) {
+			break
+		}
+		start := s.i
+
+		if s.data[s.i] == '[' {
+			double := s.i+1 < len(s.data) && s.data[s.i+1] == '['
+			s.i++
+			if double {
+				s.i++
+			}
+			keys := s.readKey()
+			for s.i < len(s.data) && s.data[s.i] == ']' {
+				s.i++
+			}
+			table = s.resolveTable(keys, double)
+			s.addSpan(table, len(table), len(table), start, start)
+		} else {
+			keys := s.readKey()
+			s.skipSpace(false)
+			if s.i < len(s.data) && s.data[s.i] == '=' {
+				s.i++
+			}
+			s.skipSpace(false)
+
+			leaf := append(append([]string(nil), table...), keys...)
+			valueStart := s.i
+			s.scanValue(leaf)
+			s.sizes[strings.Join(leaf, "\x00")] = int64(s.i - valueStart)
+			from := start
+			if sectionEnd >= 0 {
+				from = sectionEnd
+			}
+			s.addSpan(leaf, len(leaf)-1, len(table), from, start)
+		}
+		sectionEnd = s.i
+
+		if s.i == start {
+			s.i++
+		}
+	}
+	return s.sizes
+}
+
+// addSpan credits the statement from start to the current position to the
+// first n prefixes of path (plus the root). The enclosing table, up to
+// tableLen, is credited from its previous statement's end instead, so
+// blank lines and comments inside a section count toward its size.
+func (s *tomlScanner) addSpan(path []string, n, tableLen, from, start int) {
+	for i := 0; i <= n; i++ {
+		begin := start
+		if i <= tableLen {
+			begin = from
+		}
+		s.sizes[strings.Join(path[:i], "\x00")] += int64(s.i - begin)
+	}
+}
+
+// resolveTable maps a header's keys to a path through the current element
+// of every array of tables it passes, and starts a new element for [[...]].
+func (s *tomlScanner) resolveTable(keys []string, double bool) []string {
+	var path []string
+	for j, k := range keys {
+		path = append(path, k)
+		last := j == len(keys)-1
+		if n, ok := s.arrays[strings.Join(path, "\x00")]; ok && !(last && double) {
+			path = append(path, strconv.Itoa(n-1))
+		}
+	}
+	if double {
+		k := strings.Join(path, "\x00")
+		n := s.arrays[k]
+		s.arrays[k] = n + 1
+		path = append(path, strconv.Itoa(n))
+	}
+	return path
+}
+
+func (s *tomlScanner) skipSpace(newlines bool) {
+	for s.i < len(s.data) {
+		switch c := s.data[s.i]; {
+		case c == ' ' || c == '\t' || c == '\r':
+			s.i++
+		case newlines && c == '\n':
+			s.i++
+		case newlines && c == '#':
+			for s.i < len(s.data) && s.data[s.i] != '\n' {
+				s.i++
+			}
+		default:
+			return
+		}
+	}
+}
+
+func (s *tomlScanner) readKey() []string {
+	var keys []string
+	for {
+		s.skipSpace(false)
+		if s.i >= len(s.data) {
+			return keys
+		}
+
+		switch c := s.data[s.i]; c {
+		case '"':
+			end := s.stringEnd(s.i)
+			raw := string(s.data[s.i:end])
+			if k, err := strconv.Unquote(raw); err == nil {
+				keys = append(keys, k)
+			} else {
+				keys = append(keys, strings.Trim(raw, `"`))
+			}
+			s.i = end
+		case '\'':
+			end := s.stringEnd(s.i)
+			keys = append(keys, strings.Trim(string(s.data[s.i:end]), "'"))
+			s.i = end
+		default:
+			start := s.i
+			for s.i < len(s.data) && isBareKeyChar(s.data[s.i]) {
+				s.i++
+			}
+			keys = append(keys, string(s.data[start:s.i]))
+		}
+
+		s.skipSpace(false)
+		if s.i >= len(s.data) || s.data[s.i] != '.' {
+			return keys
+		}
+		s.i++
+	}
+}
+
+func isBareKeyChar(c byte) bool {
+	return c >= 'a' && c <= 'z' || c >= 'A' && c <= 'Z' || c >= '0' && c <= '9' || c == '_' || c == '-'
+}
+
+// stringEnd returns the offset just past the basic, literal or multi-line
+// string that opens at i.
+func (s *tomlScanner) stringEnd(i int) int {
+	q := s.data[i]
+	triple := []byte{q, q, q}
+
+	if bytes.HasPrefix(s.data[i:], triple) {
+		for j := i + 3; j < len(s.data); j++ {
+			if q == '"' && s.data[j] == '\\' {
+				j++
+				continue
+			}
+			if bytes.HasPrefix(s.data[j:], triple) {
+				j += 3
+				for n := 0; n < 2 && j < len(s.data) && s.data[j] == q; n++ {
+					j++
+				}
+				return j
+			}
+		}
+		return len(s.data)
+	}
+
+	j := i + 1
+	for j < len(s.data) && s.data[j] != q && s.data[j] != '\n' {
+		if q == '"' && s.data[j] == '\\' {
+			j++
+		}
+		j++
+	}
+	return min(j+1, len(s.data))
+}
+
+// scanValue advances past the value at the current position, recording the
+// span of every array item and inline table entry inside it.
+func (s *tomlScanner) scanValue(path []string) {
+	if s.i >= len(s.data) {
+		return
+	}
+
+	switch s.data[s.i] {
+	case '"', '\'':
+		s.i = s.stringEnd(s.i)
+	case '[':
+		s.i++
+		for n := 0; ; n++ {
+			s.skipSpace(true)
+			if s.i >= len(s.data) || s.data[s.i] == ']' {
+				s.i++
+				return
+			}
+			s.scanChild(appendPath(path, strconv.Itoa(n)))
+			s.skipSpace(true)
+			if s.i < len(s.data) && s.data[s.i] == ',' {
+				s.i++
+			}
+		}
+	case '{':
+		s.i++
+		for {
+			s.skipSpace(true)
+			if s.i >= len(s.data) || s.data[s.i] == '}' {
+				s.i++
+				return
+			}
+			keys := s.readKey()
+			s.skipSpace(false)
+			if s.i < len(s.data) && s.data[s.i] == '=' {
+				s.i++
+			}
+			s.skipSpace(false)
+			s.scanChild(append(append([]string(nil), path...), keys...))
+			s.skipSpace(true)
+			if s.i < len(s.data) && s.data[s.i] == ',' {
+				s.i++
+			}
+		}
+	default:
+		start := s.i
+		for s.i < len(s.data) && strings.IndexByte(",]}#\n", s.data[s.i]) < 0 {
+			s.i++
+		}
+		for s.i > start && strings.IndexByte(" \t\r", s.data[s.i-1]) >= 0 {
+			s.i--
+		}
+	}
+}
+
+func (s *tomlScanner) scanChild(path []string) {
+	start := s.i
+	s.scanValue(path)
+	if s.i == start {
+		s.i++
+	}
+	s.sizes[strings.Join(path, "\x00")] = int64(s.i - start)
+}
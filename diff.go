@@ -0,0 +1,197 @@
+package main
+
+import (
+	"fmt"
+	"strings"
+)
+
+type diffOp struct {
+	// Kind is ' ' for a line in both, '-' for one only in a, '+' for one
+	// only in b.
+	Kind byte
+	A, B int
+}
+
+// diffLines returns an edit script turning a into b, computed with Myers'
+// O(ND) algorithm so that the number of inserted and deleted lines is
+// minimal.
+func diffLines(a, b []string) []diffOp {
+	ops, _ := diffLinesLimit(a, b, len(a)+len(b))
+	return ops
+}
+
+// diffLinesLimit is diffLines giving up, with false, once more than
+// maxEdits lines would have to be inserted or deleted. Time and memory
+// grow with the square of the edits, so callers facing arbitrary input
+// bound them.
+func diffLinesLimit(a, b []string, maxEdits int) ([]diffOp, bool) {
+	n, m := len(a), len(b)
+	limit := min(n+m, maxEdits)
+	offset := n + m + 1
+	v := make([]int, 2*offset+1)
+	var trace [][]int
+
+	for d := 0; d <= limit; d++ {
+		// Only diagonals -d..d are read when backtracking from step d.
+		trace = append(trace, append([]int(nil), v[offset-d:offset+d+1]...))
+		for k := -d; k <= d; k += 2 {
+			var x int
+			if k == -d || k != d && v[offset+k-1] < v[offset+k+1] {
+				x = v[offset+k+1]
+			} else {
+				x = v[offset+k-1] + 1
+			}
+			y := x - k
+			for x < n && y < m && a[x] == b[y] {
+				x++
+				y++
+			}
+			v[offset+k] = x
+			if x >= n && y >= m {
+				return backtrackDiff(trace, a, b, d), true
+			}
+		}
+	}
+	return nil, false
+}
+
+func backtrackDiff(trace [][]int, a, b []string, d int) []diffOp {
+	x, y := len(a), len(b)
+	var ops []diffOp
+	for ; d > 0; d-- {
+		v := trace[d]
+		k := x - y
+		var prevK int
+		if k == -d || k != d && v[d+k-1] < v[d+k+1] {
+			prevK = k + 1
+		} else {
+			prevK = k - 1
+		}
+		prevX := v[d+prevK]
+		prevY := prevX - prevK
+		for x > prevX && y > prevY {
+			x--
+			y--
+			ops = append(ops, diffOp{' ', x, y})
+		}
+		if x == prevX {
+			y--
+			ops = append(ops, diffOp{'+', x, y})
+		} else {
+			x--
+			ops = append(ops, diffOp{'-', x, y})
+		}
+	}
+	for x > 0 && y > 0 {
+		x--
+		y--
+		ops = append(ops, diffOp{' ', x, y})
+	}
+
+	for i, j := 0, len(ops)-1; i < j; i, j = i+1, j-1 {
+		ops[i], ops[j] = ops[j], ops[i]
+	}
+	return ops
+}
+
+// splitLines splits text after each newline, so every line but possibly
+// the last keeps its terminator and joining them restores text.
+func splitLines(text string) []string {
+	if text == "" {
+		return nil
+	}
+	lines := strings.SplitAfter(text, "\n")
+	if lines[len(lines)-1] == "" {
+		lines = lines[:len(lines)-1]
+	}
+	return lines
+}
+
+// diffHunks groups the changes in ops into hunks with the given lines of
+// context, returned as [start, end) ranges of ops. Changes closer than twice
+// the context share a hunk.
+func diffHunks(ops []diffOp, context int) [][2]int {
+	var hunks [][2]int
+	for i := 0; i < len(ops); {
+		if ops[i].Kind == ' ' {
+			i++
+			continue
+		}
+
+		// Grow the hunk until the unchanged run after a change is longer
+		// than twice the context.
+		start := max(0, i-context)
+		end := i
+		for end < len(ops) {
+			if ops[end].Kind != ' ' {
+				end++
+				continue
+			}
+			run := end
+			for run < len(ops) && ops[run].Kind == ' ' {
+				run++
+			}
+			if run == len(ops) || run-end > 2*context {
+				end = min(len(ops), end+context)
+				break
+			}
+			end = run
+		}
+		hunks = append(hunks, [2]int{start, end})
+		i = end
+	}
+	return hunks
+}
+
+// unifiedDiff renders the changes from a to b in unified format with the
+// given lines of context, or returns "" when they are equal. It gives up,
+// with false, when more than maxEdits lines changed.
+func unifiedDiff(nameA, nameB, a, b string, context, maxEdits int) (string, bool) {
+	linesA, linesB := splitLines(a), splitLines(b)
+	ops, ok := diffLinesLimit(linesA, linesB, maxEdits)
+	if !ok {
+		return "", false
+	}
+	return formatUnified(nameA, nameB, linesA, linesB, ops, context), true
+}
+
+func formatUnified(nameA, nameB string, linesA, linesB []string, ops []diffOp, context int) string {
+	var sb strings.Builder
+	for _, h := range diffHunks(ops, context) {
+		if sb.Len() == 0 {
+			fmt.Fprintf(&sb, "--- %s\n+++ %s\n", nameA, nameB)
+		}
+		hunk := ops[h[0]:h[1]]
+		startA, startB, countA, countB := hunk[0].A+1, hunk[0].B+1, 0, 0
+		for _, op := range hunk {
+			if op.Kind != '+' {
+				countA++
+			}
+			if op.Kind != '-' {
+				countB++
+			}
+		}
+		if countA == 0 {
+			startA--
+		}
+		if countB == 0 {
+			startB--
+		}
+		fmt.Fprintf(&sb, "@@ -%d,%d +%d,%d @@\n", startA, countA, startB, countB)
+		for _, op := range hunk {
+			line := ""
+			switch op.Kind {
+			case '+':
+				line = linesB[op.B]
+			default:
+				line = linesA[op.A]
+			}
+			sb.WriteByte(op.Kind)
+			sb.WriteString(line)
+			if !strings.HasSuffix(line, "\n") {
+				sb.WriteString("\n\\ No newline at end of file\n")
+			}
+		}
+	}
+	return sb.String()
+}
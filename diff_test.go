@@ -0,0 +1,78 @@
+package main
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestDiffLines(t *testing.T) {
+	tests := []struct {
+		a, b  string
+		edits int
+	}{
+		{"", "", 0},
+		{"a\nb\nc\n", "a\nb\nc\n", 0},
+		{"", "a\nb\n", 2},
+		{"a\nb\n", "", 2},
+		{"a\nb\nc\n", "a\nx\nc\n", 2},
+		{"a\nb\nc\na\nb\nb\na\n", "c\nb\na\nb\na\nc\n", 5},
+	}
+	for _, tt := range tests {
+		a, b := splitLines(tt.a), splitLines(tt.b)
+		ops := diffLines(a, b)
+
+		// Replaying the script must turn a into b.
+		var got []string
+		edits := 0
+		for _, op := range ops {
+			switch op.Kind {
+			case ' ':
+				if a[op.A] != b[op.B] {
+					t.Errorf("%q -> %q: kept line %d differs", tt.a, tt.b, op.A)
+				}
+				got = append(got, a[op.A])
+			case '+':
+				got = append(got, b[op.B])
+				edits++
+			case '-':
+				edits++
+			}
+		}
+		if strings.Join(got, "") != tt.b {
+			t.Errorf("%q -> %q: script produces %q", tt.a, tt.b, strings.Join(got, ""))
+		}
+		if edits != tt.edits {
+			t.Errorf("%q -> %q: %d edits, want %d", tt.a, tt.b, edits, tt.edits)
+		}
+	}
+}
+
+func TestUnifiedDiff(t *testing.T) {
+	tests := []struct {
+		name string
+		a, b string
+		want string
+	}{
+		{"equal", "a\nb\n", "a\nb\n", ""},
+		{"change", "a\nb\nc\n", "a\nB\nc\n", "--- x\n+++ y\n@@ -1,3 +1,3 @@\n a\n-b\n+B\n c\n"},
+		{"insert into empty", "", "a\n", "--- x\n+++ y\n@@ -0,0 +1,1 @@\n+a\n"},
+		{"no final newline", "a\n", "a", "--- x\n+++ y\n@@ -1,1 +1,1 @@\n-a\n+a\n\\ No newline at end of file\n"},
+		{
+			"two hunks",
+			"1\n2\n3\n4\n5\n6\n7\n8\n9\n",
+			"one\n2\n3\n4\n5\n6\n7\n8\nnine\n",
+			"--- x\n+++ y\n@@ -1,2 +1,2 @@\n-1\n+one\n 2\n@@ -8,2 +8,2 @@\n 8\n-9\n+nine\n",
+		},
+		{
+			"merged hunk",
+			"1\n2\n3\n4\n",
+			"one\n2\n3\nfour\n",
+			"--- x\n+++ y\n@@ -1,4 +1,4 @@\n-1\n+one\n 2\n 3\n-4\n+four\n",
+		},
+	}
+	for _, tt := range tests {
+		if got, ok := unifiedDiff("x", "y", tt.a, tt.b, 1, 100); !ok || got != tt.want {
+			t.Errorf("%s:\ngot\n%s\nwant\n%s", tt.name, got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,260 @@
+package main
+
+import (
+	"path/filepath"
+	"slices"
+	"sync"
+	"time"
+
+	"github.com/fsnotify/fsnotify"
+	"golang.org/x/sync/errgroup"
+	"golang.org/x/sync/singleflight"
+)
+
+// DirListing is one directory read by ReadDirs. Error is set instead of
+// Nodes when the directory couldn't be read.
+type DirListing struct {
+	Nodes []FileNode `json:"nodes"`
+	Error string     `json:"error,omitempty"`
+}
+
+type ReadDirsRequest struct {
+	Paths []string `json:"paths"`
+	// Depth is how many levels are listed; 0 means 1, just Paths.
+	Depth int `json:"depth"`
+}
+
+type DirListings struct {
+	// Dirs holds a listing for each directory read, by its path.
+	Dirs map[string]DirListing `json:"dirs"`
+}
+
+const (
+	// dirCacheTTL bounds how stale a listing can get when the watcher
+	// misses a change, for instance on a network filesystem.
+	dirCacheTTL = 5 * time.Second
+	// readDirsParallel is how many directories ReadDirs reads at once.
+	readDirsParallel = 8
+)
+
+// dirCache shares directory reads between concurrent callers and keeps the
+// results for a short while. Cached directories are watched, and a change
+// in one drops its listing. The zero value is ready to use.
+type dirCache struct {
+	group singleflight.Group
+
+	mu       sync.Mutex
+	entries  map[string]cachedDir
+	watch    *fsnotify.Watcher
+	watchErr error
+	// watched holds when each watched directory was last read.
+	watched map[string]time.Time
+	// gen counts changes. changed holds the gen of the last change to each
+	// watched directory, and lost the gen of the last time events were
+	// lost, so a read only throws away its listing when its own directory
+	// changed meanwhile.
+	gen       uint64
+	changed   map[string]uint64
+	lost      uint64
+	lastSweep time.Time
+}
+
+type cachedDir struct {
+	nodes []FileNode
+	at    time.Time
+}
+
+// ReadDirs lists each of req.Paths and, for a depth above 1, the folders
+// inside them down to that many levels, keyed by directory path. A
+// directory that can't be read gets an error in its listing rather than
+// failing the rest.
+func (a *App) ReadDirs(req ReadDirsRequest) DirListings {
+	depth := req.Depth
+	if depth < 1 {
+		depth = 1
+	}
+	out := make(map[string]DirListing)
+	var mu sync.Mutex
+	level := make([]string, 0, len(req.Paths))
+	for _, p := range req.Paths {
+		p = filepath.Clean(p)
+		if _, ok := out[p]; !ok {
+			out[p] = DirListing{}
+			level = append(level, p)
+		}
+	}
+
+	for d := 0; d < depth && len(level) > 0; d++ {
+		var next []string
+		var g errgroup.Group
+		g.SetLimit(readDirsParallel)
+		for _, dir := range level {
+			g.Go(func() error {
+				nodes, err := a.dirs.read(dir)
+				mu.Lock()
+				defer mu.Unlock()
+				if err != nil {
+					out[dir] = DirListing{Error: err.Error()}
+					return nil
+				}
+				if nodes == nil {
+					nodes = []FileNode{}
+				}
+				out[dir] = DirListing{Nodes: nodes}
+				for _, n := range nodes {
+					if _, ok := out[n.Path]; !ok && n.Type == NodeFolder && d+1 < depth {
+						out[n.Path] = DirListing{}
+						next = append(next, n.Path)
+					}
+				}
+				return nil
+			})
+		}
+		g.Wait()
+		level = next
+	}
+	return DirListings{Dirs: out}
+}
+
+// read returns the listing of dir, from the cache when it is fresh, and
+// otherwise from disk, sharing one read among concurrent callers.
+func (c *dirCache) read(dir string) ([]FileNode, error) {
+	now := time.Now()
+	c.mu.Lock()
+	if e, ok := c.entries[dir]; ok && now.Sub(e.at) < dirCacheTTL {
+		c.mu.Unlock()
+		return slices.Clone(e.nodes), nil
+	}
+	c.mu.Unlock()
+
+	v, err, _ := c.group.Do(dir, func() (interface{}, error) {
+		// The watch is added before reading, and the listing is only kept
+		// if nothing changed meanwhile, so a change during the read can't
+		// leave a stale listing behind.
+		c.mu.Lock()
+		c.sweep(now)
+		watched := c.addWatch(dir, now)
+		start := c.gen
+		c.mu.Unlock()
+
+		nodes, err := readDir(dir)
+		if err != nil {
+			return nil, err
+		}
+		if watched {
+			c.mu.Lock()
+			if c.unchangedSince(dir, start) {
+				if c.entries == nil {
+					c.entries = make(map[string]cachedDir)
+				}
+				c.entries[dir] = cachedDir{nodes: nodes, at: now}
+			}
+			c.mu.Unlock()
+		}
+		return nodes, nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return slices.Clone(v.([]FileNode)), nil
+}
+
+// addWatch starts watching dir, creating the watcher on first use. It
+// reports false when dir can't be watched, and its listing mustn't be
+// cached. c.mu must be held.
+func (c *dirCache) addWatch(dir string, now time.Time) bool {
+	if c.watch == nil && c.watchErr == nil {
+		c.watch, c.watchErr = fsnotify.NewWatcher()
+		if c.watchErr == nil {
+			go c.run(c.watch)
+		}
+	}
+	if c.watch == nil {
+		return false
+	}
+	if _, ok := c.watched[dir]; !ok {
+		if c.watch.Add(dir) != nil {
+			return false
+		}
+		if c.watched == nil {
+			c.watched = make(map[string]time.Time)
+		}
+	}
+	c.watched[dir] = now
+	return true
+}
+
+func (c *dirCache) run(w *fsnotify.Watcher) {
+	for {
+		select {
+		case ev, ok := <-w.Events:
+			if !ok {
+				return
+			}
+			c.invalidate(filepath.Dir(ev.Name))
+			if ev.Has(fsnotify.Remove) || ev.Has(fsnotify.Rename) {
+				c.invalidate(ev.Name)
+			}
+		case _, ok := <-w.Errors:
+			if !ok {
+				return
+			}
+			// An overflow means events were lost, so nothing cached can
+			// be trusted.
+			c.mu.Lock()
+			c.entries = nil
+			c.gen++
+			c.lost = c.gen
+			c.mu.Unlock()
+		}
+	}
+}
+
+func (c *dirCache) invalidate(dir string) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	delete(c.entries, dir)
+	// A directory that isn't watched has no read in flight to spoil.
+	if _, ok := c.watched[dir]; !ok {
+		return
+	}
+	if c.changed == nil {
+		c.changed = make(map[string]uint64)
+	}
+	c.gen++
+	c.changed[dir] = c.gen
+}
+
+// unchangedSince reports whether dir has changed, or events were lost,
+// after gen start. c.mu must be held.
+func (c *dirCache) unchangedSince(dir string, start uint64) bool {
+	return c.changed[dir] <= start && c.lost <= start
+}
+
+// sweep stops watching the directories not read within the TTL and drops
+// their listings, at most once per TTL. c.mu must be held.
+func (c *dirCache) sweep(now time.Time) {
+	if now.Sub(c.lastSweep) < dirCacheTTL {
+		return
+	}
+	c.lastSweep = now
+	for dir, at := range c.watched {
+		if now.Sub(at) >= dirCacheTTL {
+			c.watch.Remove(dir)
+			delete(c.watched, dir)
+			delete(c.entries, dir)
+			delete(c.changed, dir)
+		}
+	}
+}
+
+func (c *dirCache) close() {
+	c.mu.Lock()
+	w := c.watch
+	c.watch, c.watchErr = nil, nil
+	c.entries, c.watched, c.changed = nil, nil, nil
+	c.mu.Unlock()
+	if w != nil {
+		w.Close()
+	}
+}
@@ -0,0 +1,174 @@
+package main
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"sort"
+	"sync"
+	"testing"
+	"time"
+)
+
+func TestReadDirs(t *testing.T) {
+	root := t.TempDir()
+	writeFiles(t, root, map[string]string{
+		"top.txt":       "x",
+		"a/one.txt":     "x",
+		"a/b/two.txt":   "x",
+		"a/b/c/three":   "x",
+		"z/other.txt":   "x",
+		"z/deeper/leaf": "x",
+	})
+	missing := filepath.Join(root, "missing")
+	a, b, z := filepath.Join(root, "a"), filepath.Join(root, "a", "b"), filepath.Join(root, "z")
+
+	tests := []struct {
+		name   string
+		paths  []string
+		depth  int
+		want   []string
+		errors []string
+	}{
+		{"one level", []string{root}, 1, []string{root}, nil},
+		{"zero means one", []string{root}, 0, []string{root}, nil},
+		{"two levels", []string{root}, 2, []string{root, a, z}, nil},
+		{"three levels", []string{a}, 3, []string{a, b, filepath.Join(b, "c")}, nil},
+		{"duplicates", []string{a, a + "/", b}, 1, []string{a, b}, nil},
+		{"missing", []string{missing, z}, 1, []string{missing, z}, []string{missing}},
+	}
+	app := NewApp()
+	defer app.dirs.close()
+	for _, tt := range tests {
+		got := app.ReadDirs(ReadDirsRequest{Paths: tt.paths, Depth: tt.depth})
+		var keys, errs []string
+		for path, l := range got.Dirs {
+			keys = append(keys, path)
+			if l.Error != "" {
+				errs = append(errs, path)
+			} else if l.Nodes == nil {
+				t.Errorf("%s: %s has nil nodes", tt.name, path)
+			}
+		}
+		sort.Strings(keys)
+		sort.Strings(tt.want)
+		if !reflect.DeepEqual(keys, tt.want) {
+			t.Errorf("%s: listed %v, want %v", tt.name, keys, tt.want)
+		}
+		if !reflect.DeepEqual(errs, tt.errors) {
+			t.Errorf("%s: errors for %v, want %v", tt.name, errs, tt.errors)
+		}
+	}
+
+	got := app.ReadDirs(ReadDirsRequest{Paths: []string{b}, Depth: 1})
+	if nodes := got.Dirs[b].Nodes; len(nodes) != 2 || nodes[0].Name != "c" || nodes[0].Type != NodeFolder || nodes[1].Size != 1 {
+		t.Errorf("listing of b = %+v", nodes)
+	}
+}
+
+func TestReadDirInvalidation(t *testing.T) {
+	root := t.TempDir()
+	writeFiles(t, root, map[string]string{"a.txt": "x"})
+	app := NewApp()
+	defer app.dirs.close()
+
+	tests := []struct {
+		name   string
+		change func() error
+		want   []string
+	}{
+		{"create", func() error { return os.WriteFile(filepath.Join(root, "b.txt"), nil, 0o644) }, []string{"a.txt", "b.txt"}},
+		{"rename", func() error { return os.Rename(filepath.Join(root, "a.txt"), filepath.Join(root, "c.txt")) }, []string{"b.txt", "c.txt"}},
+		{"remove", func() error { return os.Remove(filepath.Join(root, "b.txt")) }, []string{"c.txt"}},
+	}
+	if _, err := app.ReadDir(PathRequest{Path: root}); err != nil {
+		t.Fatal(err)
+	}
+	for _, tt := range tests {
+		if err := tt.change(); err != nil {
+			t.Fatal(err)
+		}
+		// Well within the TTL, so only the watcher can make the change show.
+		deadline := time.Now().Add(2 * time.Second)
+		var names []string
+		for {
+			list, err := app.ReadDir(PathRequest{Path: root})
+			if err != nil {
+				t.Fatal(err)
+			}
+			names = names[:0]
+			for _, n := range list.Nodes {
+				names = append(names, n.Name)
+			}
+			if reflect.DeepEqual(names, tt.want) || time.Now().After(deadline) {
+				break
+			}
+			time.Sleep(10 * time.Millisecond)
+		}
+		if !reflect.DeepEqual(names, tt.want) {
+			t.Errorf("after %s: %v, want %v", tt.name, names, tt.want)
+		}
+	}
+}
+
+func TestReadDirConcurrent(t *testing.T) {
+	root := t.TempDir()
+	writeFiles(t, root, map[string]string{"a.txt": "x", "sub/b.txt": "xy"})
+	app := NewApp()
+	defer app.dirs.close()
+
+	var wg sync.WaitGroup
+	results := make([][]FileNode, 16)
+	for i := range results {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			list, _ := app.ReadDir(PathRequest{Path: root})
+			results[i] = list.Nodes
+		}()
+	}
+	wg.Wait()
+	for i, r := range results {
+		if len(r) != 2 || r[0].Name != "a.txt" || r[1].Type != NodeFolder {
+			t.Fatalf("result %d = %+v", i, r)
+		}
+	}
+	// Callers get their own copies of a shared listing.
+	results[0][0].Name = "changed"
+	if again, _ := app.ReadDir(PathRequest{Path: root}); again.Nodes[0].Name != "a.txt" {
+		t.Error("cached listing was modified through a result")
+	}
+}
+
+func TestDirCacheUnchangedSince(t *testing.T) {
+	c := &dirCache{watched: map[string]time.Time{"/a": {}, "/b": {}}}
+	start := c.gen
+	c.invalidate("/b")
+	c.invalidate("/unwatched")
+
+	tests := []struct {
+		name string
+		dir  string
+		want bool
+	}{
+		{"other dir changed", "/a", true},
+		{"own dir changed", "/b", false},
+		{"unwatched", "/unwatched", true},
+	}
+	for _, tt := range tests {
+		if got := c.unchangedSince(tt.dir, start); got != tt.want {
+			t.Errorf("%s: unchangedSince = %v, want %v", tt.name, got, tt.want)
+		}
+	}
+	if !c.unchangedSince("/b", c.gen) {
+		t.Error("change before the read started spoiled it")
+	}
+
+	// Lost events spoil every read in flight.
+	start = c.gen
+	c.gen++
+	c.lost = c.gen
+	if c.unchangedSince("/a", start) {
+		t.Error("read kept across lost events")
+	}
+}
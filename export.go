@@ -0,0 +1,122 @@
+package main
+
+import (
+	"io/fs"
+	"os/user"
+	"path/filepath"
+	"time"
+)
+
+type ExportRequest struct {
+	Root string `json:"root"`
+	// Dest is the database file to create.
+	Dest string `json:"dest"`
+}
+
+type ExportResult struct {
+	Path    string `json:"path"`
+	Entries int    `json:"entries"`
+	// Errors counts entries that couldn't be read and were left out.
+	Errors int `json:"errors"`
+}
+
+const exportEntriesSQL = `CREATE TABLE entries (
+	id INTEGER PRIMARY KEY,
+	parent_id INTEGER REFERENCES entries (id),
+	name TEXT NOT NULL,
+	path TEXT NOT NULL,
+	size INTEGER NOT NULL,
+	mtime INTEGER NOT NULL,
+	owner TEXT,
+	type TEXT NOT NULL
+)`
+
+// ExportScanSQLite scans req.Root and writes it to a new SQLite database
+// at req.Dest, with one row per entry in an "entries" table and a "scan"
+// table describing the scan. A folder's size is the total of the files
+// below it; mtime is in Unix seconds.
+func (a *App) ExportScanSQLite(req ExportRequest) (ExportResult, error) {
+	root, dest := filepath.Clean(req.Root), req.Dest
+	result := ExportResult{Path: dest}
+	started := time.Now()
+
+	var rows [][]any
+	ids := make(map[string]int64)
+	owners := make(map[string]any)
+	err := filepath.WalkDir(root, func(path string, d fs.DirEntry, err error) error {
+		if err != nil {
+			if path == root {
+				return err
+			}
+			result.Errors++
+			return nil
+		}
+		info, err := d.Info()
+		if err != nil {
+			result.Errors++
+			// A folder left out can't be the parent of what is inside it.
+			if d.IsDir() {
+				return fs.SkipDir
+			}
+			return nil
+		}
+
+		id := int64(len(rows) + 1)
+		ids[path] = id
+		var parent any
+		if path != root {
+			parent = ids[filepath.Dir(path)]
+		}
+		kind, size := "other", int64(0)
+		switch {
+		case d.IsDir():
+			kind = "folder"
+		case d.Type()&fs.ModeSymlink != 0:
+			kind = "symlink"
+		case d.Type().IsRegular():
+			kind, size = "file", info.Size()
+		}
+		uid := fileOwnerID(info)
+		owner, ok := owners[uid]
+		if !ok {
+			owner = nil
+			if u, err := user.LookupId(uid); err == nil {
+				owner = u.Username
+			} else if uid != "" {
+				owner = uid
+			}
+			owners[uid] = owner
+		}
+		rows = append(rows, []any{id, parent, d.Name(), path, size, info.ModTime().Unix(), owner, kind})
+		return nil
+	})
+	if err != nil {
+		return result, err
+	}
+
+	// Entries are numbered parents first, so a backwards pass adds each
+	// size into its folder before that folder is itself added up.
+	for i := len(rows) - 1; i > 0; i-- {
+		if parent, ok := rows[i][1].(int64); ok {
+			rows[parent-1][4] = rows[parent-1][4].(int64) + rows[i][4].(int64)
+		}
+	}
+	result.Entries = len(rows)
+
+	tables := []sqliteTable{
+		{Name: "entries", SQL: exportEntriesSQL, KeyColumn: 0, Rows: rows},
+		{
+			Name:      "scan",
+			SQL:       "CREATE TABLE scan (root TEXT NOT NULL, started INTEGER NOT NULL, entries INTEGER NOT NULL, errors INTEGER NOT NULL)",
+			KeyColumn: -1,
+			Rows:      [][]any{{root, started.Unix(), int64(result.Entries), int64(result.Errors)}},
+		},
+	}
+	indexes := []sqliteIndex{
+		{Name: "entries_parent", Table: "entries", SQL: "CREATE INDEX entries_parent ON entries (parent_id)", Columns: []int{1}},
+		{Name: "entries_path", Table: "entries", SQL: "CREATE INDEX entries_path ON entries (path)", Columns: []int{3}},
+		{Name: "entries_size", Table: "entries", SQL: "CREATE INDEX entries_size ON entries (size)", Columns: []int{4}},
+		{Name: "entries_type", Table: "entries", SQL: "CREATE INDEX entries_type ON entries (type)", Columns: []int{7}},
+	}
+	return result, writeSQLite(dest, tables, indexes)
+}
@@ -0,0 +1,88 @@
+package main
+
+import (
+	"fmt"
+	"os/exec"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestExportScanSQLite(t *testing.T) {
+	root := t.TempDir()
+	files := map[string]string{}
+	for i := 0; i < 3000; i++ {
+		files[fmt.Sprintf("d%02d/file%04d.txt", i%30, i)] = strings.Repeat("x", i%7)
+	}
+	// Long enough to spill index entries onto overflow pages.
+	deep := strings.Repeat(strings.Repeat("n", 200)+"/", 6) + "leaf.txt"
+	files[deep] = "leaf"
+	writeFiles(t, root, files)
+
+	dest := filepath.Join(t.TempDir(), "scan.db")
+	res, err := NewApp().ExportScanSQLite(ExportRequest{Root: root, Dest: dest})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if res.Entries != 3000+30+6+1+1 {
+		t.Errorf("exported %d entries", res.Entries)
+	}
+
+	db := readSQLiteFile(t, dest)
+	rows := sqliteRows(db.tables["entries"], 0)
+	byID := make(map[int64][]any)
+	for _, row := range rows {
+		byID[row[0].(int64)] = row
+	}
+	count := func(match func(row []any) bool) int {
+		n := 0
+		for _, row := range rows {
+			if match(row) {
+				n++
+			}
+		}
+		return n
+	}
+	tests := []struct {
+		name      string
+		got, want any
+	}{
+		{"entries", len(rows), res.Entries},
+		{"files", count(func(row []any) bool { return row[7] == "file" }), 3001},
+		{"root size", count(func(row []any) bool { return row[1] == nil && row[4] == int64(8994+4) }), 1},
+		{"deep leaf", count(func(row []any) bool { return row[3] == filepath.Join(root, deep) && row[2] == "leaf.txt" }), 1},
+		{"in d07", count(func(row []any) bool { p := byID[asInt64(row[1])]; return p != nil && p[2] == "d07" }), 100},
+		{"orphans", count(func(row []any) bool {
+			p := byID[asInt64(row[1])]
+			return row[1] != nil && (p == nil || p[7] != "folder")
+		}), 0},
+		{"scan entries", sqliteRows(db.tables["scan"], -1)[0][2], int64(res.Entries)},
+	}
+	for _, tt := range tests {
+		if tt.got != tt.want {
+			t.Errorf("%s = %v, want %v", tt.name, tt.got, tt.want)
+		}
+	}
+	indexes := map[string][]int{"entries_parent": {1}, "entries_path": {3}, "entries_size": {4}, "entries_type": {7}}
+	for name, columns := range indexes {
+		if got := db.indexes[name]; !reflect.DeepEqual(got, sqliteIndexKeys(rows, 0, columns)) {
+			t.Errorf("index %s doesn't match the table", name)
+		}
+	}
+
+	// SQLite itself, where it is installed, agrees.
+	sqlite, err := exec.LookPath("sqlite3")
+	if err != nil {
+		return
+	}
+	out, err := exec.Command(sqlite, dest, "PRAGMA integrity_check").CombinedOutput()
+	if got := strings.TrimSpace(string(out)); err != nil || got != "ok" {
+		t.Errorf("integrity_check = %q (%v)", got, err)
+	}
+}
+
+func asInt64(v any) int64 {
+	n, _ := v.(int64)
+	return n
+}
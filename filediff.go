@@ -0,0 +1,214 @@
+package main
+
+import (
+	"bufio"
+	"bytes"
+	"io"
+	"os"
+	"strings"
+)
+
+type DiffOptions struct {
+	// Context is the number of unchanged lines around each change; 0 means
+	// 3, negative means none.
+	Context int `json:"context"`
+	// MaxTextSize is the largest file, in bytes, given a line diff. Larger
+	// files are compared as binary.
+	MaxTextSize int64 `json:"maxTextSize"`
+	// MaxEdits bounds the changed lines of a line diff. Files further
+	// apart than this are compared as binary.
+	MaxEdits int `json:"maxEdits"`
+	// MaxRanges bounds the byte ranges listed for a binary comparison.
+	MaxRanges int `json:"maxRanges"`
+}
+
+type DiffRequest struct {
+	PathA   string      `json:"pathA"`
+	PathB   string      `json:"pathB"`
+	Options DiffOptions `json:"options"`
+}
+
+type DiffLine struct {
+	// Kind is "equal", "insert" or "delete".
+	Kind string `json:"kind"`
+	// A and B are 1-based line numbers in each file, 0 where the line is
+	// absent from that side.
+	A    int    `json:"a"`
+	B    int    `json:"b"`
+	Text string `json:"text"`
+}
+
+type DiffHunk struct {
+	Lines []DiffLine `json:"lines"`
+}
+
+type ByteRange struct {
+	Offset int64 `json:"offset"`
+	Length int64 `json:"length"`
+}
+
+type FileDiff struct {
+	PathA     string `json:"pathA"`
+	PathB     string `json:"pathB"`
+	SizeA     int64  `json:"sizeA"`
+	SizeB     int64  `json:"sizeB"`
+	Identical bool   `json:"identical"`
+	// Binary is set when the files were compared byte by byte rather than
+	// line by line; Reason says why.
+	Binary bool   `json:"binary"`
+	Reason string `json:"reason,omitempty"`
+
+	Unified string     `json:"unified,omitempty"`
+	Hunks   []DiffHunk `json:"hunks,omitempty"`
+	Added   int        `json:"added"`
+	Removed int        `json:"removed"`
+
+	// Ranges are the spans, by offset, where the bytes differ. Bytes past
+	// the end of the shorter file count as different.
+	Ranges    []ByteRange `json:"ranges,omitempty"`
+	DiffBytes int64       `json:"diffBytes"`
+	// Truncated is set when there were more than MaxRanges ranges.
+	Truncated bool `json:"truncated"`
+}
+
+const (
+	defaultDiffContext  = 3
+	defaultDiffTextSize = 4 << 20
+	defaultDiffEdits    = 2000
+	defaultDiffRanges   = 1000
+)
+
+// DiffFiles compares two files: text files line by line, anything else, or
+// text too large or too different for that, as ranges of differing bytes.
+func (a *App) DiffFiles(req DiffRequest) (FileDiff, error) {
+	pathA, pathB, opts := req.PathA, req.PathB, req.Options
+	if opts.Context == 0 {
+		opts.Context = defaultDiffContext
+	}
+	opts.Context = max(opts.Context, 0)
+	if opts.MaxTextSize <= 0 {
+		opts.MaxTextSize = defaultDiffTextSize
+	}
+	if opts.MaxEdits <= 0 {
+		opts.MaxEdits = defaultDiffEdits
+	}
+	if opts.MaxRanges <= 0 {
+		opts.MaxRanges = defaultDiffRanges
+	}
+
+	d := FileDiff{PathA: pathA, PathB: pathB}
+	infoA, err := os.Stat(pathA)
+	if err != nil {
+		return d, err
+	}
+	infoB, err := os.Stat(pathB)
+	if err != nil {
+		return d, err
+	}
+	d.SizeA, d.SizeB = infoA.Size(), infoB.Size()
+
+	if d.SizeA > opts.MaxTextSize || d.SizeB > opts.MaxTextSize {
+		d.Reason = "too large for a line diff"
+		return d, diffBytes(&d, opts.MaxRanges)
+	}
+	dataA, err := os.ReadFile(pathA)
+	if err != nil {
+		return d, err
+	}
+	dataB, err := os.ReadFile(pathB)
+	if err != nil {
+		return d, err
+	}
+	if bytes.Equal(dataA, dataB) {
+		d.Identical = true
+		return d, nil
+	}
+
+	textA, _, okA := decodeText(dataA)
+	textB, _, okB := decodeText(dataB)
+	if !okA || !okB {
+		d.Reason = "not text"
+		return d, diffBytes(&d, opts.MaxRanges)
+	}
+	linesA, linesB := splitLines(textA), splitLines(textB)
+	ops, ok := diffLinesLimit(linesA, linesB, opts.MaxEdits)
+	if !ok {
+		d.Reason = "too many changed lines"
+		return d, diffBytes(&d, opts.MaxRanges)
+	}
+
+	d.Unified = formatUnified(pathA, pathB, linesA, linesB, ops, opts.Context)
+	for _, op := range ops {
+		switch op.Kind {
+		case '+':
+			d.Added++
+		case '-':
+			d.Removed++
+		}
+	}
+	for _, h := range diffHunks(ops, opts.Context) {
+		var hunk DiffHunk
+		for _, op := range ops[h[0]:h[1]] {
+			line := DiffLine{Kind: "equal", A: op.A + 1, B: op.B + 1}
+			switch op.Kind {
+			case '+':
+				line.Kind, line.A, line.Text = "insert", 0, linesB[op.B]
+			case '-':
+				line.Kind, line.B, line.Text = "delete", 0, linesA[op.A]
+			default:
+				line.Text = linesA[op.A]
+			}
+			line.Text = strings.TrimRight(line.Text, "\r\n")
+			hunk.Lines = append(hunk.Lines, line)
+		}
+		d.Hunks = append(d.Hunks, hunk)
+	}
+	return d, nil
+}
+
+// diffBytes streams both files and records where they differ.
+func diffBytes(d *FileDiff, maxRanges int) error {
+	fa, err := os.Open(d.PathA)
+	if err != nil {
+		return err
+	}
+	defer fa.Close()
+	fb, err := os.Open(d.PathB)
+	if err != nil {
+		return err
+	}
+	defer fb.Close()
+
+	d.Binary = true
+	ra, rb := bufio.NewReaderSize(fa, 64<<10), bufio.NewReaderSize(fb, 64<<10)
+	var run *ByteRange
+	for off := int64(0); ; off++ {
+		ba, errA := ra.ReadByte()
+		bb, errB := rb.ReadByte()
+		if errA == io.EOF && errB == io.EOF {
+			break
+		}
+		if errA != nil && errA != io.EOF {
+			return errA
+		}
+		if errB != nil && errB != io.EOF {
+			return errB
+		}
+		if errA == nil && errB == nil && ba == bb {
+			run = nil
+			continue
+		}
+
+		d.DiffBytes++
+		if run != nil {
+			run.Length++
+		} else if len(d.Ranges) < maxRanges {
+			d.Ranges = append(d.Ranges, ByteRange{Offset: off, Length: 1})
+			run = &d.Ranges[len(d.Ranges)-1]
+		} else {
+			d.Truncated = true
+		}
+	}
+	d.Identical = d.DiffBytes == 0
+	return nil
+}
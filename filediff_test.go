@@ -0,0 +1,66 @@
+package main
+
+import (
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestDiffFiles(t *testing.T) {
+	root := t.TempDir()
+	writeFiles(t, root, map[string]string{
+		"a.txt":     "one\ntwo\nthree\n",
+		"b.txt":     "one\n2\nthree\nfour\n",
+		"same.txt":  "one\ntwo\nthree\n",
+		"a.bin":     "\x00abcdefgh",
+		"b.bin":     "\x00aXcdeYYhij",
+		"crlf.txt":  "one\r\ntwo\r\n",
+		"crlf2.txt": "one\r\nTWO\r\n",
+	})
+	a := NewApp()
+	path := func(name string) string { return filepath.Join(root, name) }
+
+	tests := []struct {
+		name           string
+		a, b           string
+		opts           DiffOptions
+		identical      bool
+		binary         bool
+		added, removed int
+		ranges         []ByteRange
+		diffBytes      int64
+		truncated      bool
+	}{
+		{"text", "a.txt", "b.txt", DiffOptions{}, false, false, 2, 1, nil, 0, false},
+		{"identical", "a.txt", "same.txt", DiffOptions{}, true, false, 0, 0, nil, 0, false},
+		{"binary", "a.bin", "b.bin", DiffOptions{}, false, true, 0, 0, []ByteRange{{2, 1}, {6, 2}, {9, 2}}, 5, false},
+		{"binary truncated", "a.bin", "b.bin", DiffOptions{MaxRanges: 2}, false, true, 0, 0, []ByteRange{{2, 1}, {6, 2}}, 5, true},
+		{"text too large", "a.txt", "b.txt", DiffOptions{MaxTextSize: 8}, false, true, 0, 0, []ByteRange{{4, 13}}, 13, false},
+		{"too many edits", "a.txt", "b.txt", DiffOptions{MaxEdits: 2}, false, true, 0, 0, []ByteRange{{4, 13}}, 13, false},
+	}
+	for _, tt := range tests {
+		d, err := a.DiffFiles(DiffRequest{PathA: path(tt.a), PathB: path(tt.b), Options: tt.opts})
+		if err != nil {
+			t.Fatalf("%s: %v", tt.name, err)
+		}
+		if d.Identical != tt.identical || d.Binary != tt.binary || d.Added != tt.added || d.Removed != tt.removed {
+			t.Errorf("%s: identical %v binary %v +%d -%d", tt.name, d.Identical, d.Binary, d.Added, d.Removed)
+		}
+		if !reflect.DeepEqual(d.Ranges, tt.ranges) || d.DiffBytes != tt.diffBytes || d.Truncated != tt.truncated {
+			t.Errorf("%s: ranges %v (%d bytes, truncated %v)", tt.name, d.Ranges, d.DiffBytes, d.Truncated)
+		}
+	}
+
+	d, _ := a.DiffFiles(DiffRequest{PathA: path("crlf.txt"), PathB: path("crlf2.txt"), Options: DiffOptions{Context: -1}})
+	want := []DiffLine{{"delete", 2, 0, "two"}, {"insert", 0, 2, "TWO"}}
+	if len(d.Hunks) != 1 || !reflect.DeepEqual(d.Hunks[0].Lines, want) {
+		t.Errorf("crlf hunks = %+v", d.Hunks)
+	}
+	if !strings.Contains(d.Unified, "@@ -2,1 +2,1 @@\n-two\r\n+TWO\r\n") {
+		t.Errorf("crlf unified:\n%q", d.Unified)
+	}
+	if _, err := a.DiffFiles(DiffRequest{PathA: path("missing"), PathB: path("a.txt")}); err == nil {
+		t.Error("missing file succeeded, want error")
+	}
+}
@@ -0,0 +1,46 @@
+package main
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestMoveAndCopyPath(t *testing.T) {
+	root := t.TempDir()
+	writeFiles(t, root, map[string]string{
+		"src/a.txt":     "a",
+		"src/sub/b.txt": "b",
+		"taken.txt":     "x",
+		"taken (2).txt": "x",
+	})
+
+	if err := copyPath(filepath.Join(root, "src"), filepath.Join(root, "copy")); err != nil {
+		t.Fatal(err)
+	}
+	if err := movePath(filepath.Join(root, "src"), filepath.Join(root, "new/dir/moved")); err != nil {
+		t.Fatal(err)
+	}
+	tests := []struct {
+		path   string
+		exists bool
+	}{
+		{"copy/a.txt", true},
+		{"copy/sub/b.txt", true},
+		{"new/dir/moved/sub/b.txt", true},
+		{"src", false},
+	}
+	for _, tt := range tests {
+		_, err := os.Stat(filepath.Join(root, tt.path))
+		if (err == nil) != tt.exists {
+			t.Errorf("%s: exists = %v, want %v", tt.path, err == nil, tt.exists)
+		}
+	}
+
+	if got := freePath(filepath.Join(root, "taken.txt")); filepath.Base(got) != "taken (3).txt" {
+		t.Errorf("freePath = %s", got)
+	}
+	if got := freePath(filepath.Join(root, "free.txt")); filepath.Base(got) != "free.txt" {
+		t.Errorf("freePath = %s", got)
+	}
+}
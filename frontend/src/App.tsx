@@ -1,6 +1,7 @@
 import React, { useState, useEffect } from 'react';
 import Visualizer, { NodeData, LinkData } from './components/Visualizer';
-import { ReadDir } from "../wailsjs/go/main/App"; 
+import { ReadDir } from "../wailsjs/go/main/App";
+import { main } from "../wailsjs/go/models";
 
 function App() {
     //TODO: root_path different for unix and windows system
@@ -15,7 +16,7 @@ function App() {
     const rootNode: NodeData = { 
         id: ROOT_PATH, 
         name: "ROOT", 
-        type: "folder" 
+        type: "folder"
     };
 
     setGraphData({
@@ -25,7 +26,7 @@ function App() {
   }, []);
 
     const handleClick = (node: NodeData) => {
-        if (node.type !== 'folder') return
+        if (node.type !== "folder") return
         const getId = (item: any) => (typeof item === 'object' ? item.id : item);
         const isAlreadyExpanded = graphData.links.some(link => getId(link.source) === node.id);
         if (isAlreadyExpanded) {
@@ -69,11 +70,11 @@ function App() {
         }));
     };
   const handleExpand = async (node: NodeData) => {
-    if (node.type !== 'folder') return
+    if (node.type !== "folder") return
 
 
     try {
-        const files = await ReadDir(node.id);
+        const { nodes: files } = await ReadDir({ path: node.id });
 
         if (!files || files.length === 0) {
             return;
@@ -82,13 +83,13 @@ function App() {
         const newNodes: NodeData[] = [];
         const newLinks: LinkData[] = [];
 
-        files.forEach((file: any) => {
+        files.forEach((file: main.FileNode) => {
             const exists = graphData.nodes.find(n => n.id === file.path);
             if (!exists) {
                 newNodes.push({
                     id: file.path, 
                     name: file.name,
-                    type: file.type === "folder" ? "folder" : "file",
+                    type: file.type,
                 });
 
                 newLinks.push({
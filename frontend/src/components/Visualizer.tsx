@@ -2,11 +2,14 @@ import React, { useState, useEffect, useRef } from "react";
 import { Stage, Container, Graphics, Text } from "@pixi/react";
 import * as PIXI from "pixi.js";
 import * as d3 from "d3";
+import { main } from "../../wailsjs/go/models";
+
+export type NodeType = main.FileNode["type"];
 
 export interface NodeData extends d3.SimulationNodeDatum {
   id: string;
   name: string;
-  type: "folder" | "file";
+  type: NodeType;
   x?: number;
   y?: number;
   fx?: number | null;
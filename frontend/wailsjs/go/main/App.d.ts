@@ -2,4 +2,108 @@
 // This file is automatically generated. DO NOT EDIT
 import {main} from '../models';
 
-export function ReadDir(arg1:string):Promise<Array<main.FileNode>>;
+export function APIInfo():Promise<main.APIInfo>;
+
+export function AnalyzeModCache(arg1:main.ModCacheRequest):Promise<main.ModCacheReport>;
+
+export function AnalyzeNodeModules(arg1:main.RootRequest):Promise<main.NodeModulesReport>;
+
+export function AnalyzePath():Promise<main.PathReport>;
+
+export function Ancestors(arg1:main.PathRequest):Promise<main.NodeList>;
+
+export function AnomalyAlerts():Promise<main.AnomalyAlertList>;
+
+export function ApplyReplace(arg1:main.ApplyReplaceRequest):Promise<main.ReplaceResult>;
+
+export function AuditDependencies(arg1:main.AuditRequest):Promise<main.VulnReport>;
+
+export function CheckMarkdownLinks(arg1:main.RootRequest):Promise<main.DocLinkReport>;
+
+export function CleanModCache(arg1:main.CleanModCacheRequest):Promise<main.CleanModCacheResult>;
+
+export function CompletePath(arg1:main.CompletePathRequest):Promise<main.PathCompletions>;
+
+export function DiffFiles(arg1:main.DiffRequest):Promise<main.FileDiff>;
+
+export function DiffHistory(arg1:main.DiffHistoryRequest):Promise<main.HistoryDiff>;
+
+export function EstimateTree(arg1:main.ScanOptions):Promise<main.ScanEstimate>;
+
+export function ExportSBOM(arg1:main.SBOMRequest):Promise<void>;
+
+export function ExportScanSQLite(arg1:main.ExportRequest):Promise<main.ExportResult>;
+
+export function FileHistory(arg1:main.HistoryFileRequest):Promise<main.HistoryVersions>;
+
+export function FuzzyFind(arg1:main.FuzzyFindRequest):Promise<main.FuzzyMatches>;
+
+export function GenerateSBOM(arg1:main.SBOMRequest):Promise<main.SBOMDocument>;
+
+export function IndexContent(arg1:main.RootRequest):Promise<main.ContentIndexStats>;
+
+export function IndexPaths(arg1:main.RootRequest):Promise<main.IndexPathsResult>;
+
+export function InspectText(arg1:main.RootRequest):Promise<main.TextReport>;
+
+export function NormalizeText(arg1:main.NormalizeRequest):Promise<main.NormalizeResult>;
+
+export function PreviewReplace(arg1:main.ReplaceRequest):Promise<main.ReplacePreview>;
+
+export function ReadDataNode(arg1:main.PathRequest):Promise<main.NodeList>;
+
+export function ReadDir(arg1:main.PathRequest):Promise<main.NodeList>;
+
+export function ReadDirs(arg1:main.ReadDirsRequest):Promise<main.DirListings>;
+
+export function ReadGoOutline(arg1:main.PathRequest):Promise<main.NodeList>;
+
+export function ReadPathRoot():Promise<main.NodeList>;
+
+export function RecentFiles(arg1:main.RecentQuery):Promise<main.RecentPage>;
+
+export function ReplaceHistory():Promise<main.ReplaceJournals>;
+
+export function Reroot(arg1:main.RerootRequest):Promise<main.FileNode>;
+
+export function RestoreHistory(arg1:main.RestoreHistoryRequest):Promise<void>;
+
+export function RuleLog():Promise<main.RuleLogEntries>;
+
+export function ScanDependencies(arg1:main.RootRequest):Promise<main.DependencyList>;
+
+export function SearchContent(arg1:main.SearchContentRequest):Promise<main.ContentSearchResult>;
+
+export function SetRules(arg1:main.SetRulesRequest):Promise<void>;
+
+export function Siblings(arg1:main.PathRequest):Promise<main.NodeList>;
+
+export function SpliceRoot(arg1:main.SpliceRootRequest):Promise<main.FileNode>;
+
+export function StartScan(arg1:main.ScanOptions):Promise<void>;
+
+export function StopRules():Promise<void>;
+
+export function StopScan():Promise<void>;
+
+export function StopTail(arg1:main.StopTailRequest):Promise<void>;
+
+export function StopWatchAnomalies():Promise<void>;
+
+export function StopWatchContentIndex():Promise<void>;
+
+export function StopWatchHistory():Promise<void>;
+
+export function StopWatchRecent():Promise<void>;
+
+export function TailFile(arg1:main.TailRequest):Promise<main.TailStarted>;
+
+export function UndoReplace(arg1:main.UndoReplaceRequest):Promise<main.ReplaceResult>;
+
+export function WatchAnomalies(arg1:main.WatchAnomaliesRequest):Promise<void>;
+
+export function WatchContentIndex(arg1:main.RootRequest):Promise<void>;
+
+export function WatchHistory(arg1:main.WatchHistoryRequest):Promise<void>;
+
+export function WatchRecent(arg1:main.RecentQuery):Promise<void>;
@@ -2,6 +2,214 @@
 // Cynhyrchwyd y ffeil hon yn awtomatig. PEIDIWCH Â MODIWL
 // This file is automatically generated. DO NOT EDIT
 
+export function APIInfo() {
+  return window['go']['main']['App']['APIInfo']();
+}
+
+export function AnalyzeModCache(arg1) {
+  return window['go']['main']['App']['AnalyzeModCache'](arg1);
+}
+
+export function AnalyzeNodeModules(arg1) {
+  return window['go']['main']['App']['AnalyzeNodeModules'](arg1);
+}
+
+export function AnalyzePath() {
+  return window['go']['main']['App']['AnalyzePath']();
+}
+
+export function Ancestors(arg1) {
+  return window['go']['main']['App']['Ancestors'](arg1);
+}
+
+export function AnomalyAlerts() {
+  return window['go']['main']['App']['AnomalyAlerts']();
+}
+
+export function ApplyReplace(arg1) {
+  return window['go']['main']['App']['ApplyReplace'](arg1);
+}
+
+export function AuditDependencies(arg1) {
+  return window['go']['main']['App']['AuditDependencies'](arg1);
+}
+
+export function CheckMarkdownLinks(arg1) {
+  return window['go']['main']['App']['CheckMarkdownLinks'](arg1);
+}
+
+export function CleanModCache(arg1) {
+  return window['go']['main']['App']['CleanModCache'](arg1);
+}
+
+export function CompletePath(arg1) {
+  return window['go']['main']['App']['CompletePath'](arg1);
+}
+
+export function DiffFiles(arg1) {
+  return window['go']['main']['App']['DiffFiles'](arg1);
+}
+
+export function DiffHistory(arg1) {
+  return window['go']['main']['App']['DiffHistory'](arg1);
+}
+
+export function EstimateTree(arg1) {
+  return window['go']['main']['App']['EstimateTree'](arg1);
+}
+
+export function ExportSBOM(arg1) {
+  return window['go']['main']['App']['ExportSBOM'](arg1);
+}
+
+export function ExportScanSQLite(arg1) {
+  return window['go']['main']['App']['ExportScanSQLite'](arg1);
+}
+
+export function FileHistory(arg1) {
+  return window['go']['main']['App']['FileHistory'](arg1);
+}
+
+export function FuzzyFind(arg1) {
+  return window['go']['main']['App']['FuzzyFind'](arg1);
+}
+
+export function GenerateSBOM(arg1) {
+  return window['go']['main']['App']['GenerateSBOM'](arg1);
+}
+
+export function IndexContent(arg1) {
+  return window['go']['main']['App']['IndexContent'](arg1);
+}
+
+export function IndexPaths(arg1) {
+  return window['go']['main']['App']['IndexPaths'](arg1);
+}
+
+export function InspectText(arg1) {
+  return window['go']['main']['App']['InspectText'](arg1);
+}
+
+export function NormalizeText(arg1) {
+  return window['go']['main']['App']['NormalizeText'](arg1);
+}
+
+export function PreviewReplace(arg1) {
+  return window['go']['main']['App']['PreviewReplace'](arg1);
+}
+
+export function ReadDataNode(arg1) {
+  return window['go']['main']['App']['ReadDataNode'](arg1);
+}
+
 export function ReadDir(arg1) {
   return window['go']['main']['App']['ReadDir'](arg1);
 }
+
+export function ReadDirs(arg1) {
+  return window['go']['main']['App']['ReadDirs'](arg1);
+}
+
+export function ReadGoOutline(arg1) {
+  return window['go']['main']['App']['ReadGoOutline'](arg1);
+}
+
+export function ReadPathRoot() {
+  return window['go']['main']['App']['ReadPathRoot']();
+}
+
+export function RecentFiles(arg1) {
+  return window['go']['main']['App']['RecentFiles'](arg1);
+}
+
+export function ReplaceHistory() {
+  return window['go']['main']['App']['ReplaceHistory']();
+}
+
+export function Reroot(arg1) {
+  return window['go']['main']['App']['Reroot'](arg1);
+}
+
+export function RestoreHistory(arg1) {
+  return window['go']['main']['App']['RestoreHistory'](arg1);
+}
+
+export function RuleLog() {
+  return window['go']['main']['App']['RuleLog']();
+}
+
+export function ScanDependencies(arg1) {
+  return window['go']['main']['App']['ScanDependencies'](arg1);
+}
+
+export function SearchContent(arg1) {
+  return window['go']['main']['App']['SearchContent'](arg1);
+}
+
+export function SetRules(arg1) {
+  return window['go']['main']['App']['SetRules'](arg1);
+}
+
+export function Siblings(arg1) {
+  return window['go']['main']['App']['Siblings'](arg1);
+}
+
+export function SpliceRoot(arg1) {
+  return window['go']['main']['App']['SpliceRoot'](arg1);
+}
+
+export function StartScan(arg1) {
+  return window['go']['main']['App']['StartScan'](arg1);
+}
+
+export function StopRules() {
+  return window['go']['main']['App']['StopRules']();
+}
+
+export function StopScan() {
+  return window['go']['main']['App']['StopScan']();
+}
+
+export function StopTail(arg1) {
+  return window['go']['main']['App']['StopTail'](arg1);
+}
+
+export function StopWatchAnomalies() {
+  return window['go']['main']['App']['StopWatchAnomalies']();
+}
+
+export function StopWatchContentIndex() {
+  return window['go']['main']['App']['StopWatchContentIndex']();
+}
+
+export function StopWatchHistory() {
+  return window['go']['main']['App']['StopWatchHistory']();
+}
+
+export function StopWatchRecent() {
+  return window['go']['main']['App']['StopWatchRecent']();
+}
+
+export function TailFile(arg1) {
+  return window['go']['main']['App']['TailFile'](arg1);
+}
+
+export function UndoReplace(arg1) {
+  return window['go']['main']['App']['UndoReplace'](arg1);
+}
+
+export function WatchAnomalies(arg1) {
+  return window['go']['main']['App']['WatchAnomalies'](arg1);
+}
+
+export function WatchContentIndex(arg1) {
+  return window['go']['main']['App']['WatchContentIndex'](arg1);
+}
+
+export function WatchHistory(arg1) {
+  return window['go']['main']['App']['WatchHistory'](arg1);
+}
+
+export function WatchRecent(arg1) {
+  return window['go']['main']['App']['WatchRecent'](arg1);
+}
@@ -1,23 +1,2225 @@
 export namespace main {
 	
-	export class FileNode {
+	export class APIMethod {
+	    name: string;
+	    params: string[];
+	    results: string[];
+	
+	    static createFrom(source: any = {}) {
+	        return new APIMethod(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.name = source["name"];
+	        this.params = source["params"];
+	        this.results = source["results"];
+	    }
+	}
+	export class APIInfo {
+	    version: string;
+	    platform: string;
+	    capabilities: string[];
+	    methods: APIMethod[];
+	
+	    static createFrom(source: any = {}) {
+	        return new APIInfo(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.version = source["version"];
+	        this.platform = source["platform"];
+	        this.capabilities = source["capabilities"];
+	        this.methods = this.convertValues(source["methods"], APIMethod);
+	    }
+	
+		convertValues(a: any, classs: any, asMap: boolean = false): any {
+		    if (!a) {
+		        return a;
+		    }
+		    if (a.slice && a.map) {
+		        return (a as any[]).map(elem => this.convertValues(elem, classs));
+		    } else if ("object" === typeof a) {
+		        if (asMap) {
+		            for (const key of Object.keys(a)) {
+		                a[key] = new classs(a[key]);
+		            }
+		            return a;
+		        }
+		        return new classs(a);
+		    }
+		    return a;
+		}
+	}
+	
+	export class Advisory {
+	    id: string;
+	    aliases?: string[];
+	    summary?: string;
+	    package: string;
+	    version: string;
+	    ecosystem: string;
+	    fixed: string[];
+	    source: string;
+	
+	    static createFrom(source: any = {}) {
+	        return new Advisory(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.id = source["id"];
+	        this.aliases = source["aliases"];
+	        this.summary = source["summary"];
+	        this.package = source["package"];
+	        this.version = source["version"];
+	        this.ecosystem = source["ecosystem"];
+	        this.fixed = source["fixed"];
+	        this.source = source["source"];
+	    }
+	}
+	export class ProcessInfo {
+	    pid: number;
 	    name: string;
+	    paths: string[];
+	
+	    static createFrom(source: any = {}) {
+	        return new ProcessInfo(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.pid = source["pid"];
+	        this.name = source["name"];
+	        this.paths = source["paths"];
+	    }
+	}
+	export class AnomalyAlert {
+	    time: string;
+	    root: string;
+	    renamed: number;
+	    rewritten: number;
+	    entropy: number;
+	    paths: string[];
+	    processes: ProcessInfo[];
+	
+	    static createFrom(source: any = {}) {
+	        return new AnomalyAlert(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.time = source["time"];
+	        this.root = source["root"];
+	        this.renamed = source["renamed"];
+	        this.rewritten = source["rewritten"];
+	        this.entropy = source["entropy"];
+	        this.paths = source["paths"];
+	        this.processes = this.convertValues(source["processes"], ProcessInfo);
+	    }
+	
+		convertValues(a: any, classs: any, asMap: boolean = false): any {
+		    if (!a) {
+		        return a;
+		    }
+		    if (a.slice && a.map) {
+		        return (a as any[]).map(elem => this.convertValues(elem, classs));
+		    } else if ("object" === typeof a) {
+		        if (asMap) {
+		            for (const key of Object.keys(a)) {
+		                a[key] = new classs(a[key]);
+		            }
+		            return a;
+		        }
+		        return new classs(a);
+		    }
+		    return a;
+		}
+	}
+	export class AnomalyAlertList {
+	    alerts: AnomalyAlert[];
+	
+	    static createFrom(source: any = {}) {
+	        return new AnomalyAlertList(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.alerts = this.convertValues(source["alerts"], AnomalyAlert);
+	    }
+	
+		convertValues(a: any, classs: any, asMap: boolean = false): any {
+		    if (!a) {
+		        return a;
+		    }
+		    if (a.slice && a.map) {
+		        return (a as any[]).map(elem => this.convertValues(elem, classs));
+		    } else if ("object" === typeof a) {
+		        if (asMap) {
+		            for (const key of Object.keys(a)) {
+		                a[key] = new classs(a[key]);
+		            }
+		            return a;
+		        }
+		        return new classs(a);
+		    }
+		    return a;
+		}
+	}
+	export class AnomalyOptions {
+	    windowSeconds: number;
+	    threshold: number;
+	    minEntropy: number;
+	
+	    static createFrom(source: any = {}) {
+	        return new AnomalyOptions(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.windowSeconds = source["windowSeconds"];
+	        this.threshold = source["threshold"];
+	        this.minEntropy = source["minEntropy"];
+	    }
+	}
+	export class ReplaceSelection {
 	    path: string;
-	    type: string;
+	    hash: string;
+	
+	    static createFrom(source: any = {}) {
+	        return new ReplaceSelection(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.path = source["path"];
+	        this.hash = source["hash"];
+	    }
+	}
+	export class ReplaceRequest {
+	    root: string;
+	    paths: string[];
+	    pattern: string;
+	    replacement: string;
+	
+	    static createFrom(source: any = {}) {
+	        return new ReplaceRequest(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.root = source["root"];
+	        this.paths = source["paths"];
+	        this.pattern = source["pattern"];
+	        this.replacement = source["replacement"];
+	    }
+	}
+	export class ApplyReplaceRequest {
+	    replace: ReplaceRequest;
+	    selected: ReplaceSelection[];
+	
+	    static createFrom(source: any = {}) {
+	        return new ApplyReplaceRequest(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.replace = this.convertValues(source["replace"], ReplaceRequest);
+	        this.selected = this.convertValues(source["selected"], ReplaceSelection);
+	    }
+	
+		convertValues(a: any, classs: any, asMap: boolean = false): any {
+		    if (!a) {
+		        return a;
+		    }
+		    if (a.slice && a.map) {
+		        return (a as any[]).map(elem => this.convertValues(elem, classs));
+		    } else if ("object" === typeof a) {
+		        if (asMap) {
+		            for (const key of Object.keys(a)) {
+		                a[key] = new classs(a[key]);
+		            }
+		            return a;
+		        }
+		        return new classs(a);
+		    }
+		    return a;
+		}
+	}
+	export class AuditRequest {
+	    root: string;
+	    dbPath: string;
+	
+	    static createFrom(source: any = {}) {
+	        return new AuditRequest(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.root = source["root"];
+	        this.dbPath = source["dbPath"];
+	    }
+	}
+	export class BrokenLink {
+	    source: string;
+	    target: string;
+	    anchor?: string;
+	    line: number;
+	    reason: string;
+	
+	    static createFrom(source: any = {}) {
+	        return new BrokenLink(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.source = source["source"];
+	        this.target = source["target"];
+	        this.anchor = source["anchor"];
+	        this.line = source["line"];
+	        this.reason = source["reason"];
+	    }
+	}
+	export class ByteRange {
+	    offset: number;
+	    length: number;
+	
+	    static createFrom(source: any = {}) {
+	        return new ByteRange(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.offset = source["offset"];
+	        this.length = source["length"];
+	    }
+	}
+	export class ModuleVersion {
+	    module: string;
+	    version: string;
+	    dir?: string;
 	    size: number;
-	    children?: FileNode[];
+	    referenced: boolean;
+	    referencedBy?: string[];
 	
 	    static createFrom(source: any = {}) {
-	        return new FileNode(source);
+	        return new ModuleVersion(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.module = source["module"];
+	        this.version = source["version"];
+	        this.dir = source["dir"];
+	        this.size = source["size"];
+	        this.referenced = source["referenced"];
+	        this.referencedBy = source["referencedBy"];
+	    }
+	}
+	export class CachedModule {
+	    path: string;
+	    size: number;
+	    versions: ModuleVersion[];
+	
+	    static createFrom(source: any = {}) {
+	        return new CachedModule(source);
 	    }
 	
 	    constructor(source: any = {}) {
 	        if ('string' === typeof source) source = JSON.parse(source);
-	        this.name = source["name"];
 	        this.path = source["path"];
-	        this.type = source["type"];
 	        this.size = source["size"];
-	        this.children = this.convertValues(source["children"], FileNode);
+	        this.versions = this.convertValues(source["versions"], ModuleVersion);
+	    }
+	
+		convertValues(a: any, classs: any, asMap: boolean = false): any {
+		    if (!a) {
+		        return a;
+		    }
+		    if (a.slice && a.map) {
+		        return (a as any[]).map(elem => this.convertValues(elem, classs));
+		    } else if ("object" === typeof a) {
+		        if (asMap) {
+		            for (const key of Object.keys(a)) {
+		                a[key] = new classs(a[key]);
+		            }
+		            return a;
+		        }
+		        return new classs(a);
+		    }
+		    return a;
+		}
+	}
+	export class CleanModCacheRequest {
+	    projectRoots: string[];
+	    versions: ModuleVersion[];
+	    dryRun: boolean;
+	
+	    static createFrom(source: any = {}) {
+	        return new CleanModCacheRequest(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.projectRoots = source["projectRoots"];
+	        this.versions = this.convertValues(source["versions"], ModuleVersion);
+	        this.dryRun = source["dryRun"];
+	    }
+	
+		convertValues(a: any, classs: any, asMap: boolean = false): any {
+		    if (!a) {
+		        return a;
+		    }
+		    if (a.slice && a.map) {
+		        return (a as any[]).map(elem => this.convertValues(elem, classs));
+		    } else if ("object" === typeof a) {
+		        if (asMap) {
+		            for (const key of Object.keys(a)) {
+		                a[key] = new classs(a[key]);
+		            }
+		            return a;
+		        }
+		        return new classs(a);
+		    }
+		    return a;
+		}
+	}
+	export class CleanModCacheResult {
+	    removed: string[];
+	
+	    static createFrom(source: any = {}) {
+	        return new CleanModCacheResult(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.removed = source["removed"];
+	    }
+	}
+	export class CompletePathRequest {
+	    partial: string;
+	
+	    static createFrom(source: any = {}) {
+	        return new CompletePathRequest(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.partial = source["partial"];
+	    }
+	}
+	export class ContentIndexStats {
+	    root: string;
+	    files: number;
+	    reused: number;
+	    indexed: number;
+	    removed: number;
+	    skipped: number;
+	    trigrams: number;
+	
+	    static createFrom(source: any = {}) {
+	        return new ContentIndexStats(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.root = source["root"];
+	        this.files = source["files"];
+	        this.reused = source["reused"];
+	        this.indexed = source["indexed"];
+	        this.removed = source["removed"];
+	        this.skipped = source["skipped"];
+	        this.trigrams = source["trigrams"];
+	    }
+	}
+	export class ContentMatch {
+	    path: string;
+	    line: number;
+	    column: number;
+	    text: string;
+	    start: number;
+	    end: number;
+	
+	    static createFrom(source: any = {}) {
+	        return new ContentMatch(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.path = source["path"];
+	        this.line = source["line"];
+	        this.column = source["column"];
+	        this.text = source["text"];
+	        this.start = source["start"];
+	        this.end = source["end"];
+	    }
+	}
+	export class ContentSearchResult {
+	    files: number;
+	    candidates: number;
+	    matches: ContentMatch[];
+	    truncated: boolean;
+	
+	    static createFrom(source: any = {}) {
+	        return new ContentSearchResult(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.files = source["files"];
+	        this.candidates = source["candidates"];
+	        this.matches = this.convertValues(source["matches"], ContentMatch);
+	        this.truncated = source["truncated"];
+	    }
+	
+		convertValues(a: any, classs: any, asMap: boolean = false): any {
+		    if (!a) {
+		        return a;
+		    }
+		    if (a.slice && a.map) {
+		        return (a as any[]).map(elem => this.convertValues(elem, classs));
+		    } else if ("object" === typeof a) {
+		        if (asMap) {
+		            for (const key of Object.keys(a)) {
+		                a[key] = new classs(a[key]);
+		            }
+		            return a;
+		        }
+		        return new classs(a);
+		    }
+		    return a;
+		}
+	}
+	export class Dependency {
+	    name: string;
+	    version: string;
+	    ecosystem: string;
+	    purl: string;
+	    source: string;
+	    license?: string;
+	    sha512?: string;
+	    dev?: boolean;
+	
+	    static createFrom(source: any = {}) {
+	        return new Dependency(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.name = source["name"];
+	        this.version = source["version"];
+	        this.ecosystem = source["ecosystem"];
+	        this.purl = source["purl"];
+	        this.source = source["source"];
+	        this.license = source["license"];
+	        this.sha512 = source["sha512"];
+	        this.dev = source["dev"];
+	    }
+	}
+	export class DependencyList {
+	    dependencies: Dependency[];
+	
+	    static createFrom(source: any = {}) {
+	        return new DependencyList(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.dependencies = this.convertValues(source["dependencies"], Dependency);
+	    }
+	
+		convertValues(a: any, classs: any, asMap: boolean = false): any {
+		    if (!a) {
+		        return a;
+		    }
+		    if (a.slice && a.map) {
+		        return (a as any[]).map(elem => this.convertValues(elem, classs));
+		    } else if ("object" === typeof a) {
+		        if (asMap) {
+		            for (const key of Object.keys(a)) {
+		                a[key] = new classs(a[key]);
+		            }
+		            return a;
+		        }
+		        return new classs(a);
+		    }
+		    return a;
+		}
+	}
+	export class DiffHistoryRequest {
+	    root: string;
+	    path: string;
+	    from: string;
+	    to: string;
+	
+	    static createFrom(source: any = {}) {
+	        return new DiffHistoryRequest(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.root = source["root"];
+	        this.path = source["path"];
+	        this.from = source["from"];
+	        this.to = source["to"];
+	    }
+	}
+	export class DiffLine {
+	    kind: string;
+	    a: number;
+	    b: number;
+	    text: string;
+	
+	    static createFrom(source: any = {}) {
+	        return new DiffLine(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.kind = source["kind"];
+	        this.a = source["a"];
+	        this.b = source["b"];
+	        this.text = source["text"];
+	    }
+	}
+	export class DiffHunk {
+	    lines: DiffLine[];
+	
+	    static createFrom(source: any = {}) {
+	        return new DiffHunk(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.lines = this.convertValues(source["lines"], DiffLine);
+	    }
+	
+		convertValues(a: any, classs: any, asMap: boolean = false): any {
+		    if (!a) {
+		        return a;
+		    }
+		    if (a.slice && a.map) {
+		        return (a as any[]).map(elem => this.convertValues(elem, classs));
+		    } else if ("object" === typeof a) {
+		        if (asMap) {
+		            for (const key of Object.keys(a)) {
+		                a[key] = new classs(a[key]);
+		            }
+		            return a;
+		        }
+		        return new classs(a);
+		    }
+		    return a;
+		}
+	}
+	
+	export class DiffOptions {
+	    context: number;
+	    maxTextSize: number;
+	    maxEdits: number;
+	    maxRanges: number;
+	
+	    static createFrom(source: any = {}) {
+	        return new DiffOptions(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.context = source["context"];
+	        this.maxTextSize = source["maxTextSize"];
+	        this.maxEdits = source["maxEdits"];
+	        this.maxRanges = source["maxRanges"];
+	    }
+	}
+	export class DiffRequest {
+	    pathA: string;
+	    pathB: string;
+	    options: DiffOptions;
+	
+	    static createFrom(source: any = {}) {
+	        return new DiffRequest(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.pathA = source["pathA"];
+	        this.pathB = source["pathB"];
+	        this.options = this.convertValues(source["options"], DiffOptions);
+	    }
+	
+		convertValues(a: any, classs: any, asMap: boolean = false): any {
+		    if (!a) {
+		        return a;
+		    }
+		    if (a.slice && a.map) {
+		        return (a as any[]).map(elem => this.convertValues(elem, classs));
+		    } else if ("object" === typeof a) {
+		        if (asMap) {
+		            for (const key of Object.keys(a)) {
+		                a[key] = new classs(a[key]);
+		            }
+		            return a;
+		        }
+		        return new classs(a);
+		    }
+		    return a;
+		}
+	}
+	export class FileNode {
+	    name: string;
+	    path: string;
+	    type: 'file' | 'folder' | 'data' | 'decl';
+	    kind?: 'object' | 'array' | 'string' | 'number' | 'boolean' | 'null' | 'datetime' | 'stream' | 'package' | 'func' | 'method' | 'type' | 'const' | 'var' | 'path-entry';
+	    size: number;
+	    line?: number;
+	    endLine?: number;
+	    detail?: string;
+	    children?: FileNode[];
+	
+	    static createFrom(source: any = {}) {
+	        return new FileNode(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.name = source["name"];
+	        this.path = source["path"];
+	        this.type = source["type"];
+	        this.kind = source["kind"];
+	        this.size = source["size"];
+	        this.line = source["line"];
+	        this.endLine = source["endLine"];
+	        this.detail = source["detail"];
+	        this.children = this.convertValues(source["children"], FileNode);
+	    }
+	
+		convertValues(a: any, classs: any, asMap: boolean = false): any {
+		    if (!a) {
+		        return a;
+		    }
+		    if (a.slice && a.map) {
+		        return (a as any[]).map(elem => this.convertValues(elem, classs));
+		    } else if ("object" === typeof a) {
+		        if (asMap) {
+		            for (const key of Object.keys(a)) {
+		                a[key] = new classs(a[key]);
+		            }
+		            return a;
+		        }
+		        return new classs(a);
+		    }
+		    return a;
+		}
+	}
+	export class DirListing {
+	    nodes: FileNode[];
+	    error?: string;
+	
+	    static createFrom(source: any = {}) {
+	        return new DirListing(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.nodes = this.convertValues(source["nodes"], FileNode);
+	        this.error = source["error"];
+	    }
+	
+		convertValues(a: any, classs: any, asMap: boolean = false): any {
+		    if (!a) {
+		        return a;
+		    }
+		    if (a.slice && a.map) {
+		        return (a as any[]).map(elem => this.convertValues(elem, classs));
+		    } else if ("object" === typeof a) {
+		        if (asMap) {
+		            for (const key of Object.keys(a)) {
+		                a[key] = new classs(a[key]);
+		            }
+		            return a;
+		        }
+		        return new classs(a);
+		    }
+		    return a;
+		}
+	}
+	export class DirListings {
+	    dirs: Record<string, DirListing>;
+	
+	    static createFrom(source: any = {}) {
+	        return new DirListings(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.dirs = this.convertValues(source["dirs"], DirListing, true);
+	    }
+	
+		convertValues(a: any, classs: any, asMap: boolean = false): any {
+		    if (!a) {
+		        return a;
+		    }
+		    if (a.slice && a.map) {
+		        return (a as any[]).map(elem => this.convertValues(elem, classs));
+		    } else if ("object" === typeof a) {
+		        if (asMap) {
+		            for (const key of Object.keys(a)) {
+		                a[key] = new classs(a[key]);
+		            }
+		            return a;
+		        }
+		        return new classs(a);
+		    }
+		    return a;
+		}
+	}
+	export class DocLink {
+	    source: string;
+	    target: string;
+	    anchor?: string;
+	    line: number;
+	
+	    static createFrom(source: any = {}) {
+	        return new DocLink(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.source = source["source"];
+	        this.target = source["target"];
+	        this.anchor = source["anchor"];
+	        this.line = source["line"];
+	    }
+	}
+	export class DocLinkReport {
+	    files: string[];
+	    links: DocLink[];
+	    broken: BrokenLink[];
+	
+	    static createFrom(source: any = {}) {
+	        return new DocLinkReport(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.files = source["files"];
+	        this.links = this.convertValues(source["links"], DocLink);
+	        this.broken = this.convertValues(source["broken"], BrokenLink);
+	    }
+	
+		convertValues(a: any, classs: any, asMap: boolean = false): any {
+		    if (!a) {
+		        return a;
+		    }
+		    if (a.slice && a.map) {
+		        return (a as any[]).map(elem => this.convertValues(elem, classs));
+		    } else if ("object" === typeof a) {
+		        if (asMap) {
+		            for (const key of Object.keys(a)) {
+		                a[key] = new classs(a[key]);
+		            }
+		            return a;
+		        }
+		        return new classs(a);
+		    }
+		    return a;
+		}
+	}
+	export class NpmPackage {
+	    name: string;
+	    version: string;
+	    path: string;
+	    size: number;
+	    dependencyPath: string[];
+	
+	    static createFrom(source: any = {}) {
+	        return new NpmPackage(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.name = source["name"];
+	        this.version = source["version"];
+	        this.path = source["path"];
+	        this.size = source["size"];
+	        this.dependencyPath = source["dependencyPath"];
+	    }
+	}
+	export class DuplicatePackage {
+	    name: string;
+	    versions: string[];
+	    size: number;
+	    wastedSize: number;
+	    copies: NpmPackage[];
+	
+	    static createFrom(source: any = {}) {
+	        return new DuplicatePackage(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.name = source["name"];
+	        this.versions = source["versions"];
+	        this.size = source["size"];
+	        this.wastedSize = source["wastedSize"];
+	        this.copies = this.convertValues(source["copies"], NpmPackage);
+	    }
+	
+		convertValues(a: any, classs: any, asMap: boolean = false): any {
+		    if (!a) {
+		        return a;
+		    }
+		    if (a.slice && a.map) {
+		        return (a as any[]).map(elem => this.convertValues(elem, classs));
+		    } else if ("object" === typeof a) {
+		        if (asMap) {
+		            for (const key of Object.keys(a)) {
+		                a[key] = new classs(a[key]);
+		            }
+		            return a;
+		        }
+		        return new classs(a);
+		    }
+		    return a;
+		}
+	}
+	export class ExportRequest {
+	    root: string;
+	    dest: string;
+	
+	    static createFrom(source: any = {}) {
+	        return new ExportRequest(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.root = source["root"];
+	        this.dest = source["dest"];
+	    }
+	}
+	export class ExportResult {
+	    path: string;
+	    entries: number;
+	    errors: number;
+	
+	    static createFrom(source: any = {}) {
+	        return new ExportResult(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.path = source["path"];
+	        this.entries = source["entries"];
+	        this.errors = source["errors"];
+	    }
+	}
+	export class FileDiff {
+	    pathA: string;
+	    pathB: string;
+	    sizeA: number;
+	    sizeB: number;
+	    identical: boolean;
+	    binary: boolean;
+	    reason?: string;
+	    unified?: string;
+	    hunks?: DiffHunk[];
+	    added: number;
+	    removed: number;
+	    ranges?: ByteRange[];
+	    diffBytes: number;
+	    truncated: boolean;
+	
+	    static createFrom(source: any = {}) {
+	        return new FileDiff(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.pathA = source["pathA"];
+	        this.pathB = source["pathB"];
+	        this.sizeA = source["sizeA"];
+	        this.sizeB = source["sizeB"];
+	        this.identical = source["identical"];
+	        this.binary = source["binary"];
+	        this.reason = source["reason"];
+	        this.unified = source["unified"];
+	        this.hunks = this.convertValues(source["hunks"], DiffHunk);
+	        this.added = source["added"];
+	        this.removed = source["removed"];
+	        this.ranges = this.convertValues(source["ranges"], ByteRange);
+	        this.diffBytes = source["diffBytes"];
+	        this.truncated = source["truncated"];
+	    }
+	
+		convertValues(a: any, classs: any, asMap: boolean = false): any {
+		    if (!a) {
+		        return a;
+		    }
+		    if (a.slice && a.map) {
+		        return (a as any[]).map(elem => this.convertValues(elem, classs));
+		    } else if ("object" === typeof a) {
+		        if (asMap) {
+		            for (const key of Object.keys(a)) {
+		                a[key] = new classs(a[key]);
+		            }
+		            return a;
+		        }
+		        return new classs(a);
+		    }
+		    return a;
+		}
+	}
+	
+	export class FuzzyFindRequest {
+	    root: string;
+	    query: string;
+	    limit: number;
+	
+	    static createFrom(source: any = {}) {
+	        return new FuzzyFindRequest(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.root = source["root"];
+	        this.query = source["query"];
+	        this.limit = source["limit"];
+	    }
+	}
+	export class FuzzyMatch {
+	    path: string;
+	    rel: string;
+	    score: number;
+	    positions: number[];
+	
+	    static createFrom(source: any = {}) {
+	        return new FuzzyMatch(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.path = source["path"];
+	        this.rel = source["rel"];
+	        this.score = source["score"];
+	        this.positions = source["positions"];
+	    }
+	}
+	export class FuzzyMatches {
+	    matches: FuzzyMatch[];
+	
+	    static createFrom(source: any = {}) {
+	        return new FuzzyMatches(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.matches = this.convertValues(source["matches"], FuzzyMatch);
+	    }
+	
+		convertValues(a: any, classs: any, asMap: boolean = false): any {
+		    if (!a) {
+		        return a;
+		    }
+		    if (a.slice && a.map) {
+		        return (a as any[]).map(elem => this.convertValues(elem, classs));
+		    } else if ("object" === typeof a) {
+		        if (asMap) {
+		            for (const key of Object.keys(a)) {
+		                a[key] = new classs(a[key]);
+		            }
+		            return a;
+		        }
+		        return new classs(a);
+		    }
+		    return a;
+		}
+	}
+	export class HistoryDiff {
+	    diff: string;
+	
+	    static createFrom(source: any = {}) {
+	        return new HistoryDiff(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.diff = source["diff"];
+	    }
+	}
+	export class HistoryFileRequest {
+	    root: string;
+	    path: string;
+	
+	    static createFrom(source: any = {}) {
+	        return new HistoryFileRequest(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.root = source["root"];
+	        this.path = source["path"];
+	    }
+	}
+	export class HistoryLimits {
+	    maxFileSize: number;
+	    maxVersions: number;
+	    maxAgeDays: number;
+	    maxTotalBytes: number;
+	
+	    static createFrom(source: any = {}) {
+	        return new HistoryLimits(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.maxFileSize = source["maxFileSize"];
+	        this.maxVersions = source["maxVersions"];
+	        this.maxAgeDays = source["maxAgeDays"];
+	        this.maxTotalBytes = source["maxTotalBytes"];
+	    }
+	}
+	export class HistoryVersion {
+	    id: string;
+	    time: string;
+	    size: number;
+	    hash: string;
+	
+	    static createFrom(source: any = {}) {
+	        return new HistoryVersion(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.id = source["id"];
+	        this.time = source["time"];
+	        this.size = source["size"];
+	        this.hash = source["hash"];
+	    }
+	}
+	export class HistoryVersions {
+	    versions: HistoryVersion[];
+	
+	    static createFrom(source: any = {}) {
+	        return new HistoryVersions(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.versions = this.convertValues(source["versions"], HistoryVersion);
+	    }
+	
+		convertValues(a: any, classs: any, asMap: boolean = false): any {
+		    if (!a) {
+		        return a;
+		    }
+		    if (a.slice && a.map) {
+		        return (a as any[]).map(elem => this.convertValues(elem, classs));
+		    } else if ("object" === typeof a) {
+		        if (asMap) {
+		            for (const key of Object.keys(a)) {
+		                a[key] = new classs(a[key]);
+		            }
+		            return a;
+		        }
+		        return new classs(a);
+		    }
+		    return a;
+		}
+	}
+	export class IndexPathsResult {
+	    count: number;
+	
+	    static createFrom(source: any = {}) {
+	        return new IndexPathsResult(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.count = source["count"];
+	    }
+	}
+	export class ModCacheReport {
+	    root: string;
+	    size: number;
+	    unreferencedSize: number;
+	    modules: CachedModule[];
+	
+	    static createFrom(source: any = {}) {
+	        return new ModCacheReport(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.root = source["root"];
+	        this.size = source["size"];
+	        this.unreferencedSize = source["unreferencedSize"];
+	        this.modules = this.convertValues(source["modules"], CachedModule);
+	    }
+	
+		convertValues(a: any, classs: any, asMap: boolean = false): any {
+		    if (!a) {
+		        return a;
+		    }
+		    if (a.slice && a.map) {
+		        return (a as any[]).map(elem => this.convertValues(elem, classs));
+		    } else if ("object" === typeof a) {
+		        if (asMap) {
+		            for (const key of Object.keys(a)) {
+		                a[key] = new classs(a[key]);
+		            }
+		            return a;
+		        }
+		        return new classs(a);
+		    }
+		    return a;
+		}
+	}
+	export class ModCacheRequest {
+	    projectRoots: string[];
+	
+	    static createFrom(source: any = {}) {
+	        return new ModCacheRequest(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.projectRoots = source["projectRoots"];
+	    }
+	}
+	
+	export class NodeList {
+	    nodes: FileNode[];
+	
+	    static createFrom(source: any = {}) {
+	        return new NodeList(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.nodes = this.convertValues(source["nodes"], FileNode);
+	    }
+	
+		convertValues(a: any, classs: any, asMap: boolean = false): any {
+		    if (!a) {
+		        return a;
+		    }
+		    if (a.slice && a.map) {
+		        return (a as any[]).map(elem => this.convertValues(elem, classs));
+		    } else if ("object" === typeof a) {
+		        if (asMap) {
+		            for (const key of Object.keys(a)) {
+		                a[key] = new classs(a[key]);
+		            }
+		            return a;
+		        }
+		        return new classs(a);
+		    }
+		    return a;
+		}
+	}
+	export class NodeModulesReport {
+	    root: string;
+	    packages: number;
+	    size: number;
+	    duplicates: DuplicatePackage[];
+	
+	    static createFrom(source: any = {}) {
+	        return new NodeModulesReport(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.root = source["root"];
+	        this.packages = source["packages"];
+	        this.size = source["size"];
+	        this.duplicates = this.convertValues(source["duplicates"], DuplicatePackage);
+	    }
+	
+		convertValues(a: any, classs: any, asMap: boolean = false): any {
+		    if (!a) {
+		        return a;
+		    }
+		    if (a.slice && a.map) {
+		        return (a as any[]).map(elem => this.convertValues(elem, classs));
+		    } else if ("object" === typeof a) {
+		        if (asMap) {
+		            for (const key of Object.keys(a)) {
+		                a[key] = new classs(a[key]);
+		            }
+		            return a;
+		        }
+		        return new classs(a);
+		    }
+		    return a;
+		}
+	}
+	export class NormalizeRequest {
+	    root: string;
+	    paths: string[];
+	    encoding: string;
+	    lineEnding: string;
+	    trimTrailing: boolean;
+	    ensureFinalNewline: boolean;
+	    dryRun: boolean;
+	
+	    static createFrom(source: any = {}) {
+	        return new NormalizeRequest(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.root = source["root"];
+	        this.paths = source["paths"];
+	        this.encoding = source["encoding"];
+	        this.lineEnding = source["lineEnding"];
+	        this.trimTrailing = source["trimTrailing"];
+	        this.ensureFinalNewline = source["ensureFinalNewline"];
+	        this.dryRun = source["dryRun"];
+	    }
+	}
+	export class NormalizeResult {
+	    changed: string[];
+	    errors: string[];
+	
+	    static createFrom(source: any = {}) {
+	        return new NormalizeResult(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.changed = source["changed"];
+	        this.errors = source["errors"];
+	    }
+	}
+	
+	export class PathCommand {
+	    name: string;
+	    path: string;
+	    dir: string;
+	    target?: string;
+	    shadowedBy?: string;
+	    sameFile?: boolean;
+	    problem?: string;
+	
+	    static createFrom(source: any = {}) {
+	        return new PathCommand(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.name = source["name"];
+	        this.path = source["path"];
+	        this.dir = source["dir"];
+	        this.target = source["target"];
+	        this.shadowedBy = source["shadowedBy"];
+	        this.sameFile = source["sameFile"];
+	        this.problem = source["problem"];
+	    }
+	}
+	export class PathCompletion {
+	    name: string;
+	    path: string;
+	    isDir: boolean;
+	
+	    static createFrom(source: any = {}) {
+	        return new PathCompletion(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.name = source["name"];
+	        this.path = source["path"];
+	        this.isDir = source["isDir"];
+	    }
+	}
+	export class PathCompletions {
+	    completions: PathCompletion[];
+	
+	    static createFrom(source: any = {}) {
+	        return new PathCompletions(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.completions = this.convertValues(source["completions"], PathCompletion);
+	    }
+	
+		convertValues(a: any, classs: any, asMap: boolean = false): any {
+		    if (!a) {
+		        return a;
+		    }
+		    if (a.slice && a.map) {
+		        return (a as any[]).map(elem => this.convertValues(elem, classs));
+		    } else if ("object" === typeof a) {
+		        if (asMap) {
+		            for (const key of Object.keys(a)) {
+		                a[key] = new classs(a[key]);
+		            }
+		            return a;
+		        }
+		        return new classs(a);
+		    }
+		    return a;
+		}
+	}
+	export class PathDir {
+	    path: string;
+	    index: number;
+	    exists: boolean;
+	    duplicate: boolean;
+	    empty: boolean;
+	    commands: number;
+	
+	    static createFrom(source: any = {}) {
+	        return new PathDir(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.path = source["path"];
+	        this.index = source["index"];
+	        this.exists = source["exists"];
+	        this.duplicate = source["duplicate"];
+	        this.empty = source["empty"];
+	        this.commands = source["commands"];
+	    }
+	}
+	export class PathReport {
+	    dirs: PathDir[];
+	    commands: PathCommand[];
+	    shadowed: PathCommand[];
+	    problems: PathCommand[];
+	
+	    static createFrom(source: any = {}) {
+	        return new PathReport(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.dirs = this.convertValues(source["dirs"], PathDir);
+	        this.commands = this.convertValues(source["commands"], PathCommand);
+	        this.shadowed = this.convertValues(source["shadowed"], PathCommand);
+	        this.problems = this.convertValues(source["problems"], PathCommand);
+	    }
+	
+		convertValues(a: any, classs: any, asMap: boolean = false): any {
+		    if (!a) {
+		        return a;
+		    }
+		    if (a.slice && a.map) {
+		        return (a as any[]).map(elem => this.convertValues(elem, classs));
+		    } else if ("object" === typeof a) {
+		        if (asMap) {
+		            for (const key of Object.keys(a)) {
+		                a[key] = new classs(a[key]);
+		            }
+		            return a;
+		        }
+		        return new classs(a);
+		    }
+		    return a;
+		}
+	}
+	export class PathRequest {
+	    path: string;
+	
+	    static createFrom(source: any = {}) {
+	        return new PathRequest(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.path = source["path"];
+	    }
+	}
+	
+	export class ReadDirsRequest {
+	    paths: string[];
+	    depth: number;
+	
+	    static createFrom(source: any = {}) {
+	        return new ReadDirsRequest(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.paths = source["paths"];
+	        this.depth = source["depth"];
+	    }
+	}
+	export class RecentFile {
+	    name: string;
+	    path: string;
+	    size: number;
+	    modified: string;
+	    created: string;
+	    event?: string;
+	
+	    static createFrom(source: any = {}) {
+	        return new RecentFile(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.name = source["name"];
+	        this.path = source["path"];
+	        this.size = source["size"];
+	        this.modified = source["modified"];
+	        this.created = source["created"];
+	        this.event = source["event"];
+	    }
+	}
+	export class RecentPage {
+	    files: RecentFile[];
+	    total: number;
+	
+	    static createFrom(source: any = {}) {
+	        return new RecentPage(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.files = this.convertValues(source["files"], RecentFile);
+	        this.total = source["total"];
+	    }
+	
+		convertValues(a: any, classs: any, asMap: boolean = false): any {
+		    if (!a) {
+		        return a;
+		    }
+		    if (a.slice && a.map) {
+		        return (a as any[]).map(elem => this.convertValues(elem, classs));
+		    } else if ("object" === typeof a) {
+		        if (asMap) {
+		            for (const key of Object.keys(a)) {
+		                a[key] = new classs(a[key]);
+		            }
+		            return a;
+		        }
+		        return new classs(a);
+		    }
+		    return a;
+		}
+	}
+	export class RecentQuery {
+	    root: string;
+	    sortBy: string;
+	    extensions: string[];
+	    offset: number;
+	    limit: number;
+	    live: boolean;
+	
+	    static createFrom(source: any = {}) {
+	        return new RecentQuery(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.root = source["root"];
+	        this.sortBy = source["sortBy"];
+	        this.extensions = source["extensions"];
+	        this.offset = source["offset"];
+	        this.limit = source["limit"];
+	        this.live = source["live"];
+	    }
+	}
+	export class ReplaceFilePreview {
+	    path: string;
+	    encoding: string;
+	    count: number;
+	    diff: string;
+	    summary?: string;
+	    hash: string;
+	
+	    static createFrom(source: any = {}) {
+	        return new ReplaceFilePreview(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.path = source["path"];
+	        this.encoding = source["encoding"];
+	        this.count = source["count"];
+	        this.diff = source["diff"];
+	        this.summary = source["summary"];
+	        this.hash = source["hash"];
+	    }
+	}
+	export class ReplaceJournalFile {
+	    path: string;
+	    backup: string;
+	    oldHash: string;
+	    newHash: string;
+	    restored: boolean;
+	
+	    static createFrom(source: any = {}) {
+	        return new ReplaceJournalFile(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.path = source["path"];
+	        this.backup = source["backup"];
+	        this.oldHash = source["oldHash"];
+	        this.newHash = source["newHash"];
+	        this.restored = source["restored"];
+	    }
+	}
+	export class ReplaceJournal {
+	    id: string;
+	    time: string;
+	    root: string;
+	    pattern: string;
+	    replacement: string;
+	    files: ReplaceJournalFile[];
+	    undone: boolean;
+	
+	    static createFrom(source: any = {}) {
+	        return new ReplaceJournal(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.id = source["id"];
+	        this.time = source["time"];
+	        this.root = source["root"];
+	        this.pattern = source["pattern"];
+	        this.replacement = source["replacement"];
+	        this.files = this.convertValues(source["files"], ReplaceJournalFile);
+	        this.undone = source["undone"];
+	    }
+	
+		convertValues(a: any, classs: any, asMap: boolean = false): any {
+		    if (!a) {
+		        return a;
+		    }
+		    if (a.slice && a.map) {
+		        return (a as any[]).map(elem => this.convertValues(elem, classs));
+		    } else if ("object" === typeof a) {
+		        if (asMap) {
+		            for (const key of Object.keys(a)) {
+		                a[key] = new classs(a[key]);
+		            }
+		            return a;
+		        }
+		        return new classs(a);
+		    }
+		    return a;
+		}
+	}
+	
+	export class ReplaceJournals {
+	    journals: ReplaceJournal[];
+	
+	    static createFrom(source: any = {}) {
+	        return new ReplaceJournals(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.journals = this.convertValues(source["journals"], ReplaceJournal);
+	    }
+	
+		convertValues(a: any, classs: any, asMap: boolean = false): any {
+		    if (!a) {
+		        return a;
+		    }
+		    if (a.slice && a.map) {
+		        return (a as any[]).map(elem => this.convertValues(elem, classs));
+		    } else if ("object" === typeof a) {
+		        if (asMap) {
+		            for (const key of Object.keys(a)) {
+		                a[key] = new classs(a[key]);
+		            }
+		            return a;
+		        }
+		        return new classs(a);
+		    }
+		    return a;
+		}
+	}
+	export class ReplacePreview {
+	    files: ReplaceFilePreview[];
+	    count: number;
+	
+	    static createFrom(source: any = {}) {
+	        return new ReplacePreview(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.files = this.convertValues(source["files"], ReplaceFilePreview);
+	        this.count = source["count"];
+	    }
+	
+		convertValues(a: any, classs: any, asMap: boolean = false): any {
+		    if (!a) {
+		        return a;
+		    }
+		    if (a.slice && a.map) {
+		        return (a as any[]).map(elem => this.convertValues(elem, classs));
+		    } else if ("object" === typeof a) {
+		        if (asMap) {
+		            for (const key of Object.keys(a)) {
+		                a[key] = new classs(a[key]);
+		            }
+		            return a;
+		        }
+		        return new classs(a);
+		    }
+		    return a;
+		}
+	}
+	
+	export class ReplaceResult {
+	    journalId: string;
+	    applied: string[];
+	    conflicts: string[];
+	
+	    static createFrom(source: any = {}) {
+	        return new ReplaceResult(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.journalId = source["journalId"];
+	        this.applied = source["applied"];
+	        this.conflicts = source["conflicts"];
+	    }
+	}
+	
+	export class RerootRequest {
+	    path: string;
+	    parent: boolean;
+	    depth: number;
+	
+	    static createFrom(source: any = {}) {
+	        return new RerootRequest(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.path = source["path"];
+	        this.parent = source["parent"];
+	        this.depth = source["depth"];
+	    }
+	}
+	export class RestoreHistoryRequest {
+	    root: string;
+	    path: string;
+	    id: string;
+	
+	    static createFrom(source: any = {}) {
+	        return new RestoreHistoryRequest(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.root = source["root"];
+	        this.path = source["path"];
+	        this.id = source["id"];
+	    }
+	}
+	export class RootRequest {
+	    root: string;
+	
+	    static createFrom(source: any = {}) {
+	        return new RootRequest(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.root = source["root"];
+	    }
+	}
+	export class RuleAction {
+	    type: string;
+	    dest: string;
+	    message: string;
+	
+	    static createFrom(source: any = {}) {
+	        return new RuleAction(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.type = source["type"];
+	        this.dest = source["dest"];
+	        this.message = source["message"];
+	    }
+	}
+	export class RuleCondition {
+	    field: string;
+	    op: string;
+	    value: string;
+	
+	    static createFrom(source: any = {}) {
+	        return new RuleCondition(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.field = source["field"];
+	        this.op = source["op"];
+	        this.value = source["value"];
+	    }
+	}
+	export class Rule {
+	    name: string;
+	    root: string;
+	    events: string[];
+	    conditions: RuleCondition[];
+	    action: RuleAction;
+	
+	    static createFrom(source: any = {}) {
+	        return new Rule(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.name = source["name"];
+	        this.root = source["root"];
+	        this.events = source["events"];
+	        this.conditions = this.convertValues(source["conditions"], RuleCondition);
+	        this.action = this.convertValues(source["action"], RuleAction);
+	    }
+	
+		convertValues(a: any, classs: any, asMap: boolean = false): any {
+		    if (!a) {
+		        return a;
+		    }
+		    if (a.slice && a.map) {
+		        return (a as any[]).map(elem => this.convertValues(elem, classs));
+		    } else if ("object" === typeof a) {
+		        if (asMap) {
+		            for (const key of Object.keys(a)) {
+		                a[key] = new classs(a[key]);
+		            }
+		            return a;
+		        }
+		        return new classs(a);
+		    }
+		    return a;
+		}
+	}
+	
+	
+	export class RuleLogEntry {
+	    time: string;
+	    rule: string;
+	    event: string;
+	    path: string;
+	    action: string;
+	    dest?: string;
+	    dryRun: boolean;
+	    message?: string;
+	    error?: string;
+	
+	    static createFrom(source: any = {}) {
+	        return new RuleLogEntry(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.time = source["time"];
+	        this.rule = source["rule"];
+	        this.event = source["event"];
+	        this.path = source["path"];
+	        this.action = source["action"];
+	        this.dest = source["dest"];
+	        this.dryRun = source["dryRun"];
+	        this.message = source["message"];
+	        this.error = source["error"];
+	    }
+	}
+	export class RuleLogEntries {
+	    entries: RuleLogEntry[];
+	
+	    static createFrom(source: any = {}) {
+	        return new RuleLogEntries(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.entries = this.convertValues(source["entries"], RuleLogEntry);
+	    }
+	
+		convertValues(a: any, classs: any, asMap: boolean = false): any {
+		    if (!a) {
+		        return a;
+		    }
+		    if (a.slice && a.map) {
+		        return (a as any[]).map(elem => this.convertValues(elem, classs));
+		    } else if ("object" === typeof a) {
+		        if (asMap) {
+		            for (const key of Object.keys(a)) {
+		                a[key] = new classs(a[key]);
+		            }
+		            return a;
+		        }
+		        return new classs(a);
+		    }
+		    return a;
+		}
+	}
+	
+	export class SBOMDocument {
+	    document: string;
+	
+	    static createFrom(source: any = {}) {
+	        return new SBOMDocument(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.document = source["document"];
+	    }
+	}
+	export class SBOMRequest {
+	    root: string;
+	    format: string;
+	    dest: string;
+	
+	    static createFrom(source: any = {}) {
+	        return new SBOMRequest(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.root = source["root"];
+	        this.format = source["format"];
+	        this.dest = source["dest"];
+	    }
+	}
+	export class ScanEstimate {
+	    root: string;
+	    mode: string;
+	    samples: number;
+	    dirsVisited: number;
+	    files: number;
+	    filesLow: number;
+	    filesHigh: number;
+	    dirs: number;
+	    dirsLow: number;
+	    dirsHigh: number;
+	    bytes: number;
+	    bytesLow: number;
+	    bytesHigh: number;
+	    done: boolean;
+	
+	    static createFrom(source: any = {}) {
+	        return new ScanEstimate(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.root = source["root"];
+	        this.mode = source["mode"];
+	        this.samples = source["samples"];
+	        this.dirsVisited = source["dirsVisited"];
+	        this.files = source["files"];
+	        this.filesLow = source["filesLow"];
+	        this.filesHigh = source["filesHigh"];
+	        this.dirs = source["dirs"];
+	        this.dirsLow = source["dirsLow"];
+	        this.dirsHigh = source["dirsHigh"];
+	        this.bytes = source["bytes"];
+	        this.bytesLow = source["bytesLow"];
+	        this.bytesHigh = source["bytesHigh"];
+	        this.done = source["done"];
+	    }
+	}
+	export class ScanOptions {
+	    root: string;
+	    mode: string;
+	    precision: number;
+	    maxSamples: number;
+	
+	    static createFrom(source: any = {}) {
+	        return new ScanOptions(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.root = source["root"];
+	        this.mode = source["mode"];
+	        this.precision = source["precision"];
+	        this.maxSamples = source["maxSamples"];
+	    }
+	}
+	export class SearchContentRequest {
+	    root: string;
+	    pattern: string;
+	    limit: number;
+	
+	    static createFrom(source: any = {}) {
+	        return new SearchContentRequest(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.root = source["root"];
+	        this.pattern = source["pattern"];
+	        this.limit = source["limit"];
+	    }
+	}
+	export class SetRulesRequest {
+	    rules: Rule[];
+	    dryRun: boolean;
+	
+	    static createFrom(source: any = {}) {
+	        return new SetRulesRequest(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.rules = this.convertValues(source["rules"], Rule);
+	        this.dryRun = source["dryRun"];
+	    }
+	
+		convertValues(a: any, classs: any, asMap: boolean = false): any {
+		    if (!a) {
+		        return a;
+		    }
+		    if (a.slice && a.map) {
+		        return (a as any[]).map(elem => this.convertValues(elem, classs));
+		    } else if ("object" === typeof a) {
+		        if (asMap) {
+		            for (const key of Object.keys(a)) {
+		                a[key] = new classs(a[key]);
+		            }
+		            return a;
+		        }
+		        return new classs(a);
+		    }
+		    return a;
+		}
+	}
+	export class SpliceRootRequest {
+	    subtree: FileNode;
+	    root: string;
+	
+	    static createFrom(source: any = {}) {
+	        return new SpliceRootRequest(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.subtree = this.convertValues(source["subtree"], FileNode);
+	        this.root = source["root"];
+	    }
+	
+		convertValues(a: any, classs: any, asMap: boolean = false): any {
+		    if (!a) {
+		        return a;
+		    }
+		    if (a.slice && a.map) {
+		        return (a as any[]).map(elem => this.convertValues(elem, classs));
+		    } else if ("object" === typeof a) {
+		        if (asMap) {
+		            for (const key of Object.keys(a)) {
+		                a[key] = new classs(a[key]);
+		            }
+		            return a;
+		        }
+		        return new classs(a);
+		    }
+		    return a;
+		}
+	}
+	export class StopTailRequest {
+	    id: string;
+	
+	    static createFrom(source: any = {}) {
+	        return new StopTailRequest(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.id = source["id"];
+	    }
+	}
+	export class TailRequest {
+	    path: string;
+	    lines: number;
+	    filter: string;
+	    highlight: string;
+	
+	    static createFrom(source: any = {}) {
+	        return new TailRequest(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.path = source["path"];
+	        this.lines = source["lines"];
+	        this.filter = source["filter"];
+	        this.highlight = source["highlight"];
+	    }
+	}
+	export class TailStarted {
+	    id: string;
+	
+	    static createFrom(source: any = {}) {
+	        return new TailStarted(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.id = source["id"];
+	    }
+	}
+	export class TextFileReport {
+	    path: string;
+	    encoding: string;
+	    lineEnding: string;
+	    lf: number;
+	    crlf: number;
+	    cr: number;
+	    trailingWhitespace: number;
+	    finalNewline: boolean;
+	
+	    static createFrom(source: any = {}) {
+	        return new TextFileReport(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.path = source["path"];
+	        this.encoding = source["encoding"];
+	        this.lineEnding = source["lineEnding"];
+	        this.lf = source["lf"];
+	        this.crlf = source["crlf"];
+	        this.cr = source["cr"];
+	        this.trailingWhitespace = source["trailingWhitespace"];
+	        this.finalNewline = source["finalNewline"];
+	    }
+	}
+	export class TextReport {
+	    files: TextFileReport[];
+	    binary: number;
+	
+	    static createFrom(source: any = {}) {
+	        return new TextReport(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.files = this.convertValues(source["files"], TextFileReport);
+	        this.binary = source["binary"];
+	    }
+	
+		convertValues(a: any, classs: any, asMap: boolean = false): any {
+		    if (!a) {
+		        return a;
+		    }
+		    if (a.slice && a.map) {
+		        return (a as any[]).map(elem => this.convertValues(elem, classs));
+		    } else if ("object" === typeof a) {
+		        if (asMap) {
+		            for (const key of Object.keys(a)) {
+		                a[key] = new classs(a[key]);
+		            }
+		            return a;
+		        }
+		        return new classs(a);
+		    }
+		    return a;
+		}
+	}
+	export class UndoReplaceRequest {
+	    id: string;
+	
+	    static createFrom(source: any = {}) {
+	        return new UndoReplaceRequest(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.id = source["id"];
+	    }
+	}
+	export class VulnReport {
+	    root: string;
+	    dependencies: number;
+	    advisories: Advisory[];
+	    nodes: Record<string, Array<string>>;
+	
+	    static createFrom(source: any = {}) {
+	        return new VulnReport(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.root = source["root"];
+	        this.dependencies = source["dependencies"];
+	        this.advisories = this.convertValues(source["advisories"], Advisory);
+	        this.nodes = source["nodes"];
+	    }
+	
+		convertValues(a: any, classs: any, asMap: boolean = false): any {
+		    if (!a) {
+		        return a;
+		    }
+		    if (a.slice && a.map) {
+		        return (a as any[]).map(elem => this.convertValues(elem, classs));
+		    } else if ("object" === typeof a) {
+		        if (asMap) {
+		            for (const key of Object.keys(a)) {
+		                a[key] = new classs(a[key]);
+		            }
+		            return a;
+		        }
+		        return new classs(a);
+		    }
+		    return a;
+		}
+	}
+	export class WatchAnomaliesRequest {
+	    root: string;
+	    options: AnomalyOptions;
+	
+	    static createFrom(source: any = {}) {
+	        return new WatchAnomaliesRequest(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.root = source["root"];
+	        this.options = this.convertValues(source["options"], AnomalyOptions);
+	    }
+	
+		convertValues(a: any, classs: any, asMap: boolean = false): any {
+		    if (!a) {
+		        return a;
+		    }
+		    if (a.slice && a.map) {
+		        return (a as any[]).map(elem => this.convertValues(elem, classs));
+		    } else if ("object" === typeof a) {
+		        if (asMap) {
+		            for (const key of Object.keys(a)) {
+		                a[key] = new classs(a[key]);
+		            }
+		            return a;
+		        }
+		        return new classs(a);
+		    }
+		    return a;
+		}
+	}
+	export class WatchHistoryRequest {
+	    root: string;
+	    limits: HistoryLimits;
+	
+	    static createFrom(source: any = {}) {
+	        return new WatchHistoryRequest(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.root = source["root"];
+	        this.limits = this.convertValues(source["limits"], HistoryLimits);
 	    }
 	
 		convertValues(a: any, classs: any, asMap: boolean = false): any {
@@ -0,0 +1,265 @@
+package main
+
+import (
+	"bytes"
+	"fmt"
+	"go/ast"
+	"go/build"
+	"go/parser"
+	"go/printer"
+	"go/token"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+)
+
+// ReadGoOutline expands a .go file into its top-level declarations, with
+// methods nested under their receiver type. For a directory it returns one
+// node per Go package holding a summary of the package's exported API,
+// built from the files that match the current build context.
+func (a *App) ReadGoOutline(req PathRequest) (NodeList, error) {
+	path := req.Path
+	info, err := os.Stat(path)
+	if err != nil {
+		return NodeList{}, err
+	}
+	if info.IsDir() {
+		nodes, err := goPackageSummary(path)
+		return NodeList{Nodes: nodes}, err
+	}
+	if filepath.Ext(path) != ".go" {
+		return NodeList{}, fmt.Errorf("%s: not a Go source file", path)
+	}
+
+	fset := token.NewFileSet()
+	file, err := parser.ParseFile(fset, path, nil, parser.ParseComments)
+	if err != nil {
+		return NodeList{}, err
+	}
+	return NodeList{Nodes: goFileDecls(fset, path, file, false)}, nil
+}
+
+func goFileDecls(fset *token.FileSet, path string, file *ast.File, exportedOnly bool) []FileNode {
+	var nodes []FileNode
+	types := make(map[string]int)
+	var methods []FileNode
+
+	for _, decl := range file.Decls {
+		switch d := decl.(type) {
+		case *ast.FuncDecl:
+			if exportedOnly && !d.Name.IsExported() {
+				continue
+			}
+			if d.Recv == nil || len(d.Recv.List) == 0 {
+				nodes = append(nodes, goDeclNode(fset, path, d.Name.Name, KindFunc, d, d.Doc, goFuncSignature(fset, d)))
+				continue
+			}
+			recv := goReceiverType(d.Recv.List[0].Type)
+			if exportedOnly && !ast.IsExported(recv) {
+				continue
+			}
+			node := goDeclNode(fset, path, recv+"."+d.Name.Name, KindMethod, d, d.Doc, goFuncSignature(fset, d))
+			methods = append(methods, node)
+		case *ast.GenDecl:
+			for _, spec := range d.Specs {
+				// A group's doc comment belongs to the group, so only a
+				// lone spec takes it and is measured from the keyword.
+				var doc *ast.CommentGroup
+				var start ast.Node = spec
+				if len(d.Specs) == 1 {
+					doc = d.Doc
+					start = d
+				}
+				switch s := spec.(type) {
+				case *ast.TypeSpec:
+					if exportedOnly && !s.Name.IsExported() {
+						continue
+					}
+					if s.Doc != nil {
+						doc = s.Doc
+					}
+					types[s.Name.Name] = len(nodes)
+					nodes = append(nodes, goDeclNode(fset, path, s.Name.Name, KindType, start, doc, goTypeSignature(fset, s)))
+				case *ast.ValueSpec:
+					if s.Doc != nil {
+						doc = s.Doc
+					}
+					kind := KindVar
+					if d.Tok == token.CONST {
+						kind = KindConst
+					}
+					for _, name := range s.Names {
+						if name.Name == "_" || exportedOnly && !name.IsExported() {
+							continue
+						}
+						nodes = append(nodes, goDeclNode(fset, path, name.Name, kind, start, doc, goValueSignature(fset, d.Tok, name, s)))
+					}
+				}
+			}
+		}
+	}
+
+	for _, method := range methods {
+		recv, _, _ := strings.Cut(method.Name, ".")
+		if i, ok := types[recv]; ok {
+			m := method
+			nodes[i].Children = append(nodes[i].Children, &m)
+			continue
+		}
+		nodes = append(nodes, method)
+	}
+	return nodes
+}
+
+func goDeclNode(fset *token.FileSet, path, name string, kind NodeKind, decl ast.Node, doc *ast.CommentGroup, detail string) FileNode {
+	start := fset.Position(decl.Pos())
+	if doc != nil {
+		start = fset.Position(doc.Pos())
+	}
+	end := fset.Position(decl.End())
+
+	return FileNode{
+		Name:    name,
+		Path:    fmt.Sprintf("%s#%s@L%d", path, name, start.Line),
+		Type:    NodeDecl,
+		Kind:    kind,
+		Size:    int64(end.Offset - start.Offset),
+		Line:    start.Line,
+		EndLine: end.Line,
+		Detail:  detail,
+	}
+}
+
+func goReceiverType(expr ast.Expr) string {
+	switch t := expr.(type) {
+	case *ast.StarExpr:
+		return goReceiverType(t.X)
+	case *ast.IndexExpr:
+		return goReceiverType(t.X)
+	case *ast.IndexListExpr:
+		return goReceiverType(t.X)
+	case *ast.Ident:
+		return t.Name
+	}
+	return ""
+}
+
+func goFuncSignature(fset *token.FileSet, d *ast.FuncDecl) string {
+	sig := *d
+	sig.Doc = nil
+	sig.Body = nil
+	return goPrint(fset, &sig)
+}
+
+// goTypeSignature prints a type declaration, eliding struct and interface
+// bodies so the summary stays one line per type.
+func goTypeSignature(fset *token.FileSet, s *ast.TypeSpec) string {
+	spec := *s
+	spec.Doc = nil
+	spec.Comment = nil
+	switch spec.Type.(type) {
+	case *ast.StructType:
+		spec.Type = ast.NewIdent("struct{ ... }")
+	case *ast.InterfaceType:
+		spec.Type = ast.NewIdent("interface{ ... }")
+	}
+	return "type " + goPrint(fset, &spec)
+}
+
+func goValueSignature(fset *token.FileSet, tok token.Token, name *ast.Ident, s *ast.ValueSpec) string {
+	sig := tok.String() + " " + name.Name
+	if s.Type != nil {
+		sig += " " + goPrint(fset, s.Type)
+	}
+	return sig
+}
+
+func goPrint(fset *token.FileSet, node interface{}) string {
+	var buf bytes.Buffer
+	if err := printer.Fprint(&buf, fset, node); err != nil {
+		return ""
+	}
+	return buf.String()
+}
+
+func goPackageSummary(dir string) ([]FileNode, error) {
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		return nil, err
+	}
+
+	fset := token.NewFileSet()
+	packages := make(map[string]*FileNode)
+	for _, entry := range entries {
+		name := entry.Name()
+		if entry.IsDir() || filepath.Ext(name) != ".go" || strings.HasSuffix(name, "_test.go") {
+			continue
+		}
+		if match, err := build.Default.MatchFile(dir, name); err != nil || !match {
+			continue
+		}
+
+		path := filepath.Join(dir, name)
+		file, err := parser.ParseFile(fset, path, nil, parser.ParseComments)
+		if err != nil {
+			continue
+		}
+
+		pkg, ok := packages[file.Name.Name]
+		if !ok {
+			pkg = &FileNode{
+				Name: file.Name.Name,
+				Path: dir + "#" + file.Name.Name,
+				Type: NodeDecl,
+				Kind: KindPackage,
+			}
+			packages[file.Name.Name] = pkg
+		}
+		if info, err := entry.Info(); err == nil {
+			pkg.Size += info.Size()
+		}
+		if file.Doc != nil && pkg.Detail == "" {
+			pkg.Detail = strings.TrimSpace(file.Doc.Text())
+		}
+		for _, decl := range goFileDecls(fset, path, file, true) {
+			d := decl
+			pkg.Children = append(pkg.Children, &d)
+		}
+	}
+
+	var nodes []FileNode
+	for _, pkg := range packages {
+		pkg.Children = nestGoMethods(pkg.Children)
+		sort.Slice(pkg.Children, func(i, j int) bool {
+			return pkg.Children[i].Name < pkg.Children[j].Name
+		})
+		nodes = append(nodes, *pkg)
+	}
+	sort.Slice(nodes, func(i, j int) bool { return nodes[i].Name < nodes[j].Name })
+	return nodes, nil
+}
+
+// nestGoMethods moves methods declared apart from their receiver type, such
+// as in another file of the package, under that type's node.
+func nestGoMethods(decls []*FileNode) []*FileNode {
+	types := make(map[string]*FileNode)
+	for _, decl := range decls {
+		if decl.Kind == KindType {
+			types[decl.Name] = decl
+		}
+	}
+
+	var nested []*FileNode
+	for _, decl := range decls {
+		if decl.Kind == KindMethod {
+			recv, _, _ := strings.Cut(decl.Name, ".")
+			if t, ok := types[recv]; ok {
+				t.Children = append(t.Children, decl)
+				continue
+			}
+		}
+		nested = append(nested, decl)
+	}
+	return nested
+}
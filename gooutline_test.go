@@ -0,0 +1,71 @@
+package main
+
+import (
+	"path/filepath"
+	"testing"
+)
+
+func TestReadGoOutline(t *testing.T) {
+	dir := t.TempDir()
+	writeFiles(t, dir, map[string]string{
+		"consts.go": "package demo\n\n// Letters are grouped.\nconst (\n\tA = iota\n\tB\n\t// C has its own doc.\n\tC\n)\n",
+		"init.go":   "package demo\n\nfunc init() {}\n\nfunc init() {}\n",
+		"types.go":  "package demo\n\n// T is exported.\ntype T struct{}\n\nfunc (T) Method() {}\n\nfunc helper() {}\n",
+		"gen.go":    "//go:build ignore\n\npackage main\n\nfunc Generate() {}\n",
+		"broken.go": "package demo\n\nfunc Broken( {\n",
+	})
+
+	list, err := NewApp().ReadGoOutline(PathRequest{Path: filepath.Join(dir, "consts.go")})
+	if err != nil {
+		t.Fatal(err)
+	}
+	consts := []struct {
+		name string
+		line int
+		size int64
+	}{
+		{"A", 5, 8},
+		{"B", 6, 1},
+		{"C", 7, 24},
+	}
+	nodes := list.Nodes
+	if len(nodes) != len(consts) {
+		t.Fatalf("got %d nodes, want %d", len(nodes), len(consts))
+	}
+	for i, want := range consts {
+		got := nodes[i]
+		if got.Name != want.name || got.Line != want.line || got.Size != want.size {
+			t.Errorf("node %d = %s line %d size %d, want %s line %d size %d",
+				i, got.Name, got.Line, got.Size, want.name, want.line, want.size)
+		}
+	}
+
+	list, err = NewApp().ReadGoOutline(PathRequest{Path: filepath.Join(dir, "init.go")})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if nodes = list.Nodes; len(nodes) != 2 || nodes[0].Path == nodes[1].Path {
+		t.Errorf("init funcs share a path: %+v", nodes)
+	}
+
+	list, err = NewApp().ReadGoOutline(PathRequest{Path: dir})
+	if err != nil {
+		t.Fatal(err)
+	}
+	pkgs := list.Nodes
+	if len(pkgs) != 1 || pkgs[0].Name != "demo" {
+		t.Fatalf("packages = %+v, want only demo", pkgs)
+	}
+	exported := make(map[string]int)
+	for _, decl := range pkgs[0].Children {
+		exported[decl.Name] = len(decl.Children)
+	}
+	for name, methods := range map[string]int{"A": 0, "B": 0, "C": 0, "T": 1} {
+		if got, ok := exported[name]; !ok || got != methods {
+			t.Errorf("%s: present %v with %d methods, want %d", name, ok, got, methods)
+		}
+	}
+	if _, ok := exported["helper"]; ok {
+		t.Error("unexported helper listed in package summary")
+	}
+}
@@ -0,0 +1,251 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"io/fs"
+	"net/http"
+	"os"
+	"os/signal"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+	"syscall"
+	"time"
+)
+
+// headlessConfig is read from the file given to "recursion headless
+// -config".
+type headlessConfig struct {
+	// Listen is the address serving /metrics; default ":9110".
+	Listen string `json:"listen"`
+	// Interval between rescans, as a Go duration; default "5m".
+	Interval    string   `json:"interval"`
+	Directories []string `json:"directories"`
+
+	interval time.Duration
+}
+
+// dirMetrics is the result of one scan of a configured directory.
+type dirMetrics struct {
+	Path       string
+	Bytes      int64
+	Files      int64
+	Dirs       int64
+	Errors     int64
+	Oldest     time.Time
+	FreeBytes  uint64
+	TotalBytes uint64
+	DiskErr    error
+	Duration   time.Duration
+	Scanned    time.Time
+}
+
+// metricsExporter keeps the latest scan of each directory for /metrics.
+type metricsExporter struct {
+	cfg headlessConfig
+
+	mu      sync.Mutex
+	results map[string]dirMetrics
+	scans   int64
+}
+
+// runHeadless serves directory metrics without opening a window, until
+// interrupted.
+func runHeadless(args []string) error {
+	flags := flag.NewFlagSet("headless", flag.ContinueOnError)
+	configPath := flags.String("config", "recursion.json", "path to the JSON configuration")
+	if err := flags.Parse(args); err != nil {
+		return err
+	}
+	cfg, err := loadHeadlessConfig(*configPath)
+	if err != nil {
+		return err
+	}
+
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+
+	m := newMetricsExporter(cfg)
+	go m.run(ctx)
+
+	mux := http.NewServeMux()
+	mux.Handle("/metrics", m)
+	srv := &http.Server{Addr: cfg.Listen, Handler: mux}
+	go func() {
+		<-ctx.Done()
+		shutdown, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+		defer cancel()
+		srv.Shutdown(shutdown)
+	}()
+	fmt.Fprintf(os.Stderr, "serving metrics for %d directories on %s/metrics\n", len(cfg.Directories), cfg.Listen)
+	if err := srv.ListenAndServe(); !errors.Is(err, http.ErrServerClosed) {
+		return err
+	}
+	return nil
+}
+
+func loadHeadlessConfig(path string) (headlessConfig, error) {
+	var cfg headlessConfig
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return cfg, err
+	}
+	if err := json.Unmarshal(data, &cfg); err != nil {
+		return cfg, fmt.Errorf("%s: %w", path, err)
+	}
+	if len(cfg.Directories) == 0 {
+		return cfg, fmt.Errorf("%s: no directories configured", path)
+	}
+	for i, dir := range cfg.Directories {
+		cfg.Directories[i] = filepath.Clean(expandPath(dir))
+	}
+	if cfg.Listen == "" {
+		cfg.Listen = ":9110"
+	}
+	if cfg.Interval == "" {
+		cfg.Interval = "5m"
+	}
+	if cfg.interval, err = time.ParseDuration(cfg.Interval); err != nil {
+		return cfg, fmt.Errorf("%s: interval: %w", path, err)
+	}
+	if cfg.interval <= 0 {
+		return cfg, fmt.Errorf("%s: interval must be positive", path)
+	}
+	return cfg, nil
+}
+
+func newMetricsExporter(cfg headlessConfig) *metricsExporter {
+	return &metricsExporter{cfg: cfg, results: make(map[string]dirMetrics)}
+}
+
+// run rescans every directory now and then once per interval until ctx
+// is done.
+func (m *metricsExporter) run(ctx context.Context) {
+	ticker := time.NewTicker(m.cfg.interval)
+	defer ticker.Stop()
+	for {
+		m.scanAll(ctx)
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+func (m *metricsExporter) scanAll(ctx context.Context) {
+	for _, dir := range m.cfg.Directories {
+		if ctx.Err() != nil {
+			return
+		}
+		r := scanDirMetrics(ctx, dir)
+		if ctx.Err() != nil {
+			return
+		}
+		m.mu.Lock()
+		m.results[dir] = r
+		m.scans++
+		m.mu.Unlock()
+	}
+}
+
+func scanDirMetrics(ctx context.Context, dir string) dirMetrics {
+	start := time.Now()
+	r := dirMetrics{Path: dir}
+	filepath.WalkDir(dir, func(path string, d fs.DirEntry, err error) error {
+		if ctx.Err() != nil {
+			return ctx.Err()
+		}
+		if err != nil {
+			r.Errors++
+			return nil
+		}
+		if d.IsDir() {
+			if path != dir {
+				r.Dirs++
+			}
+			return nil
+		}
+		info, err := d.Info()
+		if err != nil {
+			r.Errors++
+			return nil
+		}
+		if !info.Mode().IsRegular() {
+			return nil
+		}
+		r.Files++
+		r.Bytes += info.Size()
+		if r.Oldest.IsZero() || info.ModTime().Before(r.Oldest) {
+			r.Oldest = info.ModTime()
+		}
+		return nil
+	})
+	r.FreeBytes, r.TotalBytes, r.DiskErr = diskSpace(dir)
+	r.Scanned = time.Now()
+	r.Duration = r.Scanned.Sub(start)
+	return r
+}
+
+func (m *metricsExporter) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "text/plain; version=0.0.4; charset=utf-8")
+	m.writeMetrics(w, time.Now())
+}
+
+// writeMetrics renders the latest results in the Prometheus text
+// exposition format. Directories not scanned yet are left out.
+func (m *metricsExporter) writeMetrics(w io.Writer, now time.Time) {
+	m.mu.Lock()
+	results := make([]dirMetrics, 0, len(m.results))
+	for _, r := range m.results {
+		results = append(results, r)
+	}
+	scans := m.scans
+	m.mu.Unlock()
+	sort.Slice(results, func(i, j int) bool { return results[i].Path < results[j].Path })
+
+	type metric struct {
+		name, typ, help string
+		value           func(r dirMetrics) (float64, bool)
+	}
+	metrics := []metric{
+		{"recursion_directory_size_bytes", "gauge", "Total size of the regular files under the directory.",
+			func(r dirMetrics) (float64, bool) { return float64(r.Bytes), true }},
+		{"recursion_directory_files", "gauge", "Number of regular files under the directory.",
+			func(r dirMetrics) (float64, bool) { return float64(r.Files), true }},
+		{"recursion_directory_subdirectories", "gauge", "Number of directories under the directory.",
+			func(r dirMetrics) (float64, bool) { return float64(r.Dirs), true }},
+		{"recursion_directory_oldest_file_age_seconds", "gauge", "Age of the least recently modified file under the directory.",
+			func(r dirMetrics) (float64, bool) { return now.Sub(r.Oldest).Seconds(), !r.Oldest.IsZero() }},
+		{"recursion_directory_scan_errors", "gauge", "Entries that could not be read during the last scan.",
+			func(r dirMetrics) (float64, bool) { return float64(r.Errors), true }},
+		{"recursion_filesystem_free_bytes", "gauge", "Bytes available on the filesystem holding the directory.",
+			func(r dirMetrics) (float64, bool) { return float64(r.FreeBytes), r.DiskErr == nil }},
+		{"recursion_filesystem_size_bytes", "gauge", "Size of the filesystem holding the directory.",
+			func(r dirMetrics) (float64, bool) { return float64(r.TotalBytes), r.DiskErr == nil }},
+		{"recursion_scan_duration_seconds", "gauge", "How long the last scan of the directory took.",
+			func(r dirMetrics) (float64, bool) { return r.Duration.Seconds(), true }},
+		{"recursion_last_scan_timestamp_seconds", "gauge", "When the last scan of the directory finished, as a Unix time.",
+			func(r dirMetrics) (float64, bool) { return float64(r.Scanned.UnixMilli()) / 1000, true }},
+	}
+	for _, mt := range metrics {
+		fmt.Fprintf(w, "# HELP %s %s\n# TYPE %s %s\n", mt.name, mt.help, mt.name, mt.typ)
+		for _, r := range results {
+			if v, ok := mt.value(r); ok {
+				fmt.Fprintf(w, "%s{path=\"%s\"} %g\n", mt.name, escapeLabel(r.Path), v)
+			}
+		}
+	}
+	fmt.Fprintf(w, "# HELP recursion_scans_total Directory scans completed since start.\n# TYPE recursion_scans_total counter\nrecursion_scans_total %d\n", scans)
+}
+
+// escapeLabel escapes a label value for the text exposition format.
+func escapeLabel(s string) string {
+	return strings.NewReplacer(`\`, `\\`, `"`, `\"`, "\n", `\n`).Replace(s)
+}
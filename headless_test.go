@@ -0,0 +1,81 @@
+package main
+
+import (
+	"context"
+	"io"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestLoadHeadlessConfig(t *testing.T) {
+	dir := t.TempDir()
+	tests := []struct {
+		name     string
+		config   string
+		listen   string
+		interval time.Duration
+		wantErr  bool
+	}{
+		{"defaults", `{"directories": ["/srv"]}`, ":9110", 5 * time.Minute, false},
+		{"explicit", `{"listen": "127.0.0.1:9000", "interval": "30s", "directories": ["/srv"]}`, "127.0.0.1:9000", 30 * time.Second, false},
+		{"no directories", `{"listen": ":9000"}`, "", 0, true},
+		{"bad interval", `{"interval": "soon", "directories": ["/srv"]}`, "", 0, true},
+		{"bad json", `{`, "", 0, true},
+	}
+	for _, tt := range tests {
+		path := filepath.Join(dir, tt.name+".json")
+		os.WriteFile(path, []byte(tt.config), 0o644)
+		cfg, err := loadHeadlessConfig(path)
+		if (err != nil) != tt.wantErr {
+			t.Errorf("%s: err = %v", tt.name, err)
+			continue
+		}
+		if err == nil && (cfg.Listen != tt.listen || cfg.interval != tt.interval) {
+			t.Errorf("%s: listen %q interval %v", tt.name, cfg.Listen, cfg.interval)
+		}
+	}
+}
+
+func TestMetricsExporter(t *testing.T) {
+	root := t.TempDir()
+	writeFiles(t, root, map[string]string{
+		"a.log":     "12345",
+		"sub/b.log": "123",
+	})
+	old := time.Now().Add(-time.Hour)
+	os.Chtimes(filepath.Join(root, "a.log"), old, old)
+
+	m := newMetricsExporter(headlessConfig{Directories: []string{root, filepath.Join(root, "missing")}, interval: time.Hour})
+	m.scanAll(context.Background())
+
+	rec := httptest.NewRecorder()
+	m.ServeHTTP(rec, httptest.NewRequest("GET", "/metrics", nil))
+	body, _ := io.ReadAll(rec.Result().Body)
+	out := string(body)
+
+	tests := []string{
+		`recursion_directory_size_bytes{path="` + root + `"} 8`,
+		`recursion_directory_files{path="` + root + `"} 2`,
+		`recursion_directory_subdirectories{path="` + root + `"} 1`,
+		`recursion_directory_oldest_file_age_seconds{path="` + root + `"} 36`,
+		`recursion_directory_scan_errors{path="` + filepath.Join(root, "missing") + `"} 1`,
+		`recursion_filesystem_free_bytes{path="` + root + `"} `,
+		"# TYPE recursion_directory_size_bytes gauge",
+		"recursion_scans_total 2",
+	}
+	for _, want := range tests {
+		if !strings.Contains(out, want) {
+			t.Errorf("metrics are missing %q:\n%s", want, out)
+		}
+	}
+	if ct := rec.Result().Header.Get("Content-Type"); !strings.HasPrefix(ct, "text/plain; version=0.0.4") {
+		t.Errorf("content type = %q", ct)
+	}
+	if got := escapeLabel("a\"b\\c\nd"); got != `a\"b\\c\nd` {
+		t.Errorf("escapeLabel = %s", got)
+	}
+}
@@ -0,0 +1,472 @@
+package main
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/fsnotify/fsnotify"
+)
+
+type HistoryVersion struct {
+	ID   string    `json:"id"`
+	Time time.Time `json:"time" ts_type:"string"`
+	Size int64     `json:"size"`
+	Hash string    `json:"hash"`
+}
+
+// HistoryLimits bound the local history store. Zero fields take the
+// defaults below.
+type HistoryLimits struct {
+	// MaxFileSize skips files larger than this many bytes.
+	MaxFileSize int64 `json:"maxFileSize"`
+	// MaxVersions is kept per file, dropping the oldest first.
+	MaxVersions int `json:"maxVersions"`
+	// MaxAgeDays drops older versions, but never a file's latest.
+	MaxAgeDays int `json:"maxAgeDays"`
+	// MaxTotalBytes caps the stored contents across all files.
+	MaxTotalBytes int64 `json:"maxTotalBytes"`
+}
+
+// historyDelay is how long a file must go unwritten before its new
+// contents are recorded.
+const historyDelay = 300 * time.Millisecond
+
+const (
+	defaultHistoryFileSize  = 1 << 20
+	defaultHistoryVersions  = 50
+	defaultHistoryAgeDays   = 30
+	defaultHistoryTotalSize = 256 << 20
+)
+
+func (l HistoryLimits) withDefaults() HistoryLimits {
+	if l.MaxFileSize <= 0 {
+		l.MaxFileSize = defaultHistoryFileSize
+	}
+	if l.MaxVersions <= 0 {
+		l.MaxVersions = defaultHistoryVersions
+	}
+	if l.MaxAgeDays <= 0 {
+		l.MaxAgeDays = defaultHistoryAgeDays
+	}
+	if l.MaxTotalBytes <= 0 {
+		l.MaxTotalBytes = defaultHistoryTotalSize
+	}
+	return l
+}
+
+type WatchHistoryRequest struct {
+	Root   string        `json:"root"`
+	Limits HistoryLimits `json:"limits"`
+}
+
+type HistoryFileRequest struct {
+	Root string `json:"root"`
+	Path string `json:"path"`
+}
+
+type DiffHistoryRequest struct {
+	Root string `json:"root"`
+	Path string `json:"path"`
+	// From and To are version ids; empty stands for the file as it is on
+	// disk now.
+	From string `json:"from"`
+	To   string `json:"to"`
+}
+
+type RestoreHistoryRequest struct {
+	Root string `json:"root"`
+	Path string `json:"path"`
+	ID   string `json:"id"`
+}
+
+type HistoryVersions struct {
+	// Versions are newest first.
+	Versions []HistoryVersion `json:"versions"`
+}
+
+type HistoryDiff struct {
+	// Diff is in unified format, empty when the versions are equal.
+	Diff string `json:"diff"`
+}
+
+// historyStore keeps the versions of the text files under Root. Contents
+// are stored once per distinct hash in a blobs directory next to the
+// index.
+type historyStore struct {
+	Root string `json:"root"`
+	// Files maps slash-separated paths relative to Root to their versions,
+	// oldest first.
+	Files map[string][]HistoryVersion `json:"files"`
+
+	dir    string
+	limits HistoryLimits
+}
+
+// WatchHistory records a version of each text file under root now and
+// every time it changes afterwards, replacing any previous watch.
+func (a *App) WatchHistory(req WatchHistoryRequest) error {
+	root := filepath.Clean(req.Root)
+	a.StopWatchHistory()
+
+	h, err := loadHistory(root)
+	if err != nil {
+		return err
+	}
+	h.limits = req.Limits.withDefaults()
+
+	a.historyMu.Lock()
+	defer a.historyMu.Unlock()
+	err = walkTextFiles(root, nil, func(path string) { h.snapshot(path) })
+	if err != nil {
+		return err
+	}
+	if err := h.commit(); err != nil {
+		return err
+	}
+
+	tw, err := newTreeWatcher(root, func(ev fsnotify.Event) {
+		if !ev.Has(fsnotify.Write) && !ev.Has(fsnotify.Create) {
+			return
+		}
+		a.historyMu.Lock()
+		defer a.historyMu.Unlock()
+		if a.history != h {
+			return
+		}
+		if a.historyPending == nil {
+			a.historyPending = make(map[string]bool)
+		}
+		a.historyPending[ev.Name] = true
+		if a.historyTimer == nil {
+			a.historyTimer = time.AfterFunc(historyDelay, a.flushHistory)
+		}
+	})
+	if err != nil {
+		return err
+	}
+	a.history = h
+	a.historyWatch = tw
+	return nil
+}
+
+func (a *App) StopWatchHistory() {
+	a.historyMu.Lock()
+	tw := a.historyWatch
+	a.historyWatch = nil
+	a.history = nil
+	if a.historyTimer != nil {
+		a.historyTimer.Stop()
+		a.historyTimer = nil
+	}
+	a.historyPending = nil
+	a.historyMu.Unlock()
+	if tw != nil {
+		tw.Close()
+	}
+}
+
+// flushHistory records the files changed since the last flush. Waiting for
+// writes to settle keeps the truncated, half-written states a save passes
+// through out of the history.
+func (a *App) flushHistory() {
+	a.historyMu.Lock()
+	defer a.historyMu.Unlock()
+	pending := a.historyPending
+	a.historyPending = nil
+	a.historyTimer = nil
+	if a.history == nil {
+		return
+	}
+	changed := false
+	for path := range pending {
+		if ok, _ := a.history.snapshot(path); ok {
+			changed = true
+		}
+	}
+	if changed {
+		a.history.commit()
+	}
+}
+
+// FileHistory lists the recorded versions of req.Path, newest first.
+func (a *App) FileHistory(req HistoryFileRequest) (HistoryVersions, error) {
+	a.historyMu.Lock()
+	defer a.historyMu.Unlock()
+	h, rel, err := a.historyForLocked(req.Root, req.Path)
+	if err != nil {
+		return HistoryVersions{}, err
+	}
+	versions := h.Files[rel]
+	out := make([]HistoryVersion, len(versions))
+	for i, v := range versions {
+		out[len(versions)-1-i] = v
+	}
+	return HistoryVersions{Versions: out}, nil
+}
+
+// DiffHistory returns a unified diff between two versions of req.Path.
+func (a *App) DiffHistory(req DiffHistoryRequest) (HistoryDiff, error) {
+	path := req.Path
+	a.historyMu.Lock()
+	defer a.historyMu.Unlock()
+	h, rel, err := a.historyForLocked(req.Root, path)
+	if err != nil {
+		return HistoryDiff{}, err
+	}
+
+	var texts [2]string
+	for i, id := range []string{req.From, req.To} {
+		var data []byte
+		if id == "" {
+			data, err = os.ReadFile(path)
+		} else {
+			data, err = h.read(rel, id)
+		}
+		if err != nil {
+			return HistoryDiff{}, err
+		}
+		text, _, ok := decodeText(data)
+		if !ok {
+			return HistoryDiff{}, errors.New(path + " is not a text file")
+		}
+		texts[i] = text
+	}
+	diff, ok := unifiedDiff(historyLabel(rel, req.From), historyLabel(rel, req.To), texts[0], texts[1], defaultDiffContext, defaultDiffEdits)
+	if !ok {
+		return HistoryDiff{}, errors.New(path + ": too many changed lines to show a diff")
+	}
+	return HistoryDiff{Diff: diff}, nil
+}
+
+// RestoreHistory puts version req.ID of req.Path back on disk. The current
+// contents are recorded first, so a restore can itself be undone.
+func (a *App) RestoreHistory(req RestoreHistoryRequest) error {
+	path := req.Path
+	a.historyMu.Lock()
+	defer a.historyMu.Unlock()
+	h, rel, err := a.historyForLocked(req.Root, path)
+	if err != nil {
+		return err
+	}
+	data, err := h.read(rel, req.ID)
+	if err != nil {
+		return err
+	}
+
+	if _, err := os.Stat(path); err != nil {
+		if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+			return err
+		}
+		return os.WriteFile(path, data, 0o644)
+	}
+	if changed, _ := h.snapshot(path); changed {
+		if err := h.commit(); err != nil {
+			return err
+		}
+	}
+	return replaceFileContents(path, data)
+}
+
+// historyForLocked returns the store for root, reusing the watched one so
+// its in-memory state stays authoritative, and path relative to root.
+func (a *App) historyForLocked(root, path string) (*historyStore, string, error) {
+	root = filepath.Clean(root)
+	if !withinRoot(root, path) {
+		return nil, "", errors.New(path + " is outside " + root)
+	}
+	rel, err := filepath.Rel(root, path)
+	if err != nil {
+		return nil, "", err
+	}
+	rel = filepath.ToSlash(rel)
+	if a.history != nil && a.history.Root == root {
+		return a.history, rel, nil
+	}
+	h, err := loadHistory(root)
+	return h, rel, err
+}
+
+func historyLabel(rel, id string) string {
+	if id == "" {
+		return rel
+	}
+	return rel + "@" + id
+}
+
+func loadHistory(root string) (*historyStore, error) {
+	base, err := os.UserCacheDir()
+	if err != nil {
+		return nil, err
+	}
+	sum := sha256.Sum256([]byte(root))
+	h := &historyStore{
+		Root:   root,
+		Files:  map[string][]HistoryVersion{},
+		dir:    filepath.Join(base, "recursion", "history", hex.EncodeToString(sum[:8])),
+		limits: HistoryLimits{}.withDefaults(),
+	}
+	data, err := os.ReadFile(filepath.Join(h.dir, "index.json"))
+	if err == nil {
+		var loaded historyStore
+		if json.Unmarshal(data, &loaded) == nil && loaded.Root == root && loaded.Files != nil {
+			h.Files = loaded.Files
+		}
+	}
+	return h, nil
+}
+
+func (h *historyStore) save() error {
+	if err := os.MkdirAll(h.dir, 0o755); err != nil {
+		return err
+	}
+	data, err := json.Marshal(h)
+	if err != nil {
+		return err
+	}
+	tmp, err := os.CreateTemp(h.dir, ".index-*")
+	if err != nil {
+		return err
+	}
+	if _, err := tmp.Write(data); err != nil {
+		tmp.Close()
+		os.Remove(tmp.Name())
+		return err
+	}
+	if err := tmp.Close(); err != nil {
+		os.Remove(tmp.Name())
+		return err
+	}
+	return os.Rename(tmp.Name(), filepath.Join(h.dir, "index.json"))
+}
+
+func (h *historyStore) blob(hash string) string {
+	return filepath.Join(h.dir, "blobs", hash)
+}
+
+func (h *historyStore) read(rel, id string) ([]byte, error) {
+	for _, v := range h.Files[rel] {
+		if v.ID == id {
+			return os.ReadFile(h.blob(v.Hash))
+		}
+	}
+	return nil, errors.New("no version " + id + " of " + rel)
+}
+
+// snapshot records path's current contents if it is a text file within
+// the limits and differs from its latest version.
+func (h *historyStore) snapshot(path string) (bool, error) {
+	rel, err := filepath.Rel(h.Root, path)
+	if err != nil || rel == ".." || strings.HasPrefix(rel, ".."+string(filepath.Separator)) {
+		return false, err
+	}
+	rel = filepath.ToSlash(rel)
+	if rel == ".git" || strings.HasPrefix(rel, ".git/") || strings.Contains(rel, "/.git/") {
+		return false, nil
+	}
+	info, err := os.Stat(path)
+	if err != nil || !info.Mode().IsRegular() || info.Size() > h.limits.MaxFileSize {
+		return false, err
+	}
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return false, err
+	}
+	if _, _, ok := decodeText(data); !ok {
+		return false, nil
+	}
+
+	hash := hashBytes(data)
+	versions := h.Files[rel]
+	if n := len(versions); n > 0 && versions[n-1].Hash == hash {
+		return false, nil
+	}
+	if _, err := os.Stat(h.blob(hash)); err != nil {
+		if err := os.MkdirAll(filepath.Dir(h.blob(hash)), 0o755); err != nil {
+			return false, err
+		}
+		if err := os.WriteFile(h.blob(hash), data, 0o600); err != nil {
+			return false, err
+		}
+	}
+
+	// IDs are nanosecond timestamps, kept unique and increasing per file.
+	now := time.Now()
+	id := now.UnixNano()
+	if n := len(versions); n > 0 {
+		if last, _ := strconv.ParseInt(versions[n-1].ID, 10, 64); last >= id {
+			id = last + 1
+		}
+	}
+	v := HistoryVersion{ID: strconv.FormatInt(id, 10), Time: now, Size: int64(len(data)), Hash: hash}
+	h.Files[rel] = append(versions, v)
+	return true, nil
+}
+
+// commit applies the limits, deletes blobs no version refers to anymore
+// and saves the index.
+func (h *historyStore) commit() error {
+	cutoff := time.Now().AddDate(0, 0, -h.limits.MaxAgeDays)
+	for rel, versions := range h.Files {
+		drop := max(0, len(versions)-h.limits.MaxVersions)
+		for drop < len(versions)-1 && versions[drop].Time.Before(cutoff) {
+			drop++
+		}
+		h.Files[rel] = versions[drop:]
+	}
+
+	// Over the size cap, drop the oldest versions anywhere in the store.
+	// Versions sharing contents share a blob, which counts once and is
+	// only freed with the last of them.
+	type storedVersion struct {
+		rel string
+		HistoryVersion
+	}
+	var all []storedVersion
+	refs := make(map[string]int)
+	var total int64
+	for rel, versions := range h.Files {
+		for _, v := range versions {
+			if refs[v.Hash] == 0 {
+				total += v.Size
+			}
+			refs[v.Hash]++
+			all = append(all, storedVersion{rel, v})
+		}
+	}
+	if total > h.limits.MaxTotalBytes {
+		// Each file's versions are oldest first, so going through all of
+		// them by age only ever drops from the front of a file's list.
+		sort.SliceStable(all, func(i, j int) bool { return all[i].Time.Before(all[j].Time) })
+		dropped := make(map[string]int)
+		for _, v := range all {
+			if total <= h.limits.MaxTotalBytes {
+				break
+			}
+			dropped[v.rel]++
+			if refs[v.Hash]--; refs[v.Hash] == 0 {
+				total -= v.Size
+			}
+		}
+		for rel, n := range dropped {
+			if h.Files[rel] = h.Files[rel][n:]; len(h.Files[rel]) == 0 {
+				delete(h.Files, rel)
+			}
+		}
+	}
+
+	entries, _ := os.ReadDir(filepath.Join(h.dir, "blobs"))
+	for _, e := range entries {
+		if refs[e.Name()] == 0 {
+			os.Remove(filepath.Join(h.dir, "blobs", e.Name()))
+		}
+	}
+	return h.save()
+}
@@ -0,0 +1,143 @@
+package main
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestFileHistory(t *testing.T) {
+	t.Setenv("XDG_CACHE_HOME", t.TempDir())
+	root := t.TempDir()
+	writeFiles(t, root, map[string]string{
+		"notes.txt": "one\n",
+		"image.bin": "\x00\x01",
+		"big.txt":   strings.Repeat("x", 100),
+	})
+	a := NewApp()
+	if err := a.WatchHistory(WatchHistoryRequest{Root: root, Limits: HistoryLimits{MaxFileSize: 50, MaxVersions: 3}}); err != nil {
+		t.Fatal(err)
+	}
+	defer a.StopWatchHistory()
+
+	notes := filepath.Join(root, "notes.txt")
+	waitVersions := func(path string, n int) []HistoryVersion {
+		t.Helper()
+		deadline := time.Now().Add(5 * time.Second)
+		for {
+			h, err := a.FileHistory(HistoryFileRequest{Root: root, Path: path})
+			if err != nil {
+				t.Fatal(err)
+			}
+			if len(h.Versions) == n {
+				return h.Versions
+			}
+			if time.Now().After(deadline) {
+				t.Fatalf("%s has %d versions, want %d", path, len(h.Versions), n)
+			}
+			time.Sleep(20 * time.Millisecond)
+		}
+	}
+
+	waitVersions(notes, 1)
+	for _, content := range []string{"one\ntwo\n", "one\ntwo\nthree\n", "four\n"} {
+		n := len(content)
+		writeFiles(t, root, map[string]string{"notes.txt": content})
+		// Wait for this write before the next, so each one is a version.
+		deadline := time.Now().Add(5 * time.Second)
+		for {
+			h, _ := a.FileHistory(HistoryFileRequest{Root: root, Path: notes})
+			if len(h.Versions) > 0 && h.Versions[0].Size == int64(n) {
+				break
+			}
+			if time.Now().After(deadline) {
+				t.Fatalf("write of %q was not recorded", content)
+			}
+			time.Sleep(20 * time.Millisecond)
+		}
+	}
+	versions := waitVersions(notes, 3)
+
+	tests := []struct {
+		name     string
+		from, to string
+		want     string
+	}{
+		{"oldest to newest", versions[2].ID, versions[0].ID, "-one\n-two\n+four\n"},
+		{"version to disk", versions[1].ID, "", "-three\n"},
+		{"same version", versions[0].ID, versions[0].ID, ""},
+	}
+	for _, tt := range tests {
+		d, err := a.DiffHistory(DiffHistoryRequest{Root: root, Path: notes, From: tt.from, To: tt.to})
+		if err != nil {
+			t.Fatalf("%s: %v", tt.name, err)
+		}
+		if diff := d.Diff; !strings.Contains(diff, tt.want) || tt.want == "" && diff != "" {
+			t.Errorf("%s: diff\n%s\nwant it to contain %q", tt.name, diff, tt.want)
+		}
+	}
+
+	for _, skipped := range []string{"image.bin", "big.txt"} {
+		if v, _ := a.FileHistory(HistoryFileRequest{Root: root, Path: filepath.Join(root, skipped)}); len(v.Versions) != 0 {
+			t.Errorf("%s has %d versions, want none", skipped, len(v.Versions))
+		}
+	}
+
+	a.StopWatchHistory()
+	if err := a.RestoreHistory(RestoreHistoryRequest{Root: root, Path: notes, ID: versions[2].ID}); err != nil {
+		t.Fatal(err)
+	}
+	if got, _ := os.ReadFile(notes); string(got) != "one\ntwo\n" {
+		t.Errorf("restored notes.txt = %q", got)
+	}
+	if err := a.RestoreHistory(RestoreHistoryRequest{Root: root, Path: notes, ID: "0"}); err == nil {
+		t.Error("restoring an unknown version succeeded, want error")
+	}
+}
+
+func TestHistorySizeCap(t *testing.T) {
+	base := time.Now()
+	version := func(min int, hash string, size int64) HistoryVersion {
+		return HistoryVersion{ID: hash + "-" + string(rune('0'+min)), Time: base.Add(time.Duration(min) * time.Minute), Size: size, Hash: hash}
+	}
+	files := map[string][]HistoryVersion{
+		"a.txt": {version(1, "h1", 10), version(3, "h2", 10), version(5, "h3", 10)},
+		// b.txt's first version has the same contents as a.txt's second.
+		"b.txt": {version(2, "h2", 10), version(4, "h4", 10)},
+	}
+
+	tests := []struct {
+		max  int64
+		want map[string]int
+	}{
+		{40, map[string]int{"a.txt": 3, "b.txt": 2}},
+		// Dropping h1 is enough.
+		{30, map[string]int{"a.txt": 2, "b.txt": 2}},
+		// b.txt's h2 goes too, but the blob stays with a.txt.
+		{25, map[string]int{"a.txt": 1, "b.txt": 1}},
+		{10, map[string]int{"a.txt": 1}},
+	}
+	for _, tt := range tests {
+		h := &historyStore{
+			Files:  make(map[string][]HistoryVersion),
+			dir:    t.TempDir(),
+			limits: HistoryLimits{MaxTotalBytes: tt.max}.withDefaults(),
+		}
+		for rel, versions := range files {
+			h.Files[rel] = append([]HistoryVersion(nil), versions...)
+		}
+		if err := h.commit(); err != nil {
+			t.Fatal(err)
+		}
+		got := make(map[string]int)
+		for rel, versions := range h.Files {
+			got[rel] = len(versions)
+		}
+		if !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("cap %d kept %v, want %v", tt.max, got, tt.want)
+		}
+	}
+}
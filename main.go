@@ -2,6 +2,7 @@ package main
 
 import (
 	"embed"
+	"os"
 
 	"github.com/wailsapp/wails/v2"
 	"github.com/wailsapp/wails/v2/pkg/options"
@@ -12,6 +13,15 @@ import (
 var assets embed.FS
 
 func main() {
+	// "recursion headless -config file" serves metrics without a window.
+	if len(os.Args) > 1 && os.Args[1] == "headless" {
+		if err := runHeadless(os.Args[2:]); err != nil {
+			println("Error:", err.Error())
+			os.Exit(1)
+		}
+		return
+	}
+
 	// Create an instance of the app structure
 	app := NewApp()
 
@@ -25,6 +35,7 @@ func main() {
 		},
 		BackgroundColour: &options.RGBA{R: 27, G: 38, B: 54, A: 1},
 		OnStartup:        app.startup,
+		OnShutdown:       app.shutdown,
 		Bind: []interface{}{
 			app,
 		},
@@ -0,0 +1,236 @@
+package main
+
+import (
+	"bufio"
+	"net/url"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+type DocLink struct {
+	Source string `json:"source"`
+	Target string `json:"target"`
+	Anchor string `json:"anchor,omitempty"`
+	Line   int    `json:"line"`
+}
+
+type BrokenLink struct {
+	Source string `json:"source"`
+	Target string `json:"target"`
+	Anchor string `json:"anchor,omitempty"`
+	Line   int    `json:"line"`
+	Reason string `json:"reason"`
+}
+
+type DocLinkReport struct {
+	Files  []string     `json:"files"`
+	Links  []DocLink    `json:"links"`
+	Broken []BrokenLink `json:"broken"`
+}
+
+var (
+	mdInlineLink  = regexp.MustCompile(`!?\[[^\]]*\]\(\s*<?([^)\s>]*)>?(?:\s+["'(][^)]*)?\)`)
+	mdRefDef      = regexp.MustCompile(`^\s{0,3}\[[^\]^][^\]]*\]:\s*<?([^\s>]+)>?`)
+	mdHeading     = regexp.MustCompile(`^\s{0,3}#{1,6}\s+(.*?)\s*#*\s*$`)
+	mdSetext      = regexp.MustCompile(`^\s{0,3}(=+|-+)\s*$`)
+	mdHTMLAnchor  = regexp.MustCompile(`<a\s[^>]*(?:name|id)\s*=\s*["']([^"']+)["']`)
+	mdCodeSpan    = regexp.MustCompile("`[^`]*`")
+	mdSlugExclude = regexp.MustCompile(`[^\p{L}\p{N}\s_-]`)
+)
+
+type markdownDoc struct {
+	links   []DocLink
+	anchors map[string]bool
+}
+
+// CheckMarkdownLinks parses every Markdown file under req.Root and returns the
+// links between them, plus relative links whose file or anchor is missing.
+// Links to external URLs are ignored.
+func (a *App) CheckMarkdownLinks(req RootRequest) (DocLinkReport, error) {
+	root := filepath.Clean(req.Root)
+	report := DocLinkReport{Files: []string{}, Links: []DocLink{}, Broken: []BrokenLink{}}
+	docs := make(map[string]*markdownDoc)
+
+	err := filepath.WalkDir(root, func(path string, d os.DirEntry, err error) error {
+		if err != nil {
+			if path == root {
+				return err
+			}
+			return nil
+		}
+		if d.IsDir() {
+			if path != root && (d.Name() == ".git" || d.Name() == "node_modules") {
+				return filepath.SkipDir
+			}
+			return nil
+		}
+		if !isMarkdown(path) {
+			return nil
+		}
+
+		doc, err := parseMarkdown(path)
+		if err != nil {
+			return nil
+		}
+		docs[path] = doc
+		report.Files = append(report.Files, path)
+		return nil
+	})
+	if err != nil {
+		return report, err
+	}
+
+	for _, path := range report.Files {
+		for _, link := range docs[path].links {
+			target, reason := resolveDocLink(root, link, docs)
+			link.Target = target
+			if reason != "" {
+				report.Broken = append(report.Broken, BrokenLink{
+					Source: link.Source,
+					Target: link.Target,
+					Anchor: link.Anchor,
+					Line:   link.Line,
+					Reason: reason,
+				})
+				continue
+			}
+			report.Links = append(report.Links, link)
+		}
+	}
+	return report, nil
+}
+
+func isMarkdown(path string) bool {
+	switch strings.ToLower(filepath.Ext(path)) {
+	case ".md", ".markdown", ".mdx":
+		return true
+	}
+	return false
+}
+
+func parseMarkdown(path string) (*markdownDoc, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	doc := &markdownDoc{anchors: make(map[string]bool)}
+	slugs := make(map[string]int)
+	addHeading := func(heading string) {
+		slug := markdownSlug(heading)
+		if n := slugs[slug]; n > 0 {
+			doc.anchors[slug+"-"+strconv.Itoa(n)] = true
+		} else {
+			doc.anchors[slug] = true
+		}
+		slugs[slug]++
+	}
+	fence := ""
+	paragraph := ""
+
+	scanner := bufio.NewScanner(f)
+	scanner.Buffer(make([]byte, 64*1024), 1024*1024)
+	for line := 1; scanner.Scan(); line++ {
+		text := scanner.Text()
+
+		trimmed := strings.TrimSpace(text)
+		previous := paragraph
+		paragraph = ""
+		if fence != "" {
+			if strings.HasPrefix(trimmed, fence) {
+				fence = ""
+			}
+			continue
+		}
+		if strings.HasPrefix(trimmed, "```") || strings.HasPrefix(trimmed, "~~~") {
+			fence = trimmed[:3]
+			continue
+		}
+
+		if m := mdHeading.FindStringSubmatch(text); m != nil {
+			addHeading(m[1])
+		} else if previous != "" && mdSetext.MatchString(text) {
+			addHeading(previous)
+			continue
+		} else if trimmed != "" {
+			paragraph = trimmed
+		}
+
+		text = mdCodeSpan.ReplaceAllString(text, "")
+		for _, m := range mdHTMLAnchor.FindAllStringSubmatch(text, -1) {
+			doc.anchors[m[1]] = true
+		}
+
+		var dests []string
+		for _, m := range mdInlineLink.FindAllStringSubmatch(text, -1) {
+			dests = append(dests, m[1])
+		}
+		if m := mdRefDef.FindStringSubmatch(text); m != nil {
+			dests = append(dests, m[1])
+		}
+
+		for _, dest := range dests {
+			if dest == "" || isExternalLink(dest) {
+				continue
+			}
+			target, anchor, _ := strings.Cut(dest, "#")
+			if unescaped, err := url.PathUnescape(target); err == nil {
+				target = unescaped
+			}
+			doc.links = append(doc.links, DocLink{
+				Source: path,
+				Target: target,
+				Anchor: anchor,
+				Line:   line,
+			})
+		}
+	}
+	return doc, scanner.Err()
+}
+
+func isExternalLink(dest string) bool {
+	if strings.HasPrefix(dest, "//") {
+		return true
+	}
+	u, err := url.Parse(dest)
+	return err == nil && u.Scheme != ""
+}
+
+// markdownSlug derives a heading anchor the way GitHub does: lowercase,
+// punctuation dropped, spaces turned into hyphens. Code spans keep their
+// text.
+func markdownSlug(heading string) string {
+	heading = strings.ToLower(strings.TrimSpace(strings.ReplaceAll(heading, "`", "")))
+	heading = mdSlugExclude.ReplaceAllString(heading, "")
+	return strings.ReplaceAll(heading, " ", "-")
+}
+
+func resolveDocLink(root string, link DocLink, docs map[string]*markdownDoc) (string, string) {
+	target := link.Source
+	if link.Target != "" {
+		if strings.HasPrefix(link.Target, "/") {
+			target = filepath.Join(root, filepath.FromSlash(link.Target))
+		} else {
+			target = filepath.Join(filepath.Dir(link.Source), filepath.FromSlash(link.Target))
+		}
+		if _, err := os.Stat(target); err != nil {
+			return target, "missing file"
+		}
+	}
+
+	if link.Anchor == "" {
+		return target, ""
+	}
+	doc, ok := docs[target]
+	if !ok {
+		return target, ""
+	}
+	if !doc.anchors[strings.ToLower(link.Anchor)] && !doc.anchors[link.Anchor] {
+		return target, "missing anchor"
+	}
+	return target, ""
+}
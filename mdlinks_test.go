@@ -0,0 +1,75 @@
+package main
+
+import (
+	"path/filepath"
+	"testing"
+)
+
+func TestCheckMarkdownLinks(t *testing.T) {
+	tests := []struct {
+		name   string
+		doc    string
+		broken []string
+	}{
+		{
+			name: "code span heading",
+			doc:  "## The `Config` type\n\n[x](#the-config-type)\n",
+		},
+		{
+			name: "footnote definition",
+			doc:  "Text[^1].\n\n[^1]: Some explanation\n",
+		},
+		{
+			name: "setext headings",
+			doc:  "Title\n=====\n\nSub Part\n--------\n\n[a](#title) [b](#sub-part)\n",
+		},
+		{
+			name: "duplicate headings",
+			doc:  "# Intro\n# Intro\n[a](#intro) [b](#intro-1)\n",
+		},
+		{
+			name:   "missing anchor",
+			doc:    "# Intro\n[a](#nope)\n",
+			broken: []string{"missing anchor"},
+		},
+		{
+			name:   "missing file",
+			doc:    "[a](other.md) [b](https://example.com/x.md)\n",
+			broken: []string{"missing file"},
+		},
+		{
+			name: "links inside code",
+			doc:  "```\n[a](gone.md)\n```\n`[b](gone.md)`\n",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			dir := t.TempDir()
+			writeFiles(t, dir, map[string]string{"README.md": tt.doc})
+
+			report, err := NewApp().CheckMarkdownLinks(RootRequest{Root: dir})
+			if err != nil {
+				t.Fatal(err)
+			}
+			var got []string
+			for _, b := range report.Broken {
+				got = append(got, b.Reason)
+			}
+			if len(got) != len(tt.broken) {
+				t.Fatalf("broken = %+v, want reasons %v", report.Broken, tt.broken)
+			}
+			for i := range got {
+				if got[i] != tt.broken[i] {
+					t.Errorf("broken[%d] = %q, want %q", i, got[i], tt.broken[i])
+				}
+			}
+		})
+	}
+}
+
+func TestCheckMarkdownLinksMissingRoot(t *testing.T) {
+	if _, err := NewApp().CheckMarkdownLinks(RootRequest{Root: filepath.Join(t.TempDir(), "nonexistent")}); err == nil {
+		t.Error("CheckMarkdownLinks on a missing root succeeded, want error")
+	}
+}
@@ -0,0 +1,396 @@
+package main
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"golang.org/x/mod/modfile"
+	"golang.org/x/mod/module"
+	"golang.org/x/mod/semver"
+)
+
+type ModuleVersion struct {
+	Module       string   `json:"module"`
+	Version      string   `json:"version"`
+	Dir          string   `json:"dir,omitempty"`
+	Size         int64    `json:"size"`
+	Referenced   bool     `json:"referenced"`
+	ReferencedBy []string `json:"referencedBy,omitempty"`
+}
+
+type CachedModule struct {
+	Path     string          `json:"path"`
+	Size     int64           `json:"size"`
+	Versions []ModuleVersion `json:"versions"`
+}
+
+type ModCacheReport struct {
+	Root             string         `json:"root"`
+	Size             int64          `json:"size"`
+	UnreferencedSize int64          `json:"unreferencedSize"`
+	Modules          []CachedModule `json:"modules"`
+}
+
+type ModCacheRequest struct {
+	// ProjectRoots are searched for the go.mod and go.sum files that keep
+	// versions referenced.
+	ProjectRoots []string `json:"projectRoots"`
+}
+
+type CleanModCacheRequest struct {
+	ProjectRoots []string        `json:"projectRoots"`
+	Versions     []ModuleVersion `json:"versions"`
+	// DryRun lists what would be removed without deleting anything.
+	DryRun bool `json:"dryRun"`
+}
+
+type CleanModCacheResult struct {
+	Removed []string `json:"removed"`
+}
+
+// AnalyzeModCache groups the contents of GOMODCACHE by module and version.
+// A version counts as referenced when a go.mod or go.sum found under one of
+// req.ProjectRoots mentions it.
+func (a *App) AnalyzeModCache(req ModCacheRequest) (ModCacheReport, error) {
+	projectRoots := req.ProjectRoots
+	root := goModCacheDir()
+	report := ModCacheReport{Root: root, Modules: []CachedModule{}}
+	if len(projectRoots) == 0 {
+		return report, errNoProjectRoots
+	}
+	if _, err := os.Stat(root); err != nil {
+		return report, err
+	}
+
+	versions := make(map[module.Version]*ModuleVersion)
+	get := func(mv module.Version) *ModuleVersion {
+		v, ok := versions[mv]
+		if !ok {
+			v = &ModuleVersion{Module: mv.Path, Version: mv.Version}
+			versions[mv] = v
+		}
+		return v
+	}
+
+	if err := scanModDownloads(root, get); err != nil {
+		return report, err
+	}
+	if err := scanModSources(root, get); err != nil {
+		return report, err
+	}
+
+	refs := findModReferences(projectRoots)
+	modules := make(map[string]*CachedModule)
+	for mv, v := range versions {
+		v.ReferencedBy = refs[mv]
+		v.Referenced = len(v.ReferencedBy) > 0
+
+		m, ok := modules[mv.Path]
+		if !ok {
+			m = &CachedModule{Path: mv.Path}
+			modules[mv.Path] = m
+		}
+		m.Versions = append(m.Versions, *v)
+		m.Size += v.Size
+		report.Size += v.Size
+		if !v.Referenced {
+			report.UnreferencedSize += v.Size
+		}
+	}
+
+	for _, m := range modules {
+		sort.Slice(m.Versions, func(i, j int) bool {
+			return semver.Compare(m.Versions[i].Version, m.Versions[j].Version) < 0
+		})
+		report.Modules = append(report.Modules, *m)
+	}
+	sort.Slice(report.Modules, func(i, j int) bool {
+		return report.Modules[i].Size > report.Modules[j].Size
+	})
+	return report, nil
+}
+
+// CleanModCache deletes the extracted sources and downloaded files of
+// req.Versions and returns the removed paths. Versions still referenced
+// from req.ProjectRoots are refused.
+func (a *App) CleanModCache(req CleanModCacheRequest) (CleanModCacheResult, error) {
+	projectRoots, versions, dryRun := req.ProjectRoots, req.Versions, req.DryRun
+	root := goModCacheDir()
+	removed := []string{}
+	if len(projectRoots) == 0 {
+		return CleanModCacheResult{Removed: removed}, errNoProjectRoots
+	}
+
+	refs := findModReferences(projectRoots)
+	for _, v := range versions {
+		if files := refs[module.Version{Path: v.Module, Version: v.Version}]; len(files) > 0 {
+			return CleanModCacheResult{Removed: removed}, fmt.Errorf("%s@%s is still referenced by %s", v.Module, v.Version, files[0])
+		}
+	}
+
+	for _, v := range versions {
+		escPath, err := module.EscapePath(v.Module)
+		if err != nil {
+			return CleanModCacheResult{Removed: removed}, err
+		}
+		escVersion, err := module.EscapeVersion(v.Version)
+		if err != nil {
+			return CleanModCacheResult{Removed: removed}, err
+		}
+
+		vdir := filepath.Join(root, "cache", "download", filepath.FromSlash(escPath), "@v")
+		targets := []string{filepath.Join(root, filepath.FromSlash(escPath)+"@"+escVersion)}
+		downloads, _ := filepath.Glob(filepath.Join(vdir, escVersion+".*"))
+		targets = append(targets, downloads...)
+
+		for _, target := range targets {
+			if _, err := os.Lstat(target); err != nil {
+				continue
+			}
+			if !dryRun {
+				if err := removeReadOnlyTree(target); err != nil {
+					return CleanModCacheResult{Removed: removed}, err
+				}
+			}
+			removed = append(removed, target)
+		}
+		if !dryRun {
+			if err := removeListedVersion(filepath.Join(vdir, "list"), v.Version); err != nil {
+				return CleanModCacheResult{Removed: removed}, err
+			}
+		}
+	}
+	return CleanModCacheResult{Removed: removed}, nil
+}
+
+var errNoProjectRoots = errors.New("no project roots given to check module references against")
+
+// removeListedVersion drops version from an @v/list file, which the go
+// command consults to answer version queries offline.
+func removeListedVersion(list, version string) error {
+	data, err := os.ReadFile(list)
+	if os.IsNotExist(err) {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+
+	var kept []string
+	for _, line := range strings.Split(strings.TrimRight(string(data), "\n"), "\n") {
+		if line != "" && strings.TrimSpace(line) != version {
+			kept = append(kept, line)
+		}
+	}
+	out := strings.Join(kept, "\n")
+	if out != "" {
+		out += "\n"
+	}
+	return os.WriteFile(list, []byte(out), 0o644)
+}
+
+func goModCacheDir() string {
+	if dir := os.Getenv("GOMODCACHE"); dir != "" {
+		return dir
+	}
+	gopath := os.Getenv("GOPATH")
+	if list := filepath.SplitList(gopath); len(list) > 0 && list[0] != "" {
+		return filepath.Join(list[0], "pkg", "mod")
+	}
+	home, _ := os.UserHomeDir()
+	return filepath.Join(home, "go", "pkg", "mod")
+}
+
+var modDownloadExts = map[string]bool{".info": true, ".mod": true, ".zip": true, ".ziphash": true}
+
+// scanModDownloads reads cache/download, where each module has an @v
+// directory holding <version>.info, .mod, .zip and .ziphash files.
+func scanModDownloads(root string, get func(module.Version) *ModuleVersion) error {
+	download := filepath.Join(root, "cache", "download")
+	err := filepath.WalkDir(download, func(path string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return nil
+		}
+		if !d.IsDir() {
+			return nil
+		}
+		if path == filepath.Join(download, "sumdb") {
+			return filepath.SkipDir
+		}
+		if d.Name() != "@v" {
+			return nil
+		}
+
+		rel, _ := filepath.Rel(download, filepath.Dir(path))
+		modPath, err := module.UnescapePath(filepath.ToSlash(rel))
+		if err != nil {
+			return filepath.SkipDir
+		}
+
+		entries, _ := os.ReadDir(path)
+		for _, e := range entries {
+			name := e.Name()
+			ext := filepath.Ext(name)
+			if e.IsDir() || !modDownloadExts[ext] {
+				continue
+			}
+			version, err := module.UnescapeVersion(strings.TrimSuffix(name, ext))
+			if err != nil || !semver.IsValid(version) {
+				continue
+			}
+			if info, err := e.Info(); err == nil {
+				get(module.Version{Path: modPath, Version: version}).Size += info.Size()
+			}
+		}
+		return filepath.SkipDir
+	})
+	if os.IsNotExist(err) {
+		return nil
+	}
+	return err
+}
+
+// scanModSources finds extracted module trees, which live at
+// <escaped path>@<escaped version> below the cache root.
+func scanModSources(root string, get func(module.Version) *ModuleVersion) error {
+	return filepath.WalkDir(root, func(path string, d fs.DirEntry, err error) error {
+		if err != nil || !d.IsDir() || path == root {
+			return nil
+		}
+		if path == filepath.Join(root, "cache") {
+			return filepath.SkipDir
+		}
+
+		at := strings.LastIndex(d.Name(), "@")
+		if at < 0 {
+			return nil
+		}
+
+		rel, _ := filepath.Rel(root, path)
+		rel = filepath.ToSlash(rel)
+		escPath, escVersion := rel[:len(rel)-len(d.Name())+at], d.Name()[at+1:]
+		modPath, err := module.UnescapePath(escPath)
+		if err != nil {
+			return filepath.SkipDir
+		}
+		version, err := module.UnescapeVersion(escVersion)
+		if err != nil {
+			return filepath.SkipDir
+		}
+
+		v := get(module.Version{Path: modPath, Version: version})
+		v.Dir = path
+		v.Size += dirSize(path)
+		return filepath.SkipDir
+	})
+}
+
+// findModReferences collects module versions named by go.mod and go.sum
+// files under roots, mapped to the files that mention them.
+func findModReferences(roots []string) map[module.Version][]string {
+	refs := make(map[module.Version][]string)
+	add := func(mv module.Version, file string) {
+		files := refs[mv]
+		if len(files) > 0 && files[len(files)-1] == file {
+			return
+		}
+		refs[mv] = append(files, file)
+	}
+
+	for _, root := range roots {
+		filepath.WalkDir(root, func(path string, d fs.DirEntry, err error) error {
+			if err != nil {
+				return nil
+			}
+			if d.IsDir() {
+				if path != root && (d.Name() == ".git" || d.Name() == "node_modules") {
+					return filepath.SkipDir
+				}
+				return nil
+			}
+
+			switch d.Name() {
+			case "go.mod":
+				data, err := os.ReadFile(path)
+				if err != nil {
+					return nil
+				}
+				f, err := modfile.ParseLax(path, data, nil)
+				if err != nil {
+					return nil
+				}
+				for _, r := range f.Require {
+					add(r.Mod, path)
+				}
+				for _, r := range f.Replace {
+					if r.New.Version != "" {
+						add(r.New, path)
+					}
+				}
+			case "go.sum":
+				for _, mv := range readGoSum(path) {
+					add(mv, path)
+				}
+			}
+			return nil
+		})
+	}
+	return refs
+}
+
+func readGoSum(path string) []module.Version {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil
+	}
+	defer f.Close()
+
+	var versions []module.Version
+	scanner := bufio.NewScanner(f)
+	for scanner.Scan() {
+		fields := strings.Fields(scanner.Text())
+		if len(fields) != 3 {
+			continue
+		}
+		versions = append(versions, module.Version{
+			Path:    fields[0],
+			Version: strings.TrimSuffix(fields[1], "/go.mod"),
+		})
+	}
+	return versions
+}
+
+func dirSize(root string) int64 {
+	var size int64
+	filepath.WalkDir(root, func(path string, d fs.DirEntry, err error) error {
+		if err != nil || d.IsDir() {
+			return nil
+		}
+		if info, err := d.Info(); err == nil {
+			size += info.Size()
+		}
+		return nil
+	})
+	return size
+}
+
+// removeReadOnlyTree deletes path even though the go command leaves module
+// sources without write permission.
+func removeReadOnlyTree(path string) error {
+	filepath.WalkDir(path, func(p string, d fs.DirEntry, err error) error {
+		if err == nil && d.IsDir() {
+			os.Chmod(p, 0o755)
+		}
+		return nil
+	})
+	if err := os.RemoveAll(path); err != nil {
+		return fmt.Errorf("removing %s: %w", path, err)
+	}
+	return nil
+}
@@ -0,0 +1,135 @@
+package main
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func writeFiles(t *testing.T, root string, files map[string]string) {
+	t.Helper()
+	for name, content := range files {
+		path := filepath.Join(root, filepath.FromSlash(name))
+		if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(path, []byte(content), 0o644); err != nil {
+			t.Fatal(err)
+		}
+	}
+}
+
+func newModCache(t *testing.T) (string, string) {
+	t.Helper()
+	cache := t.TempDir()
+	project := t.TempDir()
+	writeFiles(t, cache, map[string]string{
+		"cache/download/github.com/!burnt!sushi/toml/@v/v1.5.0.zip":           "zip",
+		"cache/download/github.com/!burnt!sushi/toml/@v/v1.5.0.mod":           "mod",
+		"cache/download/github.com/!burnt!sushi/toml/@v/v1.4.0.mod":           "mod",
+		"cache/download/github.com/!burnt!sushi/toml/@v/v1.4.0.zip123456.tmp": "partial",
+		"cache/download/github.com/!burnt!sushi/toml/@v/v1.4.0.lock":          "",
+		"cache/download/github.com/!burnt!sushi/toml/@v/list":                 "v1.4.0\nv1.5.0\n",
+		"cache/download/sumdb/sum.golang.org/lookup/github.com/x@v1.0.0":      "sum",
+		"github.com/!burnt!sushi/toml@v1.5.0/decode.go":                       "package toml",
+		"github.com/!burnt!sushi/toml@v1.4.0/decode.go":                       "package toml",
+		"golang.org/x/mod@v0.23.0/module/module.go":                           "package module",
+		"cache/download/golang.org/x/mod/@v/v0.23.0.info":                     "{}",
+	})
+	writeFiles(t, project, map[string]string{
+		"go.mod": "module example\n\ngo 1.23\n\nrequire github.com/BurntSushi/toml v1.5.0\n",
+		"go.sum": "golang.org/x/mod v0.23.0/go.mod h1:abc=\n",
+	})
+	t.Setenv("GOMODCACHE", cache)
+	return cache, project
+}
+
+func TestAnalyzeModCache(t *testing.T) {
+	_, project := newModCache(t)
+	a := NewApp()
+
+	if _, err := a.AnalyzeModCache(ModCacheRequest{}); err == nil {
+		t.Fatal("AnalyzeModCache(nil) succeeded, want error")
+	}
+
+	report, err := a.AnalyzeModCache(ModCacheRequest{ProjectRoots: []string{project}})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	got := make(map[string]bool)
+	for _, m := range report.Modules {
+		for _, v := range m.Versions {
+			got[v.Module+"@"+v.Version] = v.Referenced
+		}
+	}
+	tests := []struct {
+		version    string
+		referenced bool
+	}{
+		{"github.com/BurntSushi/toml@v1.5.0", true},
+		{"github.com/BurntSushi/toml@v1.4.0", false},
+		{"golang.org/x/mod@v0.23.0", true},
+	}
+	for _, tt := range tests {
+		referenced, ok := got[tt.version]
+		if !ok {
+			t.Errorf("%s missing from report", tt.version)
+			continue
+		}
+		if referenced != tt.referenced {
+			t.Errorf("%s referenced = %v, want %v", tt.version, referenced, tt.referenced)
+		}
+	}
+	if len(got) != len(tests) {
+		t.Errorf("report has versions %v, want exactly %d", got, len(tests))
+	}
+}
+
+func TestCleanModCache(t *testing.T) {
+	cache, project := newModCache(t)
+	a := NewApp()
+	stale := ModuleVersion{Module: "github.com/BurntSushi/toml", Version: "v1.4.0"}
+	inUse := ModuleVersion{Module: "github.com/BurntSushi/toml", Version: "v1.5.0"}
+
+	if _, err := a.CleanModCache(CleanModCacheRequest{Versions: []ModuleVersion{stale}, DryRun: true}); err == nil {
+		t.Error("CleanModCache without roots succeeded, want error")
+	}
+	if _, err := a.CleanModCache(CleanModCacheRequest{ProjectRoots: []string{project}, Versions: []ModuleVersion{inUse}}); err == nil {
+		t.Error("CleanModCache of a referenced version succeeded, want error")
+	}
+
+	src := filepath.Join(cache, "github.com", "!burnt!sushi", "toml@v1.4.0")
+	if err := os.Chmod(src, 0o555); err != nil {
+		t.Fatal(err)
+	}
+
+	removed, err := a.CleanModCache(CleanModCacheRequest{ProjectRoots: []string{project}, Versions: []ModuleVersion{stale}, DryRun: true})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(removed.Removed) == 0 {
+		t.Fatal("dry run reported nothing to remove")
+	}
+	if _, err := os.Stat(src); err != nil {
+		t.Fatalf("dry run removed %s", src)
+	}
+
+	if _, err := a.CleanModCache(CleanModCacheRequest{ProjectRoots: []string{project}, Versions: []ModuleVersion{stale}}); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := os.Stat(src); !os.IsNotExist(err) {
+		t.Errorf("%s still exists", src)
+	}
+	if _, err := os.Stat(filepath.Join(cache, "github.com", "!burnt!sushi", "toml@v1.5.0")); err != nil {
+		t.Errorf("referenced version was removed: %v", err)
+	}
+
+	list, err := os.ReadFile(filepath.Join(cache, "cache", "download", "github.com", "!burnt!sushi", "toml", "@v", "list"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(list) != "v1.5.0\n" {
+		t.Errorf("list = %q, want %q", list, "v1.5.0\n")
+	}
+}
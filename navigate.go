@@ -0,0 +1,150 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"slices"
+)
+
+type RerootRequest struct {
+	Path string `json:"path"`
+	// Parent re-roots at the folder holding Path instead.
+	Parent bool `json:"parent"`
+	// Depth is how many levels of folders are listed below the new root;
+	// 0 means 1.
+	Depth int `json:"depth"`
+}
+
+type SpliceRootRequest struct {
+	// Subtree is a folder already expanded in the view.
+	Subtree FileNode `json:"subtree"`
+	// Root is the folder, above Subtree, to splice it under.
+	Root string `json:"root"`
+}
+
+// Ancestors returns the folders containing path, from the filesystem root
+// down to its parent.
+func (a *App) Ancestors(req PathRequest) (NodeList, error) {
+	path := filepath.Clean(req.Path)
+	if _, err := os.Lstat(path); err != nil {
+		return NodeList{}, err
+	}
+	chain := []FileNode{}
+	for dir := path; filepath.Dir(dir) != dir; {
+		dir = filepath.Dir(dir)
+		chain = append(chain, folderNode(dir))
+	}
+	slices.Reverse(chain)
+	return NodeList{Nodes: chain}, nil
+}
+
+// Siblings returns the other entries in the folder holding path.
+func (a *App) Siblings(req PathRequest) (NodeList, error) {
+	path := filepath.Clean(req.Path)
+	parent := filepath.Dir(path)
+	if parent == path {
+		return NodeList{Nodes: []FileNode{}}, nil
+	}
+	nodes, err := a.dirs.read(parent)
+	if err != nil {
+		return NodeList{}, err
+	}
+	siblings := make([]FileNode, 0, len(nodes))
+	for _, n := range nodes {
+		if n.Path != path {
+			siblings = append(siblings, n)
+		}
+	}
+	return NodeList{Nodes: siblings}, nil
+}
+
+// Reroot returns the folder at req.Path, or its parent, as the root of a
+// new view, with its contents listed down to req.Depth.
+func (a *App) Reroot(req RerootRequest) (FileNode, error) {
+	path := filepath.Clean(req.Path)
+	if req.Parent {
+		path = filepath.Dir(path)
+	}
+	info, err := os.Stat(path)
+	if err != nil {
+		return FileNode{}, err
+	}
+	if !info.IsDir() {
+		return FileNode{}, fmt.Errorf("%s is not a folder", path)
+	}
+
+	root := folderNode(path)
+	dirs := a.ReadDirs(ReadDirsRequest{Paths: []string{path}, Depth: req.Depth}).Dirs
+	if msg := dirs[path].Error; msg != "" {
+		return FileNode{}, errors.New(msg)
+	}
+	attachListings(&root, dirs)
+	return root, nil
+}
+
+// SpliceRoot places req.Subtree, a folder already expanded in the view,
+// under req.Root, one of its ancestors. Only the folders between the two
+// are read, so what was expanded below the subtree is kept as it is rather
+// than read again. The folders in between come back listed, with their
+// other entries unexpanded.
+func (a *App) SpliceRoot(req SpliceRootRequest) (FileNode, error) {
+	subtree := req.Subtree
+	root := filepath.Clean(req.Root)
+	path := filepath.Clean(subtree.Path)
+	if path == root || !withinRoot(root, path) {
+		return FileNode{}, fmt.Errorf("%s is not above %s", root, path)
+	}
+
+	top := folderNode(root)
+	node := &top
+	for {
+		nodes, err := a.dirs.read(node.Path)
+		if err != nil {
+			return FileNode{}, err
+		}
+		var next *FileNode
+		for _, n := range nodes {
+			c := n
+			if withinRoot(c.Path, path) {
+				if c.Path == path {
+					c = subtree
+				}
+				next = &c
+			}
+			node.Children = append(node.Children, &c)
+		}
+		if next == nil {
+			return FileNode{}, fmt.Errorf("%s: %w", path, os.ErrNotExist)
+		}
+		if next.Path == path {
+			return top, nil
+		}
+		node = next
+	}
+}
+
+// folderNode is the node for the folder at path, named by the path itself
+// at a filesystem root, where it has no base name.
+func folderNode(path string) FileNode {
+	name := filepath.Base(path)
+	if filepath.Dir(path) == path {
+		name = path
+	}
+	return FileNode{Name: name, Path: path, Type: NodeFolder}
+}
+
+// attachListings fills in the children of node and the folders below it
+// from dirs, as returned by ReadDirs.
+func attachListings(node *FileNode, dirs map[string]DirListing) {
+	l, ok := dirs[node.Path]
+	if !ok {
+		return
+	}
+	for _, n := range l.Nodes {
+		c := n
+		attachListings(&c, dirs)
+		node.Children = append(node.Children, &c)
+	}
+}
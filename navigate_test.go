@@ -0,0 +1,200 @@
+package main
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+)
+
+func nodeNames(nodes []FileNode) []string {
+	names := []string{}
+	for _, n := range nodes {
+		names = append(names, n.Name)
+	}
+	return names
+}
+
+func childNames(n *FileNode) []string {
+	names := []string{}
+	for _, c := range n.Children {
+		names = append(names, c.Name)
+	}
+	return names
+}
+
+func TestAncestors(t *testing.T) {
+	root := t.TempDir()
+	writeFiles(t, root, map[string]string{"a/b/c.txt": "x"})
+	fsRoot := filepath.VolumeName(root) + string(filepath.Separator)
+
+	tests := []struct {
+		path string
+		tail []string
+	}{
+		{filepath.Join(root, "a", "b", "c.txt"), []string{filepath.Base(root), "a", "b"}},
+		{filepath.Join(root, "a"), []string{filepath.Base(root)}},
+		{fsRoot, nil},
+	}
+	app := NewApp()
+	for _, tt := range tests {
+		list, err := app.Ancestors(PathRequest{Path: tt.path})
+		if err != nil {
+			t.Fatalf("%s: %v", tt.path, err)
+		}
+		got := list.Nodes
+		if tt.tail == nil {
+			if len(got) != 0 {
+				t.Errorf("%s: ancestors %v, want none", tt.path, nodeNames(got))
+			}
+			continue
+		}
+		if got[0].Path != fsRoot || got[0].Name != fsRoot {
+			t.Errorf("%s: chain starts at %+v", tt.path, got[0])
+		}
+		names := nodeNames(got)
+		if tail := names[len(names)-len(tt.tail):]; !reflect.DeepEqual(tail, tt.tail) {
+			t.Errorf("%s: chain ends %v, want %v", tt.path, tail, tt.tail)
+		}
+		for i := 1; i < len(got); i++ {
+			if filepath.Dir(got[i].Path) != got[i-1].Path || got[i].Type != NodeFolder {
+				t.Errorf("%s: %s doesn't follow %s", tt.path, got[i].Path, got[i-1].Path)
+			}
+		}
+	}
+	if _, err := app.Ancestors(PathRequest{Path: filepath.Join(root, "missing")}); !errors.Is(err, os.ErrNotExist) {
+		t.Errorf("missing path: %v", err)
+	}
+}
+
+func TestSiblings(t *testing.T) {
+	root := t.TempDir()
+	writeFiles(t, root, map[string]string{"a/x": "", "b.txt": "", "c/y": ""})
+	app := NewApp()
+	defer app.dirs.close()
+
+	tests := []struct {
+		path string
+		want []string
+	}{
+		{filepath.Join(root, "b.txt"), []string{"a", "c"}},
+		{filepath.Join(root, "a"), []string{"b.txt", "c"}},
+		{filepath.Join(root, "a", "x"), []string{}},
+	}
+	for _, tt := range tests {
+		got, err := app.Siblings(PathRequest{Path: tt.path})
+		if err != nil {
+			t.Fatalf("%s: %v", tt.path, err)
+		}
+		if names := nodeNames(got.Nodes); !reflect.DeepEqual(names, tt.want) {
+			t.Errorf("%s: siblings %v, want %v", tt.path, names, tt.want)
+		}
+	}
+}
+
+func TestReroot(t *testing.T) {
+	root := t.TempDir()
+	writeFiles(t, root, map[string]string{"a/b/c/d.txt": "x", "a/e.txt": "x"})
+	a := filepath.Join(root, "a")
+	app := NewApp()
+	defer app.dirs.close()
+
+	tests := []struct {
+		name  string
+		req   RerootRequest
+		root  string
+		top   []string
+		depth int
+	}{
+		{"at node", RerootRequest{Path: a}, a, []string{"b", "e.txt"}, 1},
+		{"at parent", RerootRequest{Path: a, Parent: true}, root, []string{"a"}, 1},
+		{"deeper", RerootRequest{Path: a, Depth: 3}, a, []string{"b", "e.txt"}, 3},
+	}
+	for _, tt := range tests {
+		got, err := app.Reroot(tt.req)
+		if err != nil {
+			t.Fatalf("%s: %v", tt.name, err)
+		}
+		if got.Path != tt.root || got.Type != NodeFolder {
+			t.Errorf("%s: root %s, want %s", tt.name, got.Path, tt.root)
+		}
+		if names := childNames(&got); !reflect.DeepEqual(names, tt.top) {
+			t.Errorf("%s: children %v, want %v", tt.name, names, tt.top)
+		}
+		depth := 0
+		for n := &got; len(n.Children) > 0; n = n.Children[0] {
+			depth++
+		}
+		if depth != tt.depth {
+			t.Errorf("%s: expanded %d levels, want %d", tt.name, depth, tt.depth)
+		}
+	}
+	if _, err := app.Reroot(RerootRequest{Path: filepath.Join(a, "e.txt")}); err == nil {
+		t.Error("rerooted at a file")
+	}
+}
+
+func TestSpliceRoot(t *testing.T) {
+	root := t.TempDir()
+	writeFiles(t, root, map[string]string{"a/b/c/d.txt": "x", "a/f.txt": "x", "g/h.txt": "x"})
+	b := filepath.Join(root, "a", "b")
+	app := NewApp()
+	defer app.dirs.close()
+
+	// The expanded subtree carries a child that isn't on disk, which shows
+	// it is kept rather than read again.
+	subtree := FileNode{Name: "b", Path: b, Type: NodeFolder, Children: []*FileNode{
+		{Name: "kept", Path: filepath.Join(b, "kept"), Type: NodeFile},
+	}}
+
+	tests := []struct {
+		name string
+		root string
+		path []string
+		err  bool
+	}{
+		{"parent", filepath.Join(root, "a"), []string{"b"}, false},
+		{"two up", root, []string{"a", "b"}, false},
+		{"same", b, nil, true},
+		{"not above", filepath.Join(root, "g"), nil, true},
+	}
+	for _, tt := range tests {
+		got, err := app.SpliceRoot(SpliceRootRequest{Subtree: subtree, Root: tt.root})
+		if tt.err {
+			if err == nil {
+				t.Errorf("%s: no error", tt.name)
+			}
+			continue
+		}
+		if err != nil {
+			t.Fatalf("%s: %v", tt.name, err)
+		}
+		if got.Path != tt.root {
+			t.Errorf("%s: root %s, want %s", tt.name, got.Path, tt.root)
+		}
+		n := &got
+		for _, name := range tt.path {
+			var next *FileNode
+			for _, c := range n.Children {
+				if c.Name == name {
+					next = c
+				} else if len(c.Children) > 0 {
+					t.Errorf("%s: %s off the path is expanded", tt.name, c.Name)
+				}
+			}
+			if next == nil {
+				t.Fatalf("%s: %s has no %s among %v", tt.name, n.Path, name, childNames(n))
+			}
+			n = next
+		}
+		if names := childNames(n); !reflect.DeepEqual(names, []string{"kept"}) {
+			t.Errorf("%s: spliced subtree has %v", tt.name, names)
+		}
+	}
+
+	gone := FileNode{Name: "gone", Path: filepath.Join(root, "a", "gone"), Type: NodeFolder}
+	if _, err := app.SpliceRoot(SpliceRootRequest{Subtree: gone, Root: root}); !errors.Is(err, os.ErrNotExist) {
+		t.Errorf("missing subtree: %v", err)
+	}
+}
@@ -0,0 +1,238 @@
+package main
+
+import (
+	"encoding/json"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+)
+
+type NpmPackage struct {
+	Name           string   `json:"name"`
+	Version        string   `json:"version"`
+	Path           string   `json:"path"`
+	Size           int64    `json:"size"`
+	DependencyPath []string `json:"dependencyPath"`
+}
+
+type DuplicatePackage struct {
+	Name       string       `json:"name"`
+	Versions   []string     `json:"versions"`
+	Size       int64        `json:"size"`
+	WastedSize int64        `json:"wastedSize"`
+	Copies     []NpmPackage `json:"copies"`
+}
+
+type NodeModulesReport struct {
+	Root       string             `json:"root"`
+	Packages   int                `json:"packages"`
+	Size       int64              `json:"size"`
+	Duplicates []DuplicatePackage `json:"duplicates"`
+}
+
+type packageJSON struct {
+	Name                 string            `json:"name"`
+	Version              string            `json:"version"`
+	Dependencies         map[string]string `json:"dependencies"`
+	DevDependencies      map[string]string `json:"devDependencies"`
+	OptionalDependencies map[string]string `json:"optionalDependencies"`
+	PeerDependencies     map[string]string `json:"peerDependencies"`
+}
+
+type installedPackage struct {
+	NpmPackage
+	deps []string
+}
+
+// AnalyzeNodeModules reads every package.json under req.Root's node_modules,
+// nested installs included, and reports packages present more than once
+// along with the bytes each copy takes. Each copy carries the shortest
+// chain of dependencies from root's package.json that resolves to it.
+func (a *App) AnalyzeNodeModules(req RootRequest) (NodeModulesReport, error) {
+	root := filepath.Clean(req.Root)
+	report := NodeModulesReport{Root: root, Duplicates: []DuplicatePackage{}}
+
+	manifest, err := readPackageJSON(filepath.Join(root, "package.json"))
+	if err != nil {
+		return report, err
+	}
+
+	installed := make(map[string]*installedPackage)
+	scanNodeModules(filepath.Join(root, "node_modules"), installed)
+
+	rootName := manifest.Name
+	if rootName == "" {
+		rootName = filepath.Base(root)
+	}
+	traceDependencyPaths(root, rootName, manifest, installed)
+
+	byName := make(map[string][]NpmPackage)
+	for _, pkg := range installed {
+		report.Packages++
+		report.Size += pkg.Size
+		byName[pkg.Name] = append(byName[pkg.Name], pkg.NpmPackage)
+	}
+
+	for name, copies := range byName {
+		if len(copies) < 2 {
+			continue
+		}
+		dup := DuplicatePackage{Name: name, Copies: copies}
+		versions := make(map[string]bool)
+		var largest int64
+		for _, c := range copies {
+			if !versions[c.Version] {
+				versions[c.Version] = true
+				dup.Versions = append(dup.Versions, c.Version)
+			}
+			dup.Size += c.Size
+			largest = max(largest, c.Size)
+		}
+		dup.WastedSize = dup.Size - largest
+		sort.Strings(dup.Versions)
+		sort.Slice(dup.Copies, func(i, j int) bool { return dup.Copies[i].Path < dup.Copies[j].Path })
+		report.Duplicates = append(report.Duplicates, dup)
+	}
+	sort.Slice(report.Duplicates, func(i, j int) bool {
+		return report.Duplicates[i].WastedSize > report.Duplicates[j].WastedSize
+	})
+	return report, nil
+}
+
+func readPackageJSON(path string) (*packageJSON, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	var pkg packageJSON
+	if err := json.Unmarshal(data, &pkg); err != nil {
+		return nil, err
+	}
+	return &pkg, nil
+}
+
+// scanNodeModules records each package installed in dir, descending into
+// @scope directories and nested node_modules. Symlinked packages, as used
+// by workspaces and pnpm, are recorded but not descended into.
+func scanNodeModules(dir string, installed map[string]*installedPackage) {
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		return
+	}
+
+	for _, e := range entries {
+		name := e.Name()
+		if strings.HasPrefix(name, ".") {
+			continue
+		}
+		path := filepath.Join(dir, name)
+		if strings.HasPrefix(name, "@") && e.IsDir() {
+			scanNodeModules(path, installed)
+			continue
+		}
+
+		pkg, err := readPackageJSON(filepath.Join(path, "package.json"))
+		if err != nil {
+			continue
+		}
+		if pkg.Name == "" {
+			pkg.Name = name
+			if scope := filepath.Base(dir); strings.HasPrefix(scope, "@") {
+				pkg.Name = scope + "/" + name
+			}
+		}
+
+		inst := &installedPackage{NpmPackage: NpmPackage{
+			Name:           pkg.Name,
+			Version:        pkg.Version,
+			Path:           path,
+			DependencyPath: []string{},
+		}}
+		for _, deps := range []map[string]string{pkg.Dependencies, pkg.OptionalDependencies, pkg.PeerDependencies} {
+			for dep := range deps {
+				inst.deps = append(inst.deps, dep)
+			}
+		}
+		sort.Strings(inst.deps)
+		installed[path] = inst
+
+		if e.Type()&os.ModeSymlink != 0 {
+			continue
+		}
+		inst.Size = packageSize(path)
+		scanNodeModules(filepath.Join(path, "node_modules"), installed)
+	}
+}
+
+// packageSize sums a package's files, leaving out its nested node_modules,
+// which hold other packages.
+func packageSize(dir string) int64 {
+	var size int64
+	filepath.WalkDir(dir, func(path string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return nil
+		}
+		if d.IsDir() {
+			if path != dir && d.Name() == "node_modules" {
+				return filepath.SkipDir
+			}
+			return nil
+		}
+		if info, err := d.Info(); err == nil {
+			size += info.Size()
+		}
+		return nil
+	})
+	return size
+}
+
+// traceDependencyPaths walks the dependency graph breadth first from the
+// root manifest, resolving each name the way Node does: the nearest
+// node_modules directory going up from the requiring package.
+func traceDependencyPaths(root, rootName string, manifest *packageJSON, installed map[string]*installedPackage) {
+	type step struct {
+		dir   string
+		chain []string
+		deps  []string
+	}
+
+	var rootDeps []string
+	for _, deps := range []map[string]string{manifest.Dependencies, manifest.DevDependencies, manifest.OptionalDependencies} {
+		for dep := range deps {
+			rootDeps = append(rootDeps, dep)
+		}
+	}
+	sort.Strings(rootDeps)
+
+	visited := make(map[string]bool)
+	queue := []step{{dir: root, chain: []string{rootName}, deps: rootDeps}}
+	for len(queue) > 0 {
+		cur := queue[0]
+		queue = queue[1:]
+
+		for _, dep := range cur.deps {
+			pkg := resolveNodeModule(root, cur.dir, dep, installed)
+			if pkg == nil || visited[pkg.Path] {
+				continue
+			}
+			visited[pkg.Path] = true
+
+			chain := append(cur.chain[:len(cur.chain):len(cur.chain)], pkg.Name+"@"+pkg.Version)
+			pkg.DependencyPath = chain
+			queue = append(queue, step{dir: pkg.Path, chain: chain, deps: pkg.deps})
+		}
+	}
+}
+
+func resolveNodeModule(root, from, name string, installed map[string]*installedPackage) *installedPackage {
+	for dir := from; ; dir = filepath.Dir(dir) {
+		if pkg, ok := installed[filepath.Join(dir, "node_modules", filepath.FromSlash(name))]; ok {
+			return pkg
+		}
+		if dir == root || filepath.Dir(dir) == dir {
+			return nil
+		}
+	}
+}
@@ -0,0 +1,51 @@
+package main
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestAnalyzeNodeModules(t *testing.T) {
+	root := t.TempDir()
+	writeFiles(t, root, map[string]string{
+		"package.json":                                 `{"name":"app","dependencies":{"a":"^1","b":"^1"},"devDependencies":{"@scope/tool":"^3"}}`,
+		"node_modules/a/package.json":                  `{"name":"a","version":"1.0.0","dependencies":{"dup":"^2"}}`,
+		"node_modules/a/node_modules/dup/package.json": `{"name":"dup","version":"2.0.0"}`,
+		"node_modules/a/node_modules/dup/index.js":     "0123456789",
+		"node_modules/b/package.json":                  `{"name":"b","version":"1.0.0","dependencies":{"dup":"^1"}}`,
+		"node_modules/dup/package.json":                `{"name":"dup","version":"1.0.0"}`,
+		"node_modules/dup/index.js":                    "01234",
+		"node_modules/@scope/tool/package.json":        `{"name":"@scope/tool","version":"3.0.0","dependencies":{"dup":"^1"}}`,
+		"node_modules/@scope/tool/node_modules/.bin/x": "",
+		"node_modules/.package-lock.json":              `{}`,
+	})
+
+	report, err := NewApp().AnalyzeNodeModules(RootRequest{Root: root})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if report.Packages != 5 {
+		t.Errorf("packages = %d, want 5", report.Packages)
+	}
+	if len(report.Duplicates) != 1 {
+		t.Fatalf("duplicates = %+v, want only dup", report.Duplicates)
+	}
+
+	dup := report.Duplicates[0]
+	if dup.Name != "dup" || !reflect.DeepEqual(dup.Versions, []string{"1.0.0", "2.0.0"}) {
+		t.Errorf("duplicate = %s %v, want dup [1.0.0 2.0.0]", dup.Name, dup.Versions)
+	}
+
+	paths := map[string][]string{
+		"1.0.0": {"app", "@scope/tool@3.0.0", "dup@1.0.0"},
+		"2.0.0": {"app", "a@1.0.0", "dup@2.0.0"},
+	}
+	for _, c := range dup.Copies {
+		if want := paths[c.Version]; !reflect.DeepEqual(c.DependencyPath, want) {
+			t.Errorf("dup@%s path = %v, want %v", c.Version, c.DependencyPath, want)
+		}
+	}
+	if dup.WastedSize != dup.Size-dup.Copies[0].Size && dup.WastedSize != dup.Size-dup.Copies[1].Size {
+		t.Errorf("wasted size %d is not total %d minus one copy", dup.WastedSize, dup.Size)
+	}
+}
@@ -0,0 +1,235 @@
+package main
+
+import (
+	"fmt"
+	"os"
+	"strings"
+)
+
+type TextFileReport struct {
+	Path     string `json:"path"`
+	Encoding string `json:"encoding"`
+	// LineEnding is "lf", "crlf", "cr", "mixed", or "none" for a file
+	// without line breaks.
+	LineEnding string `json:"lineEnding"`
+	LF         int    `json:"lf"`
+	CRLF       int    `json:"crlf"`
+	CR         int    `json:"cr"`
+	// TrailingWhitespace counts lines ending in spaces or tabs.
+	TrailingWhitespace int  `json:"trailingWhitespace"`
+	FinalNewline       bool `json:"finalNewline"`
+}
+
+type TextReport struct {
+	Files []TextFileReport `json:"files"`
+	// Binary counts files that were skipped as not text.
+	Binary int `json:"binary"`
+}
+
+type NormalizeRequest struct {
+	Root  string   `json:"root"`
+	Paths []string `json:"paths"`
+	// Encoding converts files to this encoding, written as in
+	// TextFileReport, e.g. "utf-8" or "utf-16le (BOM)". Empty keeps each
+	// file's own.
+	Encoding string `json:"encoding"`
+	// LineEnding is "lf" or "crlf", or empty to leave line breaks alone.
+	LineEnding         string `json:"lineEnding"`
+	TrimTrailing       bool   `json:"trimTrailing"`
+	EnsureFinalNewline bool   `json:"ensureFinalNewline"`
+	// DryRun reports what would change without writing.
+	DryRun bool `json:"dryRun"`
+}
+
+type NormalizeResult struct {
+	Changed []string `json:"changed"`
+	Errors  []string `json:"errors"`
+}
+
+// InspectText reports the encoding, line endings and whitespace problems of
+// every text file under req.Root.
+func (a *App) InspectText(req RootRequest) (TextReport, error) {
+	report := TextReport{Files: []TextFileReport{}}
+	err := walkTextFiles(req.Root, nil, func(path string) {
+		data, err := os.ReadFile(path)
+		if err != nil {
+			return
+		}
+		text, enc, ok := decodeText(data)
+		if !ok {
+			report.Binary++
+			return
+		}
+		f := inspectText(text)
+		f.Path = path
+		f.Encoding = enc.String()
+		report.Files = append(report.Files, f)
+	})
+	return report, err
+}
+
+// NormalizeText rewrites the text files selected by req to a consistent
+// encoding, line ending and whitespace. Files already in shape are left
+// untouched.
+func (a *App) NormalizeText(req NormalizeRequest) (NormalizeResult, error) {
+	result := NormalizeResult{Changed: []string{}, Errors: []string{}}
+	var target *textEncoding
+	if req.Encoding != "" {
+		enc, err := parseTextEncoding(req.Encoding)
+		if err != nil {
+			return result, err
+		}
+		target = &enc
+	}
+	eol := ""
+	switch req.LineEnding {
+	case "":
+	case "lf":
+		eol = "\n"
+	case "crlf":
+		eol = "\r\n"
+	default:
+		return result, fmt.Errorf("unknown line ending %q", req.LineEnding)
+	}
+
+	err := walkTextFiles(req.Root, req.Paths, func(path string) {
+		data, err := os.ReadFile(path)
+		if err != nil {
+			result.Errors = append(result.Errors, path+": "+err.Error())
+			return
+		}
+		text, enc, ok := decodeText(data)
+		if !ok {
+			return
+		}
+		if target != nil {
+			enc = *target
+		}
+		out, err := encodeText(normalizeText(text, eol, req.TrimTrailing, req.EnsureFinalNewline), enc)
+		if err != nil {
+			result.Errors = append(result.Errors, path+": "+err.Error())
+			return
+		}
+		if string(out) == string(data) {
+			return
+		}
+		if !req.DryRun {
+			if err := replaceFileContents(path, out); err != nil {
+				result.Errors = append(result.Errors, path+": "+err.Error())
+				return
+			}
+		}
+		result.Changed = append(result.Changed, path)
+	})
+	return result, err
+}
+
+func parseTextEncoding(s string) (textEncoding, error) {
+	name, bom := strings.CutSuffix(s, " (BOM)")
+	switch name {
+	case "utf-8", "utf-16le", "utf-16be":
+	case "latin-1":
+		if bom {
+			return textEncoding{}, fmt.Errorf("latin-1 has no byte order mark")
+		}
+	default:
+		return textEncoding{}, fmt.Errorf("unknown encoding %q", s)
+	}
+	return textEncoding{name, bom}, nil
+}
+
+// textLine is one line of a text split on any of "\n", "\r\n" or "\r".
+type textLine struct {
+	body, eol string
+}
+
+func textLines(text string) []textLine {
+	var lines []textLine
+	for len(text) > 0 {
+		i := strings.IndexAny(text, "\r\n")
+		if i < 0 {
+			lines = append(lines, textLine{text, ""})
+			break
+		}
+		n := 1
+		if text[i] == '\r' && i+1 < len(text) && text[i+1] == '\n' {
+			n = 2
+		}
+		lines = append(lines, textLine{text[:i], text[i : i+n]})
+		text = text[i+n:]
+	}
+	return lines
+}
+
+func inspectText(text string) TextFileReport {
+	var r TextFileReport
+	lines := textLines(text)
+	for _, l := range lines {
+		switch l.eol {
+		case "\n":
+			r.LF++
+		case "\r\n":
+			r.CRLF++
+		case "\r":
+			r.CR++
+		}
+		if strings.TrimRight(l.body, " \t") != l.body {
+			r.TrailingWhitespace++
+		}
+	}
+	r.FinalNewline = len(lines) == 0 || lines[len(lines)-1].eol != ""
+
+	kinds := 0
+	for _, n := range []int{r.LF, r.CRLF, r.CR} {
+		if n > 0 {
+			kinds++
+		}
+	}
+	switch {
+	case kinds == 0:
+		r.LineEnding = "none"
+	case kinds > 1:
+		r.LineEnding = "mixed"
+	case r.LF > 0:
+		r.LineEnding = "lf"
+	case r.CRLF > 0:
+		r.LineEnding = "crlf"
+	default:
+		r.LineEnding = "cr"
+	}
+	return r
+}
+
+// normalizeText rewrites every line break as eol, unless it's empty, strips
+// trailing spaces and tabs when trim is set, and ends non-empty text with a
+// line break when final is set.
+func normalizeText(text, eol string, trim, final bool) string {
+	lines := textLines(text)
+	last := eol
+	if last == "" {
+		// A missing final newline is added in the file's own style.
+		last = "\n"
+		if r := inspectText(text); r.CRLF > r.LF && r.CRLF >= r.CR {
+			last = "\r\n"
+		} else if r.CR > r.LF && r.CR > r.CRLF {
+			last = "\r"
+		}
+	}
+
+	var sb strings.Builder
+	for i, l := range lines {
+		if trim {
+			l.body = strings.TrimRight(l.body, " \t")
+		}
+		sb.WriteString(l.body)
+		switch {
+		case l.eol != "" && eol != "":
+			sb.WriteString(eol)
+		case l.eol != "":
+			sb.WriteString(l.eol)
+		case final && i == len(lines)-1:
+			sb.WriteString(last)
+		}
+	}
+	return sb.String()
+}
@@ -0,0 +1,105 @@
+package main
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestInspectText(t *testing.T) {
+	tests := []struct {
+		text         string
+		ending       string
+		lf, crlf, cr int
+		trailing     int
+		finalNewline bool
+	}{
+		{"", "none", 0, 0, 0, 0, true},
+		{"one line", "none", 0, 0, 0, 0, false},
+		{"a\nb\n", "lf", 2, 0, 0, 0, true},
+		{"a\r\nb \r\n", "crlf", 0, 2, 0, 1, true},
+		{"a\rb", "cr", 0, 0, 1, 0, false},
+		{"a\t\nb\r\nc  ", "mixed", 1, 1, 0, 2, false},
+	}
+	for _, tt := range tests {
+		r := inspectText(tt.text)
+		if r.LineEnding != tt.ending || r.LF != tt.lf || r.CRLF != tt.crlf || r.CR != tt.cr ||
+			r.TrailingWhitespace != tt.trailing || r.FinalNewline != tt.finalNewline {
+			t.Errorf("inspectText(%q) = %+v", tt.text, r)
+		}
+	}
+}
+
+func TestNormalizeText(t *testing.T) {
+	tests := []struct {
+		text, eol   string
+		trim, final bool
+		want        string
+	}{
+		{"a\r\nb\rc\n", "\n", false, false, "a\nb\nc\n"},
+		{"a\nb", "\r\n", false, true, "a\r\nb\r\n"},
+		{"a \nb\t", "", true, false, "a\nb"},
+		{"a\r\nb", "", false, true, "a\r\nb\r\n"},
+		{"a", "", false, true, "a\n"},
+		{"", "\n", true, true, ""},
+	}
+	for _, tt := range tests {
+		if got := normalizeText(tt.text, tt.eol, tt.trim, tt.final); got != tt.want {
+			t.Errorf("normalizeText(%q, %q, %v, %v) = %q, want %q", tt.text, tt.eol, tt.trim, tt.final, got, tt.want)
+		}
+	}
+}
+
+func TestNormalizeFiles(t *testing.T) {
+	root := t.TempDir()
+	writeFiles(t, root, map[string]string{
+		"dos.txt":    "one \r\ntwo\r\n",
+		"latin.txt":  "caf\xe9",
+		"utf16.txt":  "\xff\xfea\x00\r\x00\n\x00",
+		"clean.txt":  "fine\n",
+		"binary.dat": "\x00\x01",
+		"image.png":  "\x89PNG\r\n\x1a\n\xff\xd8 \n",
+	})
+	a := NewApp()
+
+	report, err := a.InspectText(RootRequest{Root: root})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(report.Files) != 4 || report.Binary != 2 {
+		t.Fatalf("report has %d files, %d binary", len(report.Files), report.Binary)
+	}
+
+	req := NormalizeRequest{Root: root, Encoding: "utf-8", LineEnding: "lf", TrimTrailing: true, EnsureFinalNewline: true, DryRun: true}
+	dry, err := a.NormalizeText(req)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(dry.Changed) != 3 {
+		t.Errorf("dry run would change %v", dry.Changed)
+	}
+	if got, _ := os.ReadFile(filepath.Join(root, "dos.txt")); string(got) != "one \r\ntwo\r\n" {
+		t.Errorf("dry run wrote dos.txt: %q", got)
+	}
+
+	req.DryRun = false
+	if _, err := a.NormalizeText(req); err != nil {
+		t.Fatal(err)
+	}
+	want := map[string]string{
+		"dos.txt":   "one\ntwo\n",
+		"latin.txt": "café\n",
+		"utf16.txt": "a\n",
+		"clean.txt": "fine\n",
+		"image.png": "\x89PNG\r\n\x1a\n\xff\xd8 \n",
+	}
+	for name, content := range want {
+		if got, _ := os.ReadFile(filepath.Join(root, name)); string(got) != content {
+			t.Errorf("%s = %q, want %q", name, got, content)
+		}
+	}
+
+	if _, err := a.NormalizeText(NormalizeRequest{Root: root, Encoding: "ebcdic"}); err == nil {
+		t.Error("unknown encoding succeeded, want error")
+	}
+}
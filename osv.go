@@ -0,0 +1,261 @@
+package main
+
+import (
+	"archive/zip"
+	"encoding/json"
+	"io"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"slices"
+	"sort"
+	"strings"
+
+	"golang.org/x/mod/semver"
+)
+
+type Advisory struct {
+	ID        string   `json:"id"`
+	Aliases   []string `json:"aliases,omitempty"`
+	Summary   string   `json:"summary,omitempty"`
+	Package   string   `json:"package"`
+	Version   string   `json:"version"`
+	Ecosystem string   `json:"ecosystem"`
+	Fixed     []string `json:"fixed"`
+	Source    string   `json:"source"`
+}
+
+type AuditRequest struct {
+	Root string `json:"root"`
+	// DBPath is an OSV database export: a directory of advisory JSON
+	// files, or a zip of them.
+	DBPath string `json:"dbPath"`
+}
+
+type VulnReport struct {
+	Root         string     `json:"root"`
+	Dependencies int        `json:"dependencies"`
+	Advisories   []Advisory `json:"advisories"`
+	// Nodes maps each file that pulls in an affected dependency, and every
+	// directory above it up to root, to the IDs of the advisories found.
+	Nodes map[string][]string `json:"nodes"`
+}
+
+type osvRecord struct {
+	ID        string   `json:"id"`
+	Aliases   []string `json:"aliases"`
+	Summary   string   `json:"summary"`
+	Withdrawn string   `json:"withdrawn"`
+	Affected  []struct {
+		Package struct {
+			Ecosystem string `json:"ecosystem"`
+			Name      string `json:"name"`
+		} `json:"package"`
+		Ranges []struct {
+			Type   string              `json:"type"`
+			Events []map[string]string `json:"events"`
+		} `json:"ranges"`
+		Versions []string `json:"versions"`
+	} `json:"affected"`
+}
+
+// AuditDependencies matches the dependencies found under req.Root, as
+// ScanDependencies lists them, against the OSV database export at
+// req.DBPath, as published per ecosystem by osv.dev. Go modules are those
+// in the build, so a version go.sum merely mentions isn't reported.
+// Nothing is fetched from the network.
+func (a *App) AuditDependencies(req AuditRequest) (VulnReport, error) {
+	root, dbPath := filepath.Clean(req.Root), req.DBPath
+	report := VulnReport{Root: root, Advisories: []Advisory{}, Nodes: map[string][]string{}}
+
+	deps, err := collectDependencies(root)
+	if err != nil {
+		return report, err
+	}
+	report.Dependencies = len(deps)
+
+	type pkgKey struct{ ecosystem, name string }
+	wanted := make(map[pkgKey][]Dependency)
+	for _, d := range deps {
+		k := pkgKey{d.Ecosystem, d.Name}
+		wanted[k] = append(wanted[k], d)
+	}
+
+	err = readOSVDatabase(dbPath, func(rec *osvRecord) {
+		if rec.Withdrawn != "" {
+			return
+		}
+		for _, aff := range rec.Affected {
+			for _, d := range wanted[pkgKey{aff.Package.Ecosystem, aff.Package.Name}] {
+				affected := false
+				for _, v := range aff.Versions {
+					if osvVersionCompare(d.Version, v) == 0 {
+						affected = true
+					}
+				}
+				var fixed []string
+				for _, r := range aff.Ranges {
+					if r.Type != "SEMVER" && r.Type != "ECOSYSTEM" {
+						continue
+					}
+					if in, fix := osvRangeAffects(r.Events, d.Version); in {
+						affected = true
+						if fix != "" && !slices.Contains(fixed, fix) {
+							fixed = append(fixed, fix)
+						}
+					}
+				}
+				if !affected {
+					continue
+				}
+
+				sort.Slice(fixed, func(i, j int) bool { return osvVersionCompare(fixed[i], fixed[j]) < 0 })
+				report.Advisories = append(report.Advisories, Advisory{
+					ID:        rec.ID,
+					Aliases:   rec.Aliases,
+					Summary:   rec.Summary,
+					Package:   d.Name,
+					Version:   d.Version,
+					Ecosystem: d.Ecosystem,
+					Fixed:     append([]string{}, fixed...),
+					Source:    d.Source,
+				})
+			}
+		}
+	})
+	if err != nil {
+		return report, err
+	}
+
+	sort.Slice(report.Advisories, func(i, j int) bool {
+		x, y := report.Advisories[i], report.Advisories[j]
+		if x.Package != y.Package {
+			return x.Package < y.Package
+		}
+		return x.ID < y.ID
+	})
+	for _, adv := range report.Advisories {
+		for p := adv.Source; ; p = filepath.Dir(p) {
+			report.Nodes[p] = append(report.Nodes[p], adv.ID)
+			if p == root || filepath.Dir(p) == p {
+				break
+			}
+		}
+	}
+	for p, ids := range report.Nodes {
+		slices.Sort(ids)
+		report.Nodes[p] = slices.Compact(ids)
+	}
+	return report, nil
+}
+
+func readOSVDatabase(dbPath string, fn func(*osvRecord)) error {
+	info, err := os.Stat(dbPath)
+	if err != nil {
+		return err
+	}
+
+	decode := func(r io.Reader) {
+		var rec osvRecord
+		if json.NewDecoder(r).Decode(&rec) == nil && rec.ID != "" {
+			fn(&rec)
+		}
+	}
+
+	if info.IsDir() {
+		return filepath.WalkDir(dbPath, func(path string, d fs.DirEntry, err error) error {
+			if err != nil || d.IsDir() || filepath.Ext(path) != ".json" {
+				return nil
+			}
+			f, err := os.Open(path)
+			if err != nil {
+				return nil
+			}
+			defer f.Close()
+			decode(f)
+			return nil
+		})
+	}
+
+	zr, err := zip.OpenReader(dbPath)
+	if err != nil {
+		return err
+	}
+	defer zr.Close()
+	for _, zf := range zr.File {
+		if filepath.Ext(zf.Name) != ".json" {
+			continue
+		}
+		r, err := zf.Open()
+		if err != nil {
+			continue
+		}
+		decode(r)
+		r.Close()
+	}
+	return nil
+}
+
+// osvRangeAffects reports whether version falls inside a range given as
+// introduced, fixed and last_affected events and, if it does, the version
+// that fixed it: the first fixed event after the one that introduced it,
+// or "" when that part of the range ends with last_affected or not at all.
+func osvRangeAffects(events []map[string]string, version string) (bool, string) {
+	type event struct{ kind, version string }
+	var sorted []event
+	for _, e := range events {
+		for kind, v := range e {
+			sorted = append(sorted, event{kind, v})
+		}
+	}
+	sort.SliceStable(sorted, func(i, j int) bool {
+		return osvVersionCompare(sorted[i].version, sorted[j].version) < 0
+	})
+
+	affected := false
+	for i, e := range sorted {
+		c := osvVersionCompare(version, e.version)
+		if c < 0 {
+			if !affected {
+				return false, ""
+			}
+			for _, e := range sorted[i:] {
+				switch e.kind {
+				case "fixed":
+					return true, e.version
+				case "last_affected":
+					return true, ""
+				}
+			}
+			return true, ""
+		}
+		switch e.kind {
+		case "introduced":
+			affected = true
+		case "fixed":
+			affected = false
+		case "last_affected":
+			if c == 0 && affected {
+				return true, ""
+			}
+			affected = false
+		}
+	}
+	return affected, ""
+}
+
+// osvVersionCompare compares semantic versions with or without the "v"
+// prefix Go uses. "0" sorts before every version, as OSV uses it for
+// "since the first release".
+func osvVersionCompare(a, b string) int {
+	if a == b {
+		return 0
+	}
+	if a == "0" {
+		return -1
+	}
+	if b == "0" {
+		return 1
+	}
+	return semver.Compare("v"+strings.TrimPrefix(a, "v"), "v"+strings.TrimPrefix(b, "v"))
+}
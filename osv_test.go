@@ -0,0 +1,111 @@
+package main
+
+import (
+	"archive/zip"
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+)
+
+var testOSVRecords = map[string]string{
+	"GO-2024-0001.json": `{"id": "GO-2024-0001", "aliases": ["CVE-2024-1"], "affected": [{
+		"package": {"ecosystem": "Go", "name": "github.com/BurntSushi/toml"},
+		"ranges": [{"type": "SEMVER", "events": [{"introduced": "0"}, {"fixed": "1.2.0"}, {"introduced": "1.4.0"}, {"fixed": "1.6.0"}, {"introduced": "2.0.0"}, {"fixed": "2.3.0"}]}]}]}`,
+	"GO-2024-0002.json": `{"id": "GO-2024-0002", "affected": [{
+		"package": {"ecosystem": "Go", "name": "github.com/BurntSushi/toml"},
+		"ranges": [{"type": "SEMVER", "events": [{"introduced": "0"}, {"fixed": "1.5.0"}]}]}]}`,
+	"GHSA-aaaa.json": `{"id": "GHSA-aaaa", "affected": [{
+		"package": {"ecosystem": "npm", "name": "react"},
+		"ranges": [{"type": "SEMVER", "events": [{"introduced": "17.0.0"}, {"last_affected": "17.0.2"}]}]}]}`,
+	"GHSA-bbbb.json": `{"id": "GHSA-bbbb", "affected": [{
+		"package": {"ecosystem": "npm", "name": "@types/node"},
+		"versions": ["20.1.0"]}]}`,
+	"GHSA-withdrawn.json": `{"id": "GHSA-withdrawn", "withdrawn": "2024-01-01T00:00:00Z", "affected": [{
+		"package": {"ecosystem": "npm", "name": "react"},
+		"ranges": [{"type": "SEMVER", "events": [{"introduced": "0"}]}]}]}`,
+}
+
+func TestAuditDependencies(t *testing.T) {
+	root := t.TempDir()
+	writeFiles(t, root, map[string]string{
+		"go.mod":                "module example\n\ngo 1.23\n\nrequire github.com/BurntSushi/toml v1.5.0\n",
+		"go.sum":                "github.com/BurntSushi/toml v1.1.0 h1:abc=\n",
+		"web/package-lock.json": testPackageLock,
+	})
+
+	dbDir := t.TempDir()
+	writeFiles(t, dbDir, testOSVRecords)
+
+	dbZip := filepath.Join(t.TempDir(), "all.zip")
+	f, err := os.Create(dbZip)
+	if err != nil {
+		t.Fatal(err)
+	}
+	zw := zip.NewWriter(f)
+	for name, content := range testOSVRecords {
+		w, err := zw.Create(name)
+		if err != nil {
+			t.Fatal(err)
+		}
+		w.Write([]byte(content))
+	}
+	zw.Close()
+	f.Close()
+
+	want := []Advisory{
+		{ID: "GHSA-bbbb", Package: "@types/node", Version: "20.1.0", Ecosystem: "npm", Fixed: []string{}, Source: filepath.Join(root, "web", "package-lock.json")},
+		{ID: "GO-2024-0001", Aliases: []string{"CVE-2024-1"}, Package: "github.com/BurntSushi/toml", Version: "v1.5.0", Ecosystem: "Go", Fixed: []string{"1.6.0"}, Source: filepath.Join(root, "go.mod")},
+		{ID: "GHSA-aaaa", Package: "react", Version: "17.0.2", Ecosystem: "npm", Fixed: []string{}, Source: filepath.Join(root, "web", "package-lock.json")},
+	}
+
+	for _, db := range []string{dbDir, dbZip} {
+		report, err := NewApp().AuditDependencies(AuditRequest{Root: root, DBPath: db})
+		if err != nil {
+			t.Fatal(err)
+		}
+		if !reflect.DeepEqual(report.Advisories, want) {
+			t.Errorf("%s: advisories = %+v, want %+v", db, report.Advisories, want)
+		}
+		if got := report.Nodes[filepath.Join(root, "web")]; !reflect.DeepEqual(got, []string{"GHSA-aaaa", "GHSA-bbbb"}) {
+			t.Errorf("%s: web node = %v", db, got)
+		}
+		if got := report.Nodes[root]; len(got) != 3 {
+			t.Errorf("%s: root node = %v, want all three advisories", db, got)
+		}
+	}
+
+	if _, err := NewApp().AuditDependencies(AuditRequest{Root: root, DBPath: filepath.Join(dbDir, "missing")}); err == nil {
+		t.Error("missing database succeeded, want error")
+	}
+}
+
+func TestOSVRangeAffects(t *testing.T) {
+	events := []map[string]string{
+		{"introduced": "1.0.0"}, {"fixed": "1.2.0"},
+		{"introduced": "2.0.0"}, {"last_affected": "2.1.0"},
+		{"introduced": "3.0.0"}, {"fixed": "3.1.0"},
+		{"introduced": "4.0.0"},
+	}
+	tests := []struct {
+		version string
+		want    bool
+		fixed   string
+	}{
+		{"0.9.0", false, ""},
+		{"v1.0.0", true, "1.2.0"},
+		{"1.1.9", true, "1.2.0"},
+		{"1.2.0", false, ""},
+		{"2.0.0-rc.1", false, ""},
+		{"2.1.0", true, ""},
+		{"2.1.1", false, ""},
+		{"3.0.5", true, "3.1.0"},
+		{"4.2.0", true, ""},
+	}
+	for _, tt := range tests {
+		got, fixed := osvRangeAffects(events, tt.version)
+		if got != tt.want || fixed != tt.fixed {
+			t.Errorf("osvRangeAffects(%s) = %v, %q, want %v, %q", tt.version, got, fixed, tt.want, tt.fixed)
+		}
+	}
+}
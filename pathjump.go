@@ -0,0 +1,392 @@
+package main
+
+import (
+	"io/fs"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strings"
+	"unicode"
+)
+
+type PathCompletion struct {
+	Name  string `json:"name"`
+	Path  string `json:"path"`
+	IsDir bool   `json:"isDir"`
+}
+
+type FuzzyMatch struct {
+	Path string `json:"path"`
+	// Rel is Path relative to the indexed root; Positions are the indexes
+	// of its matched runes, for highlighting.
+	Rel       string `json:"rel"`
+	Score     int    `json:"score"`
+	Positions []int  `json:"positions"`
+}
+
+type CompletePathRequest struct {
+	// Partial is the path typed so far.
+	Partial string `json:"partial"`
+}
+
+type PathCompletions struct {
+	Completions []PathCompletion `json:"completions"`
+}
+
+type IndexPathsResult struct {
+	// Count is how many files and directories were indexed.
+	Count int `json:"count"`
+}
+
+type FuzzyFindRequest struct {
+	Root  string `json:"root"`
+	Query string `json:"query"`
+	// Limit bounds the matches returned; 0 means 50.
+	Limit int `json:"limit"`
+}
+
+type FuzzyMatches struct {
+	Matches []FuzzyMatch `json:"matches"`
+}
+
+const maxCompletions = 200
+
+// CompletePath lists the entries that complete req.Partial, after expanding a
+// leading ~ and $VAR, ${VAR} or %VAR% references. A partial ending in a
+// separator lists that directory. Hidden entries are only offered when the
+// typed name starts with a dot. Completions matching case exactly come
+// first.
+func (a *App) CompletePath(req CompletePathRequest) (PathCompletions, error) {
+	partial := req.Partial
+	expanded := expandPath(partial)
+	dir, prefix := filepath.Split(expanded)
+	if dir == "" {
+		dir = "."
+	}
+
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		return PathCompletions{}, err
+	}
+
+	type candidate struct {
+		PathCompletion
+		exact bool
+	}
+	var found []candidate
+	for _, e := range entries {
+		name := e.Name()
+		if strings.HasPrefix(name, ".") && !strings.HasPrefix(prefix, ".") {
+			continue
+		}
+		exact := strings.HasPrefix(name, prefix)
+		if !exact && !strings.HasPrefix(strings.ToLower(name), strings.ToLower(prefix)) {
+			continue
+		}
+		c := candidate{PathCompletion{Name: name, Path: filepath.Join(dir, name)}, exact}
+		if e.IsDir() {
+			c.IsDir = true
+		} else if e.Type()&os.ModeSymlink != 0 {
+			if info, err := os.Stat(c.Path); err == nil && info.IsDir() {
+				c.IsDir = true
+			}
+		}
+		if c.IsDir {
+			c.Path += string(filepath.Separator)
+		}
+		found = append(found, c)
+	}
+
+	sort.SliceStable(found, func(i, j int) bool {
+		if found[i].exact != found[j].exact {
+			return found[i].exact
+		}
+		return strings.ToLower(found[i].Name) < strings.ToLower(found[j].Name)
+	})
+
+	completions := []PathCompletion{}
+	for i, c := range found {
+		if i == maxCompletions {
+			break
+		}
+		completions = append(completions, c.PathCompletion)
+	}
+	return PathCompletions{Completions: completions}, nil
+}
+
+var windowsEnvRef = regexp.MustCompile(`%([A-Za-z_][A-Za-z0-9_]*)%`)
+
+func expandPath(p string) string {
+	if p == "~" || strings.HasPrefix(p, "~/") || strings.HasPrefix(p, `~\`) {
+		if home, err := os.UserHomeDir(); err == nil {
+			p = home + p[1:]
+		}
+	}
+	p = windowsEnvRef.ReplaceAllStringFunc(p, func(ref string) string {
+		if v, ok := os.LookupEnv(ref[1 : len(ref)-1]); ok {
+			return v
+		}
+		return ref
+	})
+	return os.ExpandEnv(p)
+}
+
+// IndexPaths records every file and directory under req.Root, .git
+// directories aside, for FuzzyFind, and returns how many there are.
+func (a *App) IndexPaths(req RootRequest) (IndexPathsResult, error) {
+	root := filepath.Clean(expandPath(req.Root))
+	var paths []string
+	err := filepath.WalkDir(root, func(path string, d fs.DirEntry, err error) error {
+		if err != nil {
+			if path == root {
+				return err
+			}
+			return nil
+		}
+		if path == root {
+			return nil
+		}
+		if d.IsDir() && d.Name() == ".git" {
+			return filepath.SkipDir
+		}
+		rel, _ := filepath.Rel(root, path)
+		paths = append(paths, filepath.ToSlash(rel))
+		return nil
+	})
+	if err != nil {
+		return IndexPathsResult{}, err
+	}
+
+	a.pathIndexMu.Lock()
+	if a.pathIndex == nil {
+		a.pathIndex = make(map[string][]string)
+	}
+	a.pathIndex[root] = paths
+	a.pathIndexMu.Unlock()
+	return IndexPathsResult{Count: len(paths)}, nil
+}
+
+// FuzzyFind ranks the indexed paths under req.Root against req.Query,
+// indexing the root first if needed. Space-separated terms must all
+// match. Matching is case-insensitive unless the query has an upper-case
+// letter.
+func (a *App) FuzzyFind(req FuzzyFindRequest) (FuzzyMatches, error) {
+	query, limit := req.Query, req.Limit
+	root := filepath.Clean(expandPath(req.Root))
+	a.pathIndexMu.Lock()
+	paths, ok := a.pathIndex[root]
+	a.pathIndexMu.Unlock()
+	if !ok {
+		if _, err := a.IndexPaths(RootRequest{Root: root}); err != nil {
+			return FuzzyMatches{}, err
+		}
+		a.pathIndexMu.Lock()
+		paths = a.pathIndex[root]
+		a.pathIndexMu.Unlock()
+	}
+	if limit <= 0 {
+		limit = 50
+	}
+
+	terms := strings.Fields(query)
+	caseSensitive := strings.IndexFunc(query, unicode.IsUpper) >= 0
+	var patterns [][]rune
+	for _, t := range terms {
+		if !caseSensitive {
+			t = strings.ToLower(t)
+		}
+		patterns = append(patterns, []rune(t))
+	}
+
+	matches := []FuzzyMatch{}
+	for _, rel := range paths {
+		text := []rune(rel)
+		m := FuzzyMatch{Path: filepath.Join(root, filepath.FromSlash(rel)), Rel: rel, Positions: []int{}}
+		ok := true
+		for _, pattern := range patterns {
+			score, positions, found := fuzzyMatch(text, pattern, caseSensitive)
+			if !found {
+				ok = false
+				break
+			}
+			m.Score += score
+			m.Positions = append(m.Positions, positions...)
+		}
+		if ok {
+			sort.Ints(m.Positions)
+			matches = append(matches, m)
+		}
+	}
+
+	sort.SliceStable(matches, func(i, j int) bool {
+		if matches[i].Score != matches[j].Score {
+			return matches[i].Score > matches[j].Score
+		}
+		return len(matches[i].Rel) < len(matches[j].Rel)
+	})
+	if len(matches) > limit {
+		matches = matches[:limit]
+	}
+	return FuzzyMatches{Matches: matches}, nil
+}
+
+// Scoring follows fzf: every matched character scores, gaps cost, and
+// characters at word boundaries, after path separators or at camelCase
+// humps earn bonuses that carry over to the consecutive matches after them.
+const (
+	scoreMatch             = 16
+	scoreGapStart          = -3
+	scoreGapExtension      = -1
+	bonusBoundary          = scoreMatch / 2
+	bonusNonWord           = scoreMatch / 2
+	bonusCamel123          = bonusBoundary + scoreGapExtension
+	bonusConsecutive       = -(scoreGapStart + scoreGapExtension)
+	bonusFirstCharMultiple = 2
+	bonusBoundaryWhite     = bonusBoundary + 2
+	bonusBoundaryDelimiter = bonusBoundary + 1
+)
+
+type charClass int
+
+const (
+	charWhite charClass = iota
+	charNonWord
+	charDelimiter
+	charLower
+	charUpper
+	charLetter
+	charNumber
+)
+
+func classOf(r rune) charClass {
+	switch {
+	case r >= 'a' && r <= 'z':
+		return charLower
+	case r >= 'A' && r <= 'Z':
+		return charUpper
+	case r >= '0' && r <= '9':
+		return charNumber
+	case unicode.IsSpace(r):
+		return charWhite
+	case strings.ContainsRune(`/\,:;|`, r):
+		return charDelimiter
+	case unicode.IsLower(r):
+		return charLower
+	case unicode.IsUpper(r):
+		return charUpper
+	case unicode.IsLetter(r):
+		return charLetter
+	case unicode.IsNumber(r):
+		return charNumber
+	}
+	return charNonWord
+}
+
+func bonusFor(prev, class charClass) int {
+	if class > charDelimiter {
+		switch prev {
+		case charWhite:
+			return bonusBoundaryWhite
+		case charDelimiter:
+			return bonusBoundaryDelimiter
+		case charNonWord:
+			return bonusBoundary
+		}
+	}
+	if prev == charLower && class == charUpper || prev != charNumber && class == charNumber {
+		return bonusCamel123
+	}
+	switch class {
+	case charNonWord, charDelimiter:
+		return bonusNonWord
+	case charWhite:
+		return bonusBoundaryWhite
+	}
+	return 0
+}
+
+// fuzzyMatch finds pattern as a subsequence of text the way fzf's v1
+// algorithm does: scan forward for the first occurrence, then backward
+// from its end for the shortest window, and score that window.
+func fuzzyMatch(text, pattern []rune, caseSensitive bool) (int, []int, bool) {
+	if len(pattern) == 0 {
+		return 0, nil, true
+	}
+	fold := func(r rune) rune {
+		if caseSensitive {
+			return r
+		}
+		return unicode.ToLower(r)
+	}
+
+	pidx, end := 0, -1
+	for i, r := range text {
+		if fold(r) == pattern[pidx] {
+			pidx++
+			if pidx == len(pattern) {
+				end = i + 1
+				break
+			}
+		}
+	}
+	if end < 0 {
+		return 0, nil, false
+	}
+
+	start := 0
+	pidx = len(pattern) - 1
+	for i := end - 1; i >= 0; i-- {
+		if fold(text[i]) == pattern[pidx] {
+			pidx--
+			if pidx < 0 {
+				start = i
+				break
+			}
+		}
+	}
+
+	score, consecutive, firstBonus := 0, 0, 0
+	inGap := false
+	prev := charDelimiter
+	if start > 0 {
+		prev = classOf(text[start-1])
+	}
+	var positions []int
+	pidx = 0
+	for i := start; i < end; i++ {
+		class := classOf(text[i])
+		if pidx < len(pattern) && fold(text[i]) == pattern[pidx] {
+			positions = append(positions, i)
+			score += scoreMatch
+			bonus := bonusFor(prev, class)
+			if consecutive == 0 {
+				firstBonus = bonus
+			} else {
+				if bonus >= bonusBoundary && bonus > firstBonus {
+					firstBonus = bonus
+				}
+				bonus = max(bonus, firstBonus, bonusConsecutive)
+			}
+			if pidx == 0 {
+				score += bonus * bonusFirstCharMultiple
+			} else {
+				score += bonus
+			}
+			inGap = false
+			consecutive++
+			pidx++
+		} else {
+			if inGap {
+				score += scoreGapExtension
+			} else {
+				score += scoreGapStart
+			}
+			inGap = true
+			consecutive = 0
+			firstBonus = 0
+		}
+		prev = class
+	}
+	return score, positions, true
+}
@@ -0,0 +1,101 @@
+package main
+
+import (
+	"path/filepath"
+	"reflect"
+	"testing"
+)
+
+func TestCompletePath(t *testing.T) {
+	root := t.TempDir()
+	writeFiles(t, root, map[string]string{
+		"alpha.txt":    "",
+		"Alpine/x":     "",
+		".hidden":      "",
+		"beta":         "",
+		"gamma/deep/y": "",
+	})
+	t.Setenv("HOME", root)
+	t.Setenv("USERPROFILE", root)
+	t.Setenv("JUMPROOT", root)
+	sep := string(filepath.Separator)
+
+	tests := []struct {
+		partial string
+		want    []string
+	}{
+		{root + sep + "al", []string{"alpha.txt", "Alpine" + sep}},
+		{root + sep + "Al", []string{"Alpine" + sep, "alpha.txt"}},
+		{root + sep, []string{"alpha.txt", "Alpine" + sep, "beta", "gamma" + sep}},
+		{"~" + sep + "be", []string{"beta"}},
+		{"$JUMPROOT" + sep + ".h", []string{".hidden"}},
+		{"${JUMPROOT}" + sep + "gamma" + sep, []string{"deep" + sep}},
+		{"%JUMPROOT%" + sep + "g", []string{"gamma" + sep}},
+		{root + sep + "zzz", []string{}},
+	}
+	a := NewApp()
+	for _, tt := range tests {
+		got, err := a.CompletePath(CompletePathRequest{Partial: tt.partial})
+		if err != nil {
+			t.Errorf("CompletePath(%q): %v", tt.partial, err)
+			continue
+		}
+		names := []string{}
+		for _, c := range got.Completions {
+			rel, _ := filepath.Rel(filepath.Dir(filepath.Clean(c.Path)), c.Path)
+			if c.IsDir {
+				rel += sep
+			}
+			names = append(names, rel)
+		}
+		if !reflect.DeepEqual(names, tt.want) {
+			t.Errorf("CompletePath(%q) = %v, want %v", tt.partial, names, tt.want)
+		}
+	}
+
+	if _, err := a.CompletePath(CompletePathRequest{Partial: root + sep + "missing" + sep}); err == nil {
+		t.Error("completing inside a missing directory succeeded, want error")
+	}
+}
+
+func TestFuzzyFind(t *testing.T) {
+	root := t.TempDir()
+	writeFiles(t, root, map[string]string{
+		"src/main.go":                 "",
+		"docs/maintenance.md":         "",
+		"src/components/MainView.tsx": "",
+		"misc/a_m_a_i_n.txt":          "",
+		".git/main":                   "",
+	})
+
+	tests := []struct {
+		query     string
+		want      []string
+		positions []int
+	}{
+		{"main", []string{"src/main.go", "docs/maintenance.md", "src/components/MainView.tsx", "misc/a_m_a_i_n.txt"}, []int{4, 5, 6, 7}},
+		{"Main", []string{"src/components/MainView.tsx"}, []int{15, 16, 17, 18}},
+		{"MAIN", []string{}, nil},
+		{"src view", []string{"src/components/MainView.tsx"}, []int{0, 1, 2, 19, 20, 21, 22}},
+		{"smg", []string{"src/main.go"}, []int{0, 4, 9}},
+	}
+	a := NewApp()
+	for _, tt := range tests {
+		found, err := a.FuzzyFind(FuzzyFindRequest{Root: root, Query: tt.query, Limit: 10})
+		if err != nil {
+			t.Fatal(err)
+		}
+		got := found.Matches
+		rels := []string{}
+		for _, m := range got {
+			rels = append(rels, m.Rel)
+		}
+		if !reflect.DeepEqual(rels, tt.want) {
+			t.Errorf("FuzzyFind(%q) = %v, want %v", tt.query, rels, tt.want)
+			continue
+		}
+		if len(got) > 0 && !reflect.DeepEqual(got[0].Positions, tt.positions) {
+			t.Errorf("FuzzyFind(%q) positions = %v, want %v", tt.query, got[0].Positions, tt.positions)
+		}
+	}
+}
@@ -0,0 +1,236 @@
+package main
+
+import (
+	"os"
+	"path/filepath"
+	"runtime"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+type PathDir struct {
+	Path      string `json:"path"`
+	Index     int    `json:"index"`
+	Exists    bool   `json:"exists"`
+	Duplicate bool   `json:"duplicate"`
+	Empty     bool   `json:"empty"`
+	Commands  int    `json:"commands"`
+}
+
+type PathCommand struct {
+	Name       string `json:"name"`
+	Path       string `json:"path"`
+	Dir        string `json:"dir"`
+	Target     string `json:"target,omitempty"`
+	ShadowedBy string `json:"shadowedBy,omitempty"`
+	SameFile   bool   `json:"sameFile,omitempty"`
+	Problem    string `json:"problem,omitempty"`
+}
+
+type PathReport struct {
+	Dirs     []PathDir     `json:"dirs"`
+	Commands []PathCommand `json:"commands"`
+	Shadowed []PathCommand `json:"shadowed"`
+	Problems []PathCommand `json:"problems"`
+}
+
+// pathEntries splits $PATH into its entries, marking empty ones (the
+// current directory on POSIX) and repeats of an earlier directory.
+func pathEntries() []PathDir {
+	var dirs []PathDir
+	seen := make(map[string]bool)
+	for i, dir := range filepath.SplitList(os.Getenv("PATH")) {
+		entry := PathDir{Path: dir, Index: i}
+		if dir == "" {
+			entry.Empty = true
+			dirs = append(dirs, entry)
+			continue
+		}
+
+		key := filepath.Clean(dir)
+		if runtime.GOOS == "windows" {
+			key = strings.ToLower(key)
+		}
+		entry.Duplicate = seen[key]
+		seen[key] = true
+		dirs = append(dirs, entry)
+	}
+	return dirs
+}
+
+// ReadPathRoot presents the directories in $PATH as the children of a
+// virtual root, in lookup order. Each one expands with ReadDir as usual.
+// Empty and repeated entries are left out; AnalyzePath reports them.
+func (a *App) ReadPathRoot() (NodeList, error) {
+	var nodes []FileNode
+	for _, entry := range pathEntries() {
+		if entry.Empty || entry.Duplicate {
+			continue
+		}
+		nodes = append(nodes, FileNode{
+			Name:   entry.Path,
+			Path:   entry.Path,
+			Type:   NodeFolder,
+			Kind:   KindPathEntry,
+			Detail: "$PATH[" + strconv.Itoa(entry.Index) + "]",
+		})
+	}
+	return NodeList{Nodes: nodes}, nil
+}
+
+// AnalyzePath lists every command reachable through $PATH and reports the
+// ones hidden by an earlier entry, symlinks pointing nowhere and files that
+// sit in a PATH directory without being executable.
+func (a *App) AnalyzePath() (PathReport, error) {
+	report := PathReport{
+		Dirs:     []PathDir{},
+		Commands: []PathCommand{},
+		Shadowed: []PathCommand{},
+		Problems: []PathCommand{},
+	}
+	first := make(map[string]PathCommand)
+
+	for _, entry := range pathEntries() {
+		if entry.Empty {
+			report.Dirs = append(report.Dirs, entry)
+			continue
+		}
+		if entry.Duplicate {
+			_, err := os.Stat(entry.Path)
+			entry.Exists = err == nil
+			report.Dirs = append(report.Dirs, entry)
+			continue
+		}
+
+		entries, err := os.ReadDir(entry.Path)
+		if err != nil {
+			report.Dirs = append(report.Dirs, entry)
+			continue
+		}
+		entry.Exists = true
+
+		var cmds []PathCommand
+		for _, e := range entries {
+			cmd, ok := pathCommand(entry.Path, e)
+			if !ok {
+				continue
+			}
+			if cmd.Problem != "" {
+				report.Problems = append(report.Problems, cmd)
+				continue
+			}
+			cmds = append(cmds, cmd)
+		}
+		if runtime.GOOS == "windows" {
+			sortByPathext(cmds)
+		}
+
+		for _, cmd := range cmds {
+			entry.Commands++
+			if prev, ok := first[cmd.Name]; ok {
+				cmd.ShadowedBy = prev.Path
+				cmd.SameFile = sameFile(prev.Path, cmd.Path)
+				report.Shadowed = append(report.Shadowed, cmd)
+				continue
+			}
+			first[cmd.Name] = cmd
+			report.Commands = append(report.Commands, cmd)
+		}
+		report.Dirs = append(report.Dirs, entry)
+	}
+	return report, nil
+}
+
+// sortByPathext orders the commands of one directory the way Windows picks
+// between foo.exe and foo.bat: by the extension's position in PATHEXT.
+func sortByPathext(cmds []PathCommand) {
+	exts := pathext()
+	rank := func(cmd PathCommand) int {
+		ext := strings.ToLower(filepath.Ext(cmd.Path))
+		for i, e := range exts {
+			if e == ext {
+				return i
+			}
+		}
+		return len(exts)
+	}
+	sort.SliceStable(cmds, func(i, j int) bool {
+		if cmds[i].Name != cmds[j].Name {
+			return cmds[i].Name < cmds[j].Name
+		}
+		return rank(cmds[i]) < rank(cmds[j])
+	})
+}
+
+func pathCommand(dir string, e os.DirEntry) (PathCommand, bool) {
+	path := filepath.Join(dir, e.Name())
+	cmd := PathCommand{Name: e.Name(), Path: path, Dir: dir}
+
+	if e.Type()&os.ModeSymlink != 0 {
+		if target, err := os.Readlink(path); err == nil {
+			cmd.Target = target
+		}
+	}
+
+	info, err := os.Stat(path)
+	if err != nil {
+		if cmd.Target != "" {
+			cmd.Problem = "broken symlink"
+			return cmd, true
+		}
+		return cmd, false
+	}
+	if info.IsDir() {
+		return cmd, false
+	}
+
+	if runtime.GOOS == "windows" {
+		ext := strings.ToLower(filepath.Ext(e.Name()))
+		if !windowsExecutableExt(ext) {
+			return cmd, false
+		}
+		cmd.Name = strings.ToLower(strings.TrimSuffix(e.Name(), filepath.Ext(e.Name())))
+		return cmd, true
+	}
+
+	if info.Mode().Perm()&0o111 == 0 {
+		cmd.Problem = "not executable"
+	}
+	return cmd, true
+}
+
+func windowsExecutableExt(ext string) bool {
+	for _, e := range pathext() {
+		if e == ext {
+			return true
+		}
+	}
+	return false
+}
+
+func pathext() []string {
+	value := os.Getenv("PATHEXT")
+	if value == "" {
+		value = ".COM;.EXE;.BAT;.CMD"
+	}
+	var exts []string
+	for _, e := range strings.Split(strings.ToLower(value), ";") {
+		if e != "" {
+			exts = append(exts, e)
+		}
+	}
+	return exts
+}
+
+func sameFile(a, b string) bool {
+	ai, err := os.Stat(a)
+	if err != nil {
+		return false
+	}
+	bi, err := os.Stat(b)
+	if err != nil {
+		return false
+	}
+	return os.SameFile(ai, bi)
+}
@@ -0,0 +1,101 @@
+package main
+
+import (
+	"os"
+	"path/filepath"
+	"runtime"
+	"strings"
+	"testing"
+)
+
+func TestReadPathRoot(t *testing.T) {
+	a, b := t.TempDir(), t.TempDir()
+	t.Setenv("PATH", strings.Join([]string{a, a, "", b}, string(os.PathListSeparator)))
+
+	list, err := NewApp().ReadPathRoot()
+	if err != nil {
+		t.Fatal(err)
+	}
+	nodes := list.Nodes
+	if len(nodes) != 2 || nodes[0].Path != a || nodes[1].Path != b {
+		t.Errorf("nodes = %+v, want %s then %s", nodes, a, b)
+	}
+}
+
+func TestAnalyzePath(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("uses POSIX permissions and symlinks")
+	}
+
+	first, second := t.TempDir(), t.TempDir()
+	writeFiles(t, first, map[string]string{"tool": "#!/bin/sh\n"})
+	writeFiles(t, second, map[string]string{"tool": "#!/bin/sh\n", "notes.txt": "x", "other": "#!/bin/sh\n"})
+	for _, p := range []string{filepath.Join(first, "tool"), filepath.Join(second, "tool"), filepath.Join(second, "other")} {
+		if err := os.Chmod(p, 0o755); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := os.Symlink(filepath.Join(second, "missing"), filepath.Join(second, "dangling")); err != nil {
+		t.Fatal(err)
+	}
+	t.Setenv("PATH", strings.Join([]string{first, "", second, first}, string(os.PathListSeparator)))
+
+	report, err := NewApp().AnalyzePath()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	dirs := []struct {
+		empty, duplicate bool
+		commands         int
+	}{
+		{false, false, 1},
+		{true, false, 0},
+		{false, false, 2},
+		{false, true, 0},
+	}
+	if len(report.Dirs) != len(dirs) {
+		t.Fatalf("dirs = %+v, want %d entries", report.Dirs, len(dirs))
+	}
+	for i, want := range dirs {
+		got := report.Dirs[i]
+		if got.Empty != want.empty || got.Duplicate != want.duplicate || got.Commands != want.commands {
+			t.Errorf("dirs[%d] = %+v, want empty=%v duplicate=%v commands=%d", i, got, want.empty, want.duplicate, want.commands)
+		}
+	}
+
+	if len(report.Shadowed) != 1 || report.Shadowed[0].Path != filepath.Join(second, "tool") ||
+		report.Shadowed[0].ShadowedBy != filepath.Join(first, "tool") {
+		t.Errorf("shadowed = %+v", report.Shadowed)
+	}
+
+	problems := make(map[string]string)
+	for _, p := range report.Problems {
+		problems[p.Name] = p.Problem
+	}
+	want := map[string]string{"dangling": "broken symlink", "notes.txt": "not executable"}
+	for name, problem := range want {
+		if problems[name] != problem {
+			t.Errorf("problem for %s = %q, want %q", name, problems[name], problem)
+		}
+	}
+}
+
+func TestSortByPathext(t *testing.T) {
+	t.Setenv("PATHEXT", ".COM;.EXE;.BAT")
+	cmds := []PathCommand{
+		{Name: "foo", Path: `C:\bin\foo.bat`},
+		{Name: "foo", Path: `C:\bin\foo.exe`},
+		{Name: "bar", Path: `C:\bin\bar.exe`},
+		{Name: "foo", Path: `C:\bin\foo.com`},
+	}
+	sortByPathext(cmds)
+
+	var got []string
+	for _, c := range cmds {
+		got = append(got, filepath.Ext(c.Path))
+	}
+	if strings.Join(got, " ") != ".exe .com .exe .bat" {
+		t.Errorf("order = %v, want bar.exe, foo.com, foo.exe, foo.bat", got)
+	}
+}
@@ -0,0 +1,196 @@
+package main
+
+import (
+	"container/heap"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"time"
+)
+
+// ScannedDir is emitted in batches as "scan:dirs" by a priority scan. Bytes
+// and Files count only the files directly inside Path.
+type ScannedDir struct {
+	Path     string  `json:"path"`
+	Parent   string  `json:"parent"`
+	Files    int     `json:"files"`
+	Bytes    int64   `json:"bytes"`
+	Priority float64 `json:"priority"`
+}
+
+type pendingDir struct {
+	path, parent string
+	priority     float64
+}
+
+type dirQueue []pendingDir
+
+func (q dirQueue) Len() int           { return len(q) }
+func (q dirQueue) Less(i, j int) bool { return q[i].priority > q[j].priority }
+func (q dirQueue) Swap(i, j int)      { q[i], q[j] = q[j], q[i] }
+func (q *dirQueue) Push(x any)        { *q = append(*q, x.(pendingDir)) }
+func (q *dirQueue) Pop() any {
+	old := *q
+	d := old[len(old)-1]
+	*q = old[:len(old)-1]
+	return d
+}
+
+// priorityScan walks the whole tree, always reading next the pending
+// directory that looks largest, so the big contributors are reported first.
+// A directory's guess is its size from the previous completed scan of the
+// same root when there is one. Otherwise it is the mean bytes per directory
+// so far times the number of directories it holds, which its link count
+// gives away on Unix filesystems. Where link counts don't help, each
+// subdirectory gets an equal share of its parent's entries.
+func priorityScan(ctx context.Context, opts ScanOptions, emit func(string, interface{})) ScanEstimate {
+	index := loadScanIndex(opts.Root)
+	est := ScanEstimate{Root: opts.Root, Mode: "priority"}
+
+	var order []ScannedDir
+	var batch []ScannedDir
+	last := time.Now()
+	flush := func() {
+		if len(batch) > 0 {
+			emit("scan:dirs", batch)
+			batch = nil
+		}
+		emit("scan:estimate", exactBounds(est))
+	}
+
+	queue := &dirQueue{{path: opts.Root}}
+	for queue.Len() > 0 && ctx.Err() == nil {
+		cur := heap.Pop(queue).(pendingDir)
+		entries, _ := os.ReadDir(cur.path)
+
+		dir := ScannedDir{Path: cur.path, Parent: cur.parent, Priority: cur.priority}
+		var subdirs []os.DirEntry
+		for _, e := range entries {
+			if e.IsDir() {
+				subdirs = append(subdirs, e)
+			} else if info, err := e.Info(); err == nil {
+				dir.Files++
+				dir.Bytes += info.Size()
+			}
+		}
+		est.Dirs++
+		est.DirsVisited++
+		est.Files += float64(dir.Files)
+		est.Bytes += float64(dir.Bytes)
+
+		mean := max(est.Bytes/est.Dirs, 1)
+		for _, e := range subdirs {
+			path := filepath.Join(cur.path, e.Name())
+			priority, ok := index.size(opts.Root, path)
+			if !ok {
+				guess := float64(len(entries)) / float64(len(subdirs))
+				if info, err := e.Info(); err == nil {
+					if n := linkCount(info); n >= 2 {
+						guess = float64(n - 1)
+					}
+				}
+				priority = guess * mean
+			}
+			heap.Push(queue, pendingDir{path: path, parent: cur.path, priority: priority})
+		}
+
+		order = append(order, dir)
+		batch = append(batch, dir)
+		if time.Since(last) >= scanEmitInterval {
+			last = time.Now()
+			flush()
+		}
+	}
+	if len(batch) > 0 {
+		emit("scan:dirs", batch)
+	}
+
+	if ctx.Err() == nil {
+		index.update(opts.Root, order)
+		index.save()
+	}
+	return exactBounds(est)
+}
+
+// scanIndex remembers the total size of each directory from the last
+// completed priority scan of a root, keyed by slash-separated path relative
+// to the root. It lives in the user cache directory.
+type scanIndex struct {
+	Root    string           `json:"root"`
+	Updated time.Time        `json:"updated"`
+	Sizes   map[string]int64 `json:"sizes"`
+}
+
+func scanIndexFile(root string) (string, error) {
+	dir, err := os.UserCacheDir()
+	if err != nil {
+		return "", err
+	}
+	sum := sha256.Sum256([]byte(filepath.Clean(root)))
+	return filepath.Join(dir, "recursion", "scan-index", hex.EncodeToString(sum[:8])+".json"), nil
+}
+
+func loadScanIndex(root string) *scanIndex {
+	ix := &scanIndex{Root: filepath.Clean(root), Sizes: map[string]int64{}}
+	file, err := scanIndexFile(root)
+	if err != nil {
+		return ix
+	}
+	data, err := os.ReadFile(file)
+	if err != nil {
+		return ix
+	}
+	var loaded scanIndex
+	if json.Unmarshal(data, &loaded) != nil || loaded.Root != ix.Root || loaded.Sizes == nil {
+		return ix
+	}
+	return &loaded
+}
+
+func (ix *scanIndex) size(root, path string) (float64, bool) {
+	rel, err := filepath.Rel(root, path)
+	if err != nil {
+		return 0, false
+	}
+	size, ok := ix.Sizes[filepath.ToSlash(rel)]
+	return float64(size), ok
+}
+
+// update replaces the sizes with totals computed from dirs, which lists
+// every directory after its parent.
+func (ix *scanIndex) update(root string, dirs []ScannedDir) {
+	totals := make(map[string]int64, len(dirs))
+	for i := len(dirs) - 1; i >= 0; i-- {
+		d := dirs[i]
+		totals[d.Path] += d.Bytes
+		if d.Parent != "" {
+			totals[d.Parent] += totals[d.Path]
+		}
+	}
+
+	ix.Sizes = make(map[string]int64, len(totals))
+	for path, size := range totals {
+		if rel, err := filepath.Rel(root, path); err == nil {
+			ix.Sizes[filepath.ToSlash(rel)] = size
+		}
+	}
+	ix.Updated = time.Now()
+}
+
+func (ix *scanIndex) save() error {
+	file, err := scanIndexFile(ix.Root)
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(filepath.Dir(file), 0o755); err != nil {
+		return err
+	}
+	data, err := json.Marshal(ix)
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(file, data, 0o644)
+}
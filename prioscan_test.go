@@ -0,0 +1,93 @@
+package main
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func runPriorityScan(t *testing.T, root string) ([]ScannedDir, ScanEstimate) {
+	t.Helper()
+	var dirs []ScannedDir
+	est := priorityScan(context.Background(), ScanOptions{Root: root, Mode: "priority"}, func(name string, data interface{}) {
+		if name == "scan:dirs" {
+			dirs = append(dirs, data.([]ScannedDir)...)
+		}
+	})
+	return dirs, est
+}
+
+func TestPriorityScan(t *testing.T) {
+	t.Setenv("XDG_CACHE_HOME", t.TempDir())
+	root := t.TempDir()
+	writeFiles(t, root, map[string]string{
+		"a/small":     "x",
+		"b/c/d/large": strings.Repeat("x", 5000),
+		"b/medium":    strings.Repeat("x", 100),
+		"e/f/g":       strings.Repeat("x", 10),
+		"top":         "xy",
+	})
+
+	first, est := runPriorityScan(t, root)
+	exact := exactScan(context.Background(), ScanOptions{Root: root}, func(string, interface{}) {})
+	if est.Files != exact.Files || est.Dirs != exact.Dirs || est.Bytes != exact.Bytes {
+		t.Errorf("priority totals = %+v, want %+v", est, exact)
+	}
+	if len(first) != int(exact.Dirs) || first[0].Path != root {
+		t.Fatalf("first scan visited %d dirs starting at %s", len(first), first[0].Path)
+	}
+
+	index := loadScanIndex(root)
+	tests := []struct {
+		rel  string
+		size int64
+	}{
+		{".", 5113},
+		{"a", 1},
+		{"b", 5100},
+		{"b/c/d", 5000},
+		{"e", 10},
+	}
+	for _, tt := range tests {
+		if got := index.Sizes[tt.rel]; got != tt.size {
+			t.Errorf("index size of %s = %d, want %d", tt.rel, got, tt.size)
+		}
+	}
+
+	second, _ := runPriorityScan(t, root)
+	var order []string
+	for _, d := range second {
+		rel, _ := filepath.Rel(root, d.Path)
+		order = append(order, filepath.ToSlash(rel))
+	}
+	want := []string{".", "b", "b/c", "b/c/d", "e", "e/f", "a"}
+	if strings.Join(order, " ") != strings.Join(want, " ") {
+		t.Errorf("second scan order = %v, want %v", order, want)
+	}
+}
+
+func TestPriorityScanLinkCounts(t *testing.T) {
+	t.Setenv("XDG_CACHE_HOME", t.TempDir())
+	root := t.TempDir()
+	writeFiles(t, root, map[string]string{
+		"few/file":    "x",
+		"many/1/file": "x",
+		"many/2/file": "x",
+		"many/3/file": "x",
+		"many/4/file": "x",
+	})
+	info, err := os.Stat(filepath.Join(root, "many"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if linkCount(info) < 2 {
+		t.Skip("filesystem doesn't count directory links")
+	}
+
+	dirs, _ := runPriorityScan(t, root)
+	if got := filepath.Base(dirs[1].Path); got != "many" {
+		t.Errorf("second directory read = %s, want many", got)
+	}
+}
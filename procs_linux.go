@@ -0,0 +1,50 @@
+package main
+
+import (
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+)
+
+const canFindProcesses = true
+
+// processesHolding returns the processes with one of paths open, found by
+// reading the descriptor links under /proc. Processes owned by other users
+// can't be inspected and are missed.
+func processesHolding(paths []string) []ProcessInfo {
+	want := make(map[string]bool, len(paths))
+	for _, p := range paths {
+		want[p] = true
+	}
+	procs, err := os.ReadDir("/proc")
+	if err != nil {
+		return nil
+	}
+
+	var found []ProcessInfo
+	for _, p := range procs {
+		pid, err := strconv.Atoi(p.Name())
+		if err != nil || pid == os.Getpid() {
+			continue
+		}
+		fdDir := filepath.Join("/proc", p.Name(), "fd")
+		fds, err := os.ReadDir(fdDir)
+		if err != nil {
+			continue
+		}
+		var held []string
+		for _, fd := range fds {
+			target, err := os.Readlink(filepath.Join(fdDir, fd.Name()))
+			if err == nil && want[strings.TrimSuffix(target, " (deleted)")] {
+				held = append(held, target)
+			}
+		}
+		if len(held) == 0 {
+			continue
+		}
+		comm, _ := os.ReadFile(filepath.Join("/proc", p.Name(), "comm"))
+		found = append(found, ProcessInfo{PID: pid, Name: strings.TrimSpace(string(comm)), Paths: held})
+	}
+	return found
+}
@@ -0,0 +1,9 @@
+//go:build !linux
+
+package main
+
+const canFindProcesses = false
+
+func processesHolding(paths []string) []ProcessInfo {
+	return nil
+}
@@ -0,0 +1,226 @@
+package main
+
+import (
+	"container/heap"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/fsnotify/fsnotify"
+)
+
+type RecentFile struct {
+	Name     string    `json:"name"`
+	Path     string    `json:"path"`
+	Size     int64     `json:"size"`
+	Modified time.Time `json:"modified" ts_type:"string"`
+	Created  time.Time `json:"created" ts_type:"string"`
+	// Event is set on entries that came from the watcher rather than a scan.
+	Event string `json:"event,omitempty"`
+}
+
+type RecentQuery struct {
+	Root string `json:"root"`
+	// SortBy is "modified" (the default) or "created". Files whose creation
+	// time isn't recorded fall back to their modification time.
+	SortBy string `json:"sortBy"`
+	// Extensions limits results to these extensions, e.g. ".log".
+	Extensions []string `json:"extensions"`
+	Offset     int      `json:"offset"`
+	Limit      int      `json:"limit"`
+	// Live prepends files reported by WatchRecent since it was started.
+	Live bool `json:"live"`
+}
+
+type RecentPage struct {
+	Files []RecentFile `json:"files"`
+	// Total counts the files under root that match the query.
+	Total int `json:"total"`
+}
+
+const maxRecentLive = 1000
+
+// RecentFiles returns the files under q.Root ordered newest first, one page
+// at a time.
+func (a *App) RecentFiles(q RecentQuery) (RecentPage, error) {
+	page := RecentPage{Files: []RecentFile{}}
+	if q.Limit <= 0 {
+		q.Limit = 100
+	}
+	q.Offset = max(q.Offset, 0)
+
+	var live []RecentFile
+	if q.Live {
+		a.recentMu.Lock()
+		for _, f := range a.recentLive {
+			if withinRoot(q.Root, f.Path) && q.matches(f.Path) {
+				live = append(live, f)
+			}
+		}
+		a.recentMu.Unlock()
+	}
+	seen := make(map[string]bool)
+	for _, f := range live {
+		seen[f.Path] = true
+	}
+
+	keep := q.Offset + q.Limit
+	h := &recentHeap{by: q.SortBy}
+	err := filepath.WalkDir(q.Root, func(path string, d fs.DirEntry, err error) error {
+		if err != nil {
+			if path == q.Root {
+				return err
+			}
+			return nil
+		}
+		if !d.Type().IsRegular() || !q.matches(path) || seen[path] {
+			return nil
+		}
+		info, err := d.Info()
+		if err != nil {
+			return nil
+		}
+
+		page.Total++
+		f := newRecentFile(path, info)
+		if h.Len() < keep {
+			heap.Push(h, f)
+		} else if h.less(h.files[0], f) {
+			h.files[0] = f
+			heap.Fix(h, 0)
+		}
+		return nil
+	})
+	if err != nil {
+		return page, err
+	}
+
+	scanned := make([]RecentFile, h.Len())
+	for i := len(scanned) - 1; i >= 0; i-- {
+		scanned[i] = heap.Pop(h).(RecentFile)
+	}
+	page.Total += len(live)
+	all := append(live, scanned...)
+	if q.Offset < len(all) {
+		page.Files = all[q.Offset:min(len(all), keep)]
+	}
+	return page, nil
+}
+
+// WatchRecent watches q.Root and emits a "recent:file" event with a
+// RecentFile for each matching file that is created or written, replacing
+// any previous watch. The files are also kept for RecentFiles queries with
+// Live set.
+func (a *App) WatchRecent(q RecentQuery) error {
+	a.StopWatchRecent()
+	a.recentMu.Lock()
+	a.recentLive = nil
+	a.recentMu.Unlock()
+
+	tw, err := newTreeWatcher(q.Root, func(ev fsnotify.Event) {
+		if !ev.Has(fsnotify.Create) && !ev.Has(fsnotify.Write) || !q.matches(ev.Name) {
+			return
+		}
+		info, err := os.Lstat(ev.Name)
+		if err != nil || !info.Mode().IsRegular() {
+			return
+		}
+		f := newRecentFile(ev.Name, info)
+		f.Event = "write"
+		if ev.Has(fsnotify.Create) {
+			f.Event = "create"
+		}
+
+		a.recentMu.Lock()
+		live := []RecentFile{f}
+		for _, old := range a.recentLive {
+			if old.Path != f.Path && len(live) < maxRecentLive {
+				live = append(live, old)
+			}
+		}
+		a.recentLive = live
+		a.recentMu.Unlock()
+
+		a.emit("recent:file", f)
+	})
+	if err != nil {
+		return err
+	}
+
+	a.recentMu.Lock()
+	a.recentWatch = tw
+	a.recentMu.Unlock()
+	return nil
+}
+
+func (a *App) StopWatchRecent() {
+	a.recentMu.Lock()
+	tw := a.recentWatch
+	a.recentWatch = nil
+	a.recentMu.Unlock()
+	if tw != nil {
+		tw.Close()
+	}
+}
+
+func newRecentFile(path string, info fs.FileInfo) RecentFile {
+	return RecentFile{
+		Name:     info.Name(),
+		Path:     path,
+		Size:     info.Size(),
+		Modified: info.ModTime(),
+		Created:  fileBirthTime(path, info),
+	}
+}
+
+func (q *RecentQuery) matches(path string) bool {
+	if len(q.Extensions) == 0 {
+		return true
+	}
+	ext := filepath.Ext(path)
+	for _, e := range q.Extensions {
+		if strings.EqualFold(ext, e) {
+			return true
+		}
+	}
+	return false
+}
+
+func withinRoot(root, path string) bool {
+	rel, err := filepath.Rel(root, path)
+	return err == nil && rel != ".." && !strings.HasPrefix(rel, ".."+string(filepath.Separator))
+}
+
+// recentHeap is a min-heap on time, so the oldest of the files kept so far
+// is the one replaced by a newer file.
+type recentHeap struct {
+	by    string
+	files []RecentFile
+}
+
+func (h *recentHeap) time(f RecentFile) time.Time {
+	if h.by == "created" && !f.Created.IsZero() {
+		return f.Created
+	}
+	return f.Modified
+}
+
+func (h *recentHeap) less(a, b RecentFile) bool {
+	ta, tb := h.time(a), h.time(b)
+	if ta.Equal(tb) {
+		return a.Path > b.Path
+	}
+	return ta.Before(tb)
+}
+
+func (h *recentHeap) Len() int           { return len(h.files) }
+func (h *recentHeap) Less(i, j int) bool { return h.less(h.files[i], h.files[j]) }
+func (h *recentHeap) Swap(i, j int)      { h.files[i], h.files[j] = h.files[j], h.files[i] }
+func (h *recentHeap) Push(x any)         { h.files = append(h.files, x.(RecentFile)) }
+func (h *recentHeap) Pop() any {
+	f := h.files[len(h.files)-1]
+	h.files = h.files[:len(h.files)-1]
+	return f
+}
@@ -0,0 +1,108 @@
+package main
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func TestRecentFiles(t *testing.T) {
+	root := t.TempDir()
+	writeFiles(t, root, map[string]string{
+		"a.log":     "a",
+		"b.txt":     "b",
+		"sub/c.log": "c",
+		"sub/d.LOG": "d",
+	})
+	base := time.Now().Add(-time.Hour)
+	for i, name := range []string{"a.log", "b.txt", "sub/c.log", "sub/d.LOG"} {
+		mtime := base.Add(time.Duration(i) * time.Minute)
+		if err := os.Chtimes(filepath.Join(root, filepath.FromSlash(name)), mtime, mtime); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	tests := []struct {
+		name  string
+		query RecentQuery
+		want  []string
+		total int
+	}{
+		{"all", RecentQuery{}, []string{"d.LOG", "c.log", "b.txt", "a.log"}, 4},
+		{"first page", RecentQuery{Limit: 2}, []string{"d.LOG", "c.log"}, 4},
+		{"second page", RecentQuery{Offset: 2, Limit: 2}, []string{"b.txt", "a.log"}, 4},
+		{"past the end", RecentQuery{Offset: 10, Limit: 2}, []string{}, 4},
+		{"by extension", RecentQuery{Extensions: []string{".log"}}, []string{"d.LOG", "c.log", "a.log"}, 3},
+	}
+	a := NewApp()
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tt.query.Root = root
+			page, err := a.RecentFiles(tt.query)
+			if err != nil {
+				t.Fatal(err)
+			}
+			var got []string
+			for _, f := range page.Files {
+				got = append(got, f.Name)
+			}
+			if len(got) != len(tt.want) {
+				t.Fatalf("files = %v, want %v", got, tt.want)
+			}
+			for i := range got {
+				if got[i] != tt.want[i] {
+					t.Fatalf("files = %v, want %v", got, tt.want)
+				}
+			}
+			if page.Total != tt.total {
+				t.Errorf("total = %d, want %d", page.Total, tt.total)
+			}
+		})
+	}
+
+	if _, err := a.RecentFiles(RecentQuery{Root: filepath.Join(root, "missing")}); err == nil {
+		t.Error("missing root succeeded, want error")
+	}
+}
+
+func TestWatchRecent(t *testing.T) {
+	root := t.TempDir()
+	writeFiles(t, root, map[string]string{"old.log": "old"})
+	old := time.Now().Add(-time.Hour)
+	os.Chtimes(filepath.Join(root, "old.log"), old, old)
+
+	a := NewApp()
+	q := RecentQuery{Root: root, Extensions: []string{".log"}, Live: true}
+	if err := a.WatchRecent(q); err != nil {
+		t.Fatal(err)
+	}
+	defer a.StopWatchRecent()
+
+	if err := os.Mkdir(filepath.Join(root, "new"), 0o755); err != nil {
+		t.Fatal(err)
+	}
+	time.Sleep(50 * time.Millisecond)
+	writeFiles(t, root, map[string]string{"new/fresh.log": "fresh", "new/skip.txt": "skip"})
+
+	deadline := time.Now().Add(5 * time.Second)
+	for {
+		page, err := a.RecentFiles(q)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if len(page.Files) == 2 && page.Files[0].Event != "" {
+			if page.Files[0].Name != "fresh.log" || page.Files[1].Name != "old.log" {
+				t.Fatalf("files = %+v", page.Files)
+			}
+			if page.Total != 2 {
+				t.Errorf("total = %d, want 2", page.Total)
+			}
+			return
+		}
+		if time.Now().After(deadline) {
+			t.Fatalf("watcher event never arrived, files = %+v", page.Files)
+		}
+		time.Sleep(20 * time.Millisecond)
+	}
+}
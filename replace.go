@@ -0,0 +1,444 @@
+package main
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"time"
+)
+
+type ReplaceRequest struct {
+	Root string `json:"root"`
+	// Paths narrows the operation to these files and directories under
+	// Root. Empty means all of Root.
+	Paths   []string `json:"paths"`
+	Pattern string   `json:"pattern"`
+	// Replacement may refer to capture groups as $1 or ${name}.
+	Replacement string `json:"replacement"`
+}
+
+type ReplaceFilePreview struct {
+	Path     string `json:"path"`
+	Encoding string `json:"encoding"`
+	Count    int    `json:"count"`
+	Diff     string `json:"diff"`
+	// Summary is set instead of Diff when too many lines change to show
+	// one.
+	Summary string `json:"summary,omitempty"`
+	// Hash identifies the contents the preview was made from; ApplyReplace
+	// refuses to touch a file whose contents have changed since.
+	Hash string `json:"hash"`
+}
+
+type ReplacePreview struct {
+	Files []ReplaceFilePreview `json:"files"`
+	Count int                  `json:"count"`
+}
+
+type ReplaceSelection struct {
+	Path string `json:"path"`
+	Hash string `json:"hash"`
+}
+
+type ReplaceResult struct {
+	JournalID string   `json:"journalId"`
+	Applied   []string `json:"applied"`
+	// Conflicts lists files that were skipped, with the reason.
+	Conflicts []string `json:"conflicts"`
+}
+
+type ReplaceJournal struct {
+	ID          string               `json:"id"`
+	Time        time.Time            `json:"time" ts_type:"string"`
+	Root        string               `json:"root"`
+	Pattern     string               `json:"pattern"`
+	Replacement string               `json:"replacement"`
+	Files       []ReplaceJournalFile `json:"files"`
+	Undone      bool                 `json:"undone"`
+}
+
+type ReplaceJournalFile struct {
+	Path    string `json:"path"`
+	Backup  string `json:"backup"`
+	OldHash string `json:"oldHash"`
+	NewHash string `json:"newHash"`
+	// Restored is set once an undo has put the backup back.
+	Restored bool `json:"restored"`
+}
+
+// replaceJournalIDLayout formats journal ids, which are also the names of
+// their directories.
+const replaceJournalIDLayout = "20060102T150405.000000000Z"
+
+// PreviewReplace computes the replacements req would make without writing
+// anything, with a unified diff per changed file.
+func (a *App) PreviewReplace(req ReplaceRequest) (ReplacePreview, error) {
+	preview := ReplacePreview{Files: []ReplaceFilePreview{}}
+	re, err := regexp.Compile(req.Pattern)
+	if err != nil {
+		return preview, err
+	}
+
+	err = walkTextFiles(req.Root, req.Paths, func(path string) {
+		data, err := os.ReadFile(path)
+		if err != nil {
+			return
+		}
+		edit, ok := replaceInText(re, req.Replacement, data)
+		if !ok || edit.count == 0 {
+			return
+		}
+		fp := ReplaceFilePreview{
+			Path:     path,
+			Encoding: edit.enc.String(),
+			Count:    edit.count,
+			Hash:     hashBytes(data),
+		}
+		if fp.Diff, ok = unifiedDiff(path, path, edit.before, edit.after, defaultDiffContext, defaultDiffEdits); !ok {
+			fp.Summary = fmt.Sprintf("%d replacements; too many changed lines to show a diff", edit.count)
+		}
+		preview.Files = append(preview.Files, fp)
+		preview.Count += edit.count
+	})
+	return preview, err
+}
+
+type ApplyReplaceRequest struct {
+	// Replace is the request the preview was made for.
+	Replace  ReplaceRequest     `json:"replace"`
+	Selected []ReplaceSelection `json:"selected"`
+}
+
+type ReplaceJournals struct {
+	Journals []ReplaceJournal `json:"journals"`
+}
+
+type UndoReplaceRequest struct {
+	// ID is the ReplaceResult.JournalID of the operation.
+	ID string `json:"id"`
+}
+
+// ApplyReplace performs req.Replace on the req.Selected files from a
+// preview. Each file keeps its encoding, byte order mark and permissions,
+// and is backed up to a journal first so UndoReplace can restore it. The
+// journal is saved before each file is rewritten, so an apply cut short
+// can still be undone.
+func (a *App) ApplyReplace(req ApplyReplaceRequest) (ReplaceResult, error) {
+	result := ReplaceResult{Applied: []string{}, Conflicts: []string{}}
+	re, err := regexp.Compile(req.Replace.Pattern)
+	if err != nil {
+		return result, err
+	}
+
+	journal := &ReplaceJournal{
+		ID:          time.Now().UTC().Format(replaceJournalIDLayout),
+		Time:        time.Now(),
+		Root:        req.Replace.Root,
+		Pattern:     req.Replace.Pattern,
+		Replacement: req.Replace.Replacement,
+	}
+	dir, err := replaceJournalDir(journal.ID)
+	if err != nil {
+		return result, err
+	}
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		return result, err
+	}
+	if err := journal.save(); err != nil {
+		os.RemoveAll(dir)
+		return result, err
+	}
+
+	for i, sel := range req.Selected {
+		if !withinRoot(req.Replace.Root, sel.Path) {
+			result.Conflicts = append(result.Conflicts, sel.Path+": outside the root")
+			continue
+		}
+		data, err := os.ReadFile(sel.Path)
+		if err != nil {
+			result.Conflicts = append(result.Conflicts, sel.Path+": "+err.Error())
+			continue
+		}
+		if hashBytes(data) != sel.Hash {
+			result.Conflicts = append(result.Conflicts, sel.Path+": changed since the preview")
+			continue
+		}
+		edit, ok := replaceInText(re, req.Replace.Replacement, data)
+		if !ok || edit.count == 0 {
+			continue
+		}
+		out, err := encodeText(edit.after, edit.enc)
+		if err != nil {
+			result.Conflicts = append(result.Conflicts, sel.Path+": "+err.Error())
+			continue
+		}
+
+		backup := filepath.Join(dir, fmt.Sprintf("%d.orig", i))
+		if err := os.WriteFile(backup, data, 0o600); err != nil {
+			return result, finishReplaceJournal(journal, dir, &result, err)
+		}
+		journal.Files = append(journal.Files, ReplaceJournalFile{
+			Path:    sel.Path,
+			Backup:  backup,
+			OldHash: sel.Hash,
+			NewHash: hashBytes(out),
+		})
+		if err := journal.save(); err != nil {
+			journal.Files = journal.Files[:len(journal.Files)-1]
+			return result, finishReplaceJournal(journal, dir, &result, err)
+		}
+		if err := replaceFileContents(sel.Path, out); err != nil {
+			journal.Files = journal.Files[:len(journal.Files)-1]
+			os.Remove(backup)
+			result.Conflicts = append(result.Conflicts, sel.Path+": "+err.Error())
+			continue
+		}
+		result.Applied = append(result.Applied, sel.Path)
+	}
+	return result, finishReplaceJournal(journal, dir, &result, nil)
+}
+
+// finishReplaceJournal saves the journal of the files rewritten so far,
+// or removes it when there are none, and returns err or the save's error.
+func finishReplaceJournal(journal *ReplaceJournal, dir string, result *ReplaceResult, err error) error {
+	if len(journal.Files) == 0 {
+		os.RemoveAll(dir)
+		return err
+	}
+	result.JournalID = journal.ID
+	return errors.Join(err, journal.save())
+}
+
+// ReplaceHistory lists the journaled replace operations, newest first.
+func (a *App) ReplaceHistory() (ReplaceJournals, error) {
+	journals := []ReplaceJournal{}
+	base, err := replaceJournalBase()
+	if err != nil {
+		return ReplaceJournals{Journals: journals}, err
+	}
+	entries, err := os.ReadDir(base)
+	if os.IsNotExist(err) {
+		return ReplaceJournals{Journals: journals}, nil
+	}
+	if err != nil {
+		return ReplaceJournals{Journals: journals}, err
+	}
+	for _, e := range entries {
+		if j, err := loadReplaceJournal(e.Name()); err == nil {
+			journals = append(journals, *j)
+		}
+	}
+	sort.Slice(journals, func(i, j int) bool { return journals[i].ID > journals[j].ID })
+	return ReplaceJournals{Journals: journals}, nil
+}
+
+// UndoReplace restores the files changed by the journaled operation req.ID.
+// Files edited again since are left alone and reported as conflicts; the
+// undo can be retried for them once they are sorted out.
+func (a *App) UndoReplace(req UndoReplaceRequest) (ReplaceResult, error) {
+	id := req.ID
+	result := ReplaceResult{JournalID: id, Applied: []string{}, Conflicts: []string{}}
+	j, err := loadReplaceJournal(id)
+	if err != nil {
+		return result, err
+	}
+	if j.Undone {
+		return result, errors.New("replace " + id + " was already undone")
+	}
+
+	for i, f := range j.Files {
+		if f.Restored {
+			continue
+		}
+		current, err := os.ReadFile(f.Path)
+		if err != nil {
+			result.Conflicts = append(result.Conflicts, f.Path+": "+err.Error())
+			continue
+		}
+		if hash := hashBytes(current); hash == f.OldHash {
+			// Already back as it was, by hand or because an apply was cut
+			// short before rewriting it.
+			j.Files[i].Restored = true
+			result.Applied = append(result.Applied, f.Path)
+			continue
+		} else if hash != f.NewHash {
+			result.Conflicts = append(result.Conflicts, f.Path+": changed since the replace")
+			continue
+		}
+		orig, err := os.ReadFile(f.Backup)
+		if err != nil {
+			result.Conflicts = append(result.Conflicts, f.Path+": "+err.Error())
+			continue
+		}
+		if err := replaceFileContents(f.Path, orig); err != nil {
+			result.Conflicts = append(result.Conflicts, f.Path+": "+err.Error())
+			continue
+		}
+		j.Files[i].Restored = true
+		result.Applied = append(result.Applied, f.Path)
+	}
+
+	j.Undone = len(result.Conflicts) == 0
+	return result, j.save()
+}
+
+type textEdit struct {
+	enc           textEncoding
+	before, after string
+	count         int
+}
+
+func replaceInText(re *regexp.Regexp, replacement string, data []byte) (textEdit, bool) {
+	text, enc, ok := decodeText(data)
+	if !ok {
+		return textEdit{}, false
+	}
+	edit := textEdit{enc: enc, before: text, after: text}
+	matches := re.FindAllStringSubmatchIndex(text, -1)
+	if len(matches) == 0 {
+		return edit, true
+	}
+
+	var out []byte
+	last := 0
+	for _, m := range matches {
+		out = append(out, text[last:m[0]]...)
+		out = re.ExpandString(out, replacement, text, m)
+		last = m[1]
+	}
+	edit.after = string(append(out, text[last:]...))
+	edit.count = len(matches)
+	return edit, true
+}
+
+// walkTextFiles calls fn for each regular file under paths, or under root
+// when paths is empty, that is small enough to load. Paths must lie within
+// root; .git directories are skipped.
+func walkTextFiles(root string, paths []string, fn func(path string)) error {
+	if len(paths) == 0 {
+		paths = []string{root}
+	}
+	seen := make(map[string]bool)
+	for _, p := range paths {
+		if !withinRoot(root, p) {
+			return fmt.Errorf("%s is outside %s", p, root)
+		}
+		err := filepath.WalkDir(p, func(path string, d fs.DirEntry, err error) error {
+			if err != nil {
+				if path == p {
+					return err
+				}
+				return nil
+			}
+			if d.IsDir() {
+				if path != p && d.Name() == ".git" {
+					return filepath.SkipDir
+				}
+				return nil
+			}
+			if !d.Type().IsRegular() || seen[path] {
+				return nil
+			}
+			if info, err := d.Info(); err != nil || info.Size() > maxIndexedFileSize {
+				return nil
+			}
+			seen[path] = true
+			fn(path)
+			return nil
+		})
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// replaceFileContents writes data to path through a temporary file in the
+// same directory, so readers never see a half-written file, and keeps the
+// original permissions.
+func replaceFileContents(path string, data []byte) error {
+	info, err := os.Stat(path)
+	if err != nil {
+		return err
+	}
+	tmp, err := os.CreateTemp(filepath.Dir(path), "."+filepath.Base(path)+".*")
+	if err != nil {
+		return err
+	}
+	if _, err := tmp.Write(data); err != nil {
+		tmp.Close()
+		os.Remove(tmp.Name())
+		return err
+	}
+	if err := tmp.Close(); err != nil {
+		os.Remove(tmp.Name())
+		return err
+	}
+	if err := os.Chmod(tmp.Name(), info.Mode().Perm()); err != nil {
+		os.Remove(tmp.Name())
+		return err
+	}
+	return os.Rename(tmp.Name(), path)
+}
+
+func hashBytes(data []byte) string {
+	sum := sha256.Sum256(data)
+	return hex.EncodeToString(sum[:])
+}
+
+func replaceJournalBase() (string, error) {
+	dir, err := os.UserCacheDir()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(dir, "recursion", "replace-journal"), nil
+}
+
+// replaceJournalDir is where journal id is kept. Ids come from the
+// frontend, so anything but a well-formed id is refused rather than
+// joined into a path.
+func replaceJournalDir(id string) (string, error) {
+	if t, err := time.Parse(replaceJournalIDLayout, id); err != nil || t.Format(replaceJournalIDLayout) != id {
+		return "", fmt.Errorf("invalid replace journal id %q", id)
+	}
+	base, err := replaceJournalBase()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(base, id), nil
+}
+
+func loadReplaceJournal(id string) (*ReplaceJournal, error) {
+	dir, err := replaceJournalDir(id)
+	if err != nil {
+		return nil, err
+	}
+	data, err := os.ReadFile(filepath.Join(dir, "journal.json"))
+	if err != nil {
+		return nil, err
+	}
+	var j ReplaceJournal
+	if err := json.Unmarshal(data, &j); err != nil {
+		return nil, err
+	}
+	return &j, nil
+}
+
+func (j *ReplaceJournal) save() error {
+	dir, err := replaceJournalDir(j.ID)
+	if err != nil {
+		return err
+	}
+	data, err := json.MarshalIndent(j, "", "  ")
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(filepath.Join(dir, "journal.json"), data, 0o644)
+}
@@ -0,0 +1,151 @@
+package main
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestReplace(t *testing.T) {
+	t.Setenv("XDG_CACHE_HOME", t.TempDir())
+	root := t.TempDir()
+	writeFiles(t, root, map[string]string{
+		"a.txt":       "name: alice\nname: bob\n",
+		"b.txt":       "\xff\xfen\x00a\x00m\x00e\x00:\x00 \x00c\x00a\x00r\x00o\x00l\x00",
+		"c.txt":       "name: d\xe9sir\xe9e\n",
+		"skip.txt":    "name: eve\n",
+		"bin.dat":     "name: \x00",
+		".git/config": "name: git\n",
+	})
+	a := NewApp()
+	req := ReplaceRequest{Root: root, Pattern: `name: (\w+)`, Replacement: "user=${1}"}
+
+	preview, err := a.PreviewReplace(req)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(preview.Files) != 4 || preview.Count != 5 {
+		t.Fatalf("preview has %d files, %d matches; want 4, 5", len(preview.Files), preview.Count)
+	}
+	var selected []ReplaceSelection
+	for _, f := range preview.Files {
+		if filepath.Base(f.Path) == "a.txt" && !strings.Contains(f.Diff, "+user=alice\n") {
+			t.Errorf("a.txt diff:\n%s", f.Diff)
+		}
+		if filepath.Base(f.Path) != "skip.txt" {
+			selected = append(selected, ReplaceSelection{f.Path, f.Hash})
+		}
+	}
+
+	// An edit after the preview turns c.txt into a conflict.
+	writeFiles(t, root, map[string]string{"c.txt": "name: changed\n"})
+	result, err := a.ApplyReplace(ApplyReplaceRequest{Replace: req, Selected: selected})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(result.Applied) != 2 || len(result.Conflicts) != 1 {
+		t.Fatalf("applied %v, conflicts %v", result.Applied, result.Conflicts)
+	}
+
+	tests := []struct {
+		file, after string
+	}{
+		{"a.txt", "user=alice\nuser=bob\n"},
+		{"b.txt", "\xff\xfeu\x00s\x00e\x00r\x00=\x00c\x00a\x00r\x00o\x00l\x00"},
+		{"c.txt", "name: changed\n"},
+		{"skip.txt", "name: eve\n"},
+	}
+	for _, tt := range tests {
+		if got, _ := os.ReadFile(filepath.Join(root, tt.file)); string(got) != tt.after {
+			t.Errorf("after replace, %s = %q, want %q", tt.file, got, tt.after)
+		}
+	}
+
+	// Undo restores what it can and leaves files edited since alone.
+	writeFiles(t, root, map[string]string{"a.txt": "edited again\n"})
+	history, err := a.ReplaceHistory()
+	if err != nil || len(history.Journals) != 1 || history.Journals[0].ID != result.JournalID {
+		t.Fatalf("history = %+v, %v", history, err)
+	}
+	undo, err := a.UndoReplace(UndoReplaceRequest{ID: result.JournalID})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(undo.Applied) != 1 || len(undo.Conflicts) != 1 {
+		t.Errorf("undo applied %v, conflicts %v", undo.Applied, undo.Conflicts)
+	}
+	if got, _ := os.ReadFile(filepath.Join(root, "b.txt")); !strings.HasPrefix(string(got), "\xff\xfen\x00") {
+		t.Errorf("b.txt not restored: %q", got)
+	}
+
+	// The conflict stays until a.txt is back as the replace left it, and
+	// then a retry restores it.
+	retries := []struct {
+		before    string
+		applied   int
+		conflicts int
+		undone    bool
+	}{
+		{"", 0, 1, false},
+		{"user=alice\nuser=bob\n", 1, 0, true},
+	}
+	for i, tt := range retries {
+		if tt.before != "" {
+			writeFiles(t, root, map[string]string{"a.txt": tt.before})
+		}
+		undo, err := a.UndoReplace(UndoReplaceRequest{ID: result.JournalID})
+		if err != nil {
+			t.Fatalf("retry %d: %v", i, err)
+		}
+		if len(undo.Applied) != tt.applied || len(undo.Conflicts) != tt.conflicts {
+			t.Errorf("retry %d applied %v, conflicts %v", i, undo.Applied, undo.Conflicts)
+		}
+		j, err := loadReplaceJournal(result.JournalID)
+		if err != nil || j.Undone != tt.undone {
+			t.Errorf("retry %d: journal undone = %v, %v", i, j != nil && j.Undone, err)
+		}
+	}
+	if got, _ := os.ReadFile(filepath.Join(root, "a.txt")); string(got) != "name: alice\nname: bob\n" {
+		t.Errorf("a.txt not restored: %q", got)
+	}
+	if _, err := a.UndoReplace(UndoReplaceRequest{ID: result.JournalID}); err == nil {
+		t.Error("undo after a complete undo succeeded, want error")
+	}
+}
+
+func TestReplaceJournalIDs(t *testing.T) {
+	t.Setenv("XDG_CACHE_HOME", t.TempDir())
+	tests := []struct {
+		id string
+		ok bool
+	}{
+		{"20240102T030405.000000006Z", true},
+		{"", false},
+		{"../x", false},
+		{"20240102T030405.000000006Z/..", false},
+		{"../20240102T030405.000000006Z", false},
+		{"20240102T030405Z", false},
+	}
+	for _, tt := range tests {
+		_, err := replaceJournalDir(tt.id)
+		if (err == nil) != tt.ok {
+			t.Errorf("replaceJournalDir(%q) error = %v, want ok %v", tt.id, err, tt.ok)
+		}
+	}
+	if _, err := NewApp().UndoReplace(UndoReplaceRequest{ID: "../../x"}); err == nil || !strings.Contains(err.Error(), "invalid") {
+		t.Errorf("undo of a bad id: %v", err)
+	}
+}
+
+func TestPreviewReplaceTooManyChanges(t *testing.T) {
+	root := t.TempDir()
+	writeFiles(t, root, map[string]string{"big.txt": strings.Repeat("x\n", defaultDiffEdits)})
+	preview, err := NewApp().PreviewReplace(ReplaceRequest{Root: root, Pattern: "x", Replacement: "y"})
+	if err != nil || len(preview.Files) != 1 {
+		t.Fatalf("preview = %+v, %v", preview, err)
+	}
+	if f := preview.Files[0]; f.Diff != "" || !strings.Contains(f.Summary, "too many") || f.Count != defaultDiffEdits {
+		t.Errorf("preview of big.txt = count %d, diff %d bytes, summary %q", f.Count, len(f.Diff), f.Summary)
+	}
+}
@@ -0,0 +1,453 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/fsnotify/fsnotify"
+)
+
+// Rule acts on files under Root when the watcher reports one of Events
+// and every condition holds.
+type Rule struct {
+	Name string `json:"name"`
+	Root string `json:"root"`
+	// Events is any of "create", "write", "rename" and "remove"; empty
+	// means "create".
+	Events     []string        `json:"events"`
+	Conditions []RuleCondition `json:"conditions"`
+	Action     RuleAction      `json:"action"`
+}
+
+type RuleCondition struct {
+	// Field is "name", "ext", "path", "type" ("file" or "folder"), "size"
+	// in bytes, which folders never match, or "age" since the last
+	// modification.
+	Field string `json:"field"`
+	// Op is "is", "not", "glob" or "regex" for text fields and "gt" or
+	// "lt" for size and age.
+	Op string `json:"op"`
+	// Value is a size like "1GB" for size, and a duration like "24h" for
+	// age.
+	Value string `json:"value"`
+}
+
+type RuleAction struct {
+	// Type is "move", "copy", "delete" or "alert".
+	Type string `json:"type"`
+	// Dest is the directory to move or copy into, relative to the rule's
+	// Root unless absolute. {YYYY}, {MM} and {DD} expand to the file's
+	// modification date, {ext} to its extension without the dot.
+	Dest    string `json:"dest"`
+	Message string `json:"message"`
+}
+
+type RuleLogEntry struct {
+	Time   time.Time `json:"time" ts_type:"string"`
+	Rule   string    `json:"rule"`
+	Event  string    `json:"event"`
+	Path   string    `json:"path"`
+	Action string    `json:"action"`
+	Dest   string    `json:"dest,omitempty"`
+	// DryRun is set when the action was only logged.
+	DryRun  bool   `json:"dryRun"`
+	Message string `json:"message,omitempty"`
+	Error   string `json:"error,omitempty"`
+}
+
+const (
+	// rulesDelay lets a file settle before rules look at it, so a download
+	// isn't moved while it is still being written.
+	rulesDelay = time.Second
+	// rulesMaxDelay bounds the wait from the first pending event, so a
+	// folder that never settles is still flushed.
+	rulesMaxDelay = 10 * time.Second
+	// rulesMadeTTL is how long a file the rules put in place is ignored,
+	// which covers the flush its own events come in.
+	rulesMadeTTL = time.Minute
+	maxRuleLog   = 500
+)
+
+type compiledRule struct {
+	Rule
+	events map[string]bool
+	conds  []ruleCond
+}
+
+type ruleCond struct {
+	RuleCondition
+	re  *regexp.Regexp
+	num float64
+}
+
+type SetRulesRequest struct {
+	Rules []Rule `json:"rules"`
+	// DryRun logs matches without acting on them.
+	DryRun bool `json:"dryRun"`
+}
+
+type RuleLogEntries struct {
+	Entries []RuleLogEntry `json:"entries"`
+}
+
+// SetRules replaces the active automation rules and starts watching their
+// roots. With req.DryRun set, matches are logged but nothing is changed. Each
+// match is emitted as a "rules:log" event, and alerts also as
+// "rules:alert".
+func (a *App) SetRules(req SetRulesRequest) error {
+	compiled := make([]compiledRule, 0, len(req.Rules))
+	for _, r := range req.Rules {
+		cr, err := compileRule(r)
+		if err != nil {
+			return fmt.Errorf("rule %q: %w", r.Name, err)
+		}
+		compiled = append(compiled, cr)
+	}
+	a.StopRules()
+
+	var watches []*treeWatcher
+	roots := make(map[string]bool)
+	for _, r := range compiled {
+		if roots[r.Root] {
+			continue
+		}
+		roots[r.Root] = true
+		tw, err := newTreeWatcher(r.Root, a.queueRuleEvent)
+		if err != nil {
+			for _, w := range watches {
+				w.Close()
+			}
+			return err
+		}
+		watches = append(watches, tw)
+	}
+
+	a.rulesMu.Lock()
+	a.rules = compiled
+	a.rulesDryRun = req.DryRun
+	a.rulesWatch = watches
+	a.rulesMu.Unlock()
+	return nil
+}
+
+func (a *App) StopRules() {
+	a.rulesMu.Lock()
+	watches := a.rulesWatch
+	a.rulesWatch = nil
+	a.rules = nil
+	if a.rulesTimer != nil {
+		a.rulesTimer.Stop()
+		a.rulesTimer = nil
+	}
+	a.rulesPending = nil
+	a.rulesMade = nil
+	a.rulesMu.Unlock()
+	for _, tw := range watches {
+		tw.Close()
+	}
+}
+
+// RuleLog returns what the rules did, or would have done in a dry run,
+// newest first.
+func (a *App) RuleLog() RuleLogEntries {
+	a.rulesMu.Lock()
+	defer a.rulesMu.Unlock()
+	out := make([]RuleLogEntry, len(a.rulesLog))
+	for i, e := range a.rulesLog {
+		out[len(a.rulesLog)-1-i] = e
+	}
+	return RuleLogEntries{Entries: out}
+}
+
+func (a *App) queueRuleEvent(ev fsnotify.Event) {
+	event := ""
+	switch {
+	case ev.Has(fsnotify.Create):
+		event = "create"
+	case ev.Has(fsnotify.Write):
+		event = "write"
+	case ev.Has(fsnotify.Rename):
+		event = "rename"
+	case ev.Has(fsnotify.Remove):
+		event = "remove"
+	default:
+		return
+	}
+
+	a.rulesMu.Lock()
+	defer a.rulesMu.Unlock()
+	if a.rulesWatch == nil {
+		return
+	}
+	if a.rulesPending == nil {
+		a.rulesPending = make(map[string]string)
+	}
+	// A file that is created and then written is still new.
+	if !(event == "write" && a.rulesPending[ev.Name] == "create") {
+		a.rulesPending[ev.Name] = event
+	}
+	now := time.Now()
+	if a.rulesTimer == nil {
+		a.rulesFirst = now
+		a.rulesTimer = time.AfterFunc(rulesDelay, a.flushRules)
+	} else {
+		a.rulesTimer.Reset(rulesFlushDelay(a.rulesFirst, now))
+	}
+}
+
+// rulesFlushDelay is how long to wait after an event at now, when the
+// first pending event came at first.
+func rulesFlushDelay(first, now time.Time) time.Duration {
+	return max(0, min(rulesDelay, first.Add(rulesMaxDelay).Sub(now)))
+}
+
+func (a *App) flushRules() {
+	a.rulesMu.Lock()
+	pending := a.rulesPending
+	a.rulesPending = nil
+	a.rulesTimer = nil
+	rules, dryRun := a.rules, a.rulesDryRun
+	// Files the rules put in place themselves aren't acted on again, so a
+	// destination under a watched root can't loop.
+	now := time.Now()
+	var skip []string
+	for path, made := range a.rulesMade {
+		if _, ok := pending[path]; ok {
+			skip = append(skip, path)
+			delete(a.rulesMade, path)
+		} else if now.Sub(made) > rulesMadeTTL {
+			delete(a.rulesMade, path)
+		}
+	}
+	a.rulesMu.Unlock()
+	for _, path := range skip {
+		delete(pending, path)
+	}
+
+	for path, event := range pending {
+		for _, e := range runRules(rules, path, event, dryRun) {
+			a.rulesMu.Lock()
+			if e.Dest != "" && !e.DryRun && e.Error == "" {
+				if a.rulesMade == nil {
+					a.rulesMade = make(map[string]time.Time)
+				}
+				a.rulesMade[e.Dest] = time.Now()
+			}
+			a.rulesLog = append(a.rulesLog, e)
+			if n := len(a.rulesLog); n > maxRuleLog {
+				a.rulesLog = append([]RuleLogEntry(nil), a.rulesLog[n-maxRuleLog:]...)
+			}
+			a.rulesMu.Unlock()
+			a.emit("rules:log", e)
+			if e.Action == "alert" {
+				a.emit("rules:alert", e)
+			}
+		}
+	}
+}
+
+// runRules applies the rules matching event on path in order, stopping
+// after one that moves or deletes it.
+func runRules(rules []compiledRule, path, event string, dryRun bool) []RuleLogEntry {
+	var entries []RuleLogEntry
+	info, statErr := os.Lstat(path)
+	if statErr != nil && event != "remove" && event != "rename" {
+		return nil
+	}
+	for _, r := range rules {
+		if !r.events[event] || !withinRoot(r.Root, path) || path == r.Root {
+			continue
+		}
+		if !r.matches(path, info) {
+			continue
+		}
+
+		e := RuleLogEntry{Time: time.Now(), Rule: r.Name, Event: event, Path: path, Action: r.Action.Type, DryRun: dryRun, Message: r.Action.Message}
+		if r.Action.Type == "move" || r.Action.Type == "copy" {
+			if info == nil {
+				continue
+			}
+			e.Dest = freePath(filepath.Join(r.destDir(info), filepath.Base(path)))
+		}
+		if !dryRun {
+			var err error
+			switch r.Action.Type {
+			case "move":
+				err = movePath(path, e.Dest)
+			case "copy":
+				err = copyPath(path, e.Dest)
+			case "delete":
+				err = os.RemoveAll(path)
+			}
+			if err != nil {
+				e.Error = err.Error()
+			}
+		}
+		entries = append(entries, e)
+		if r.Action.Type == "move" || r.Action.Type == "delete" {
+			break
+		}
+	}
+	return entries
+}
+
+func compileRule(r Rule) (compiledRule, error) {
+	if r.Root == "" {
+		return compiledRule{}, errors.New("no root")
+	}
+	r.Root = filepath.Clean(expandPath(r.Root))
+	cr := compiledRule{Rule: r, events: make(map[string]bool)}
+	if len(r.Events) == 0 {
+		r.Events = []string{"create"}
+	}
+	for _, ev := range r.Events {
+		switch ev {
+		case "create", "write", "rename", "remove":
+			cr.events[ev] = true
+		default:
+			return cr, fmt.Errorf("unknown event %q", ev)
+		}
+	}
+
+	switch r.Action.Type {
+	case "move", "copy":
+		if r.Action.Dest == "" {
+			return cr, errors.New(r.Action.Type + " needs a destination")
+		}
+	case "delete", "alert":
+	default:
+		return cr, fmt.Errorf("unknown action %q", r.Action.Type)
+	}
+
+	for _, c := range r.Conditions {
+		rc := ruleCond{RuleCondition: c}
+		var err error
+		switch c.Field {
+		case "name", "ext", "path", "type":
+			switch c.Op {
+			case "is", "not":
+			case "glob":
+				_, err = filepath.Match(c.Value, "")
+			case "regex":
+				rc.re, err = regexp.Compile(c.Value)
+			default:
+				err = fmt.Errorf("%s can't be compared with %q", c.Field, c.Op)
+			}
+		case "size", "age":
+			if c.Op != "gt" && c.Op != "lt" {
+				err = fmt.Errorf("%s can't be compared with %q", c.Field, c.Op)
+			} else if c.Field == "size" {
+				var n int64
+				n, err = parseByteSize(c.Value)
+				rc.num = float64(n)
+			} else {
+				var d time.Duration
+				d, err = time.ParseDuration(c.Value)
+				rc.num = d.Seconds()
+			}
+		default:
+			err = fmt.Errorf("unknown field %q", c.Field)
+		}
+		if err != nil {
+			return cr, err
+		}
+		cr.conds = append(cr.conds, rc)
+	}
+	return cr, nil
+}
+
+// matches reports whether every condition holds. info is nil for a path
+// that no longer exists, which fails every condition on its metadata.
+func (r compiledRule) matches(path string, info os.FileInfo) bool {
+	for _, c := range r.conds {
+		var text string
+		var num float64
+		switch c.Field {
+		case "name":
+			text = filepath.Base(path)
+		case "ext":
+			text = strings.ToLower(filepath.Ext(path))
+		case "path":
+			text = path
+		case "type":
+			if info == nil {
+				return false
+			}
+			text = "file"
+			if info.IsDir() {
+				text = "folder"
+			}
+		case "size":
+			if info == nil || info.IsDir() {
+				return false
+			}
+			num = float64(info.Size())
+		case "age":
+			if info == nil {
+				return false
+			}
+			num = time.Since(info.ModTime()).Seconds()
+		}
+
+		ok := false
+		switch c.Op {
+		case "is":
+			ok = strings.EqualFold(text, c.Value)
+		case "not":
+			ok = !strings.EqualFold(text, c.Value)
+		case "glob":
+			ok, _ = filepath.Match(c.Value, text)
+		case "regex":
+			ok = c.re.MatchString(text)
+		case "gt":
+			ok = num > c.num
+		case "lt":
+			ok = num < c.num
+		}
+		if !ok {
+			return false
+		}
+	}
+	return true
+}
+
+func (r compiledRule) destDir(info os.FileInfo) string {
+	mod := info.ModTime()
+	dest := strings.NewReplacer(
+		"{YYYY}", mod.Format("2006"),
+		"{MM}", mod.Format("01"),
+		"{DD}", mod.Format("02"),
+		"{ext}", strings.TrimPrefix(strings.ToLower(filepath.Ext(info.Name())), "."),
+	).Replace(expandPath(r.Action.Dest))
+	if !filepath.IsAbs(dest) {
+		dest = filepath.Join(r.Root, dest)
+	}
+	return dest
+}
+
+// parseByteSize reads sizes like "512", "10KB" or "1.5 GB", with binary
+// multiples.
+func parseByteSize(size string) (int64, error) {
+	s := strings.ToUpper(strings.TrimSpace(size))
+	mult := int64(1)
+	for _, u := range []struct {
+		suffix string
+		mult   int64
+	}{{"TB", 1 << 40}, {"GB", 1 << 30}, {"MB", 1 << 20}, {"KB", 1 << 10}, {"B", 1}} {
+		if strings.HasSuffix(s, u.suffix) {
+			s, mult = strings.TrimSpace(strings.TrimSuffix(s, u.suffix)), u.mult
+			break
+		}
+	}
+	n, err := strconv.ParseFloat(s, 64)
+	if err != nil || n < 0 {
+		return 0, fmt.Errorf("invalid size %q", size)
+	}
+	return int64(n * float64(mult)), nil
+}
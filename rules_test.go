@@ -0,0 +1,183 @@
+package main
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func TestRuleConditions(t *testing.T) {
+	root := t.TempDir()
+	writeFiles(t, root, map[string]string{"Report.PDF": "12345", "notes.txt": "1"})
+	old := time.Now().Add(-48 * time.Hour)
+	os.Chtimes(filepath.Join(root, "notes.txt"), old, old)
+
+	tests := []struct {
+		name string
+		cond RuleCondition
+		file string
+		want bool
+	}{
+		{"ext is", RuleCondition{"ext", "is", ".pdf"}, "Report.PDF", true},
+		{"ext not", RuleCondition{"ext", "not", ".pdf"}, "Report.PDF", false},
+		{"name glob", RuleCondition{"name", "glob", "*.txt"}, "notes.txt", true},
+		{"name regex", RuleCondition{"name", "regex", "^Rep"}, "notes.txt", false},
+		{"type", RuleCondition{"type", "is", "file"}, "notes.txt", true},
+		{"size gt", RuleCondition{"size", "gt", "4B"}, "Report.PDF", true},
+		{"size lt", RuleCondition{"size", "lt", "1KB"}, "Report.PDF", true},
+		{"size gt GB", RuleCondition{"size", "gt", "1GB"}, "Report.PDF", false},
+		{"age gt", RuleCondition{"age", "gt", "24h"}, "notes.txt", true},
+		{"age lt", RuleCondition{"age", "lt", "24h"}, "notes.txt", false},
+	}
+	for _, tt := range tests {
+		r, err := compileRule(Rule{Root: root, Conditions: []RuleCondition{tt.cond}, Action: RuleAction{Type: "alert"}})
+		if err != nil {
+			t.Fatalf("%s: %v", tt.name, err)
+		}
+		path := filepath.Join(root, tt.file)
+		info, _ := os.Lstat(path)
+		if got := r.matches(path, info); got != tt.want {
+			t.Errorf("%s: matches = %v, want %v", tt.name, got, tt.want)
+		}
+	}
+
+	invalid := []Rule{
+		{Action: RuleAction{Type: "alert"}},
+		{Root: root, Action: RuleAction{Type: "explode"}},
+		{Root: root, Action: RuleAction{Type: "move"}},
+		{Root: root, Events: []string{"open"}, Action: RuleAction{Type: "alert"}},
+		{Root: root, Conditions: []RuleCondition{{"size", "is", "1"}}, Action: RuleAction{Type: "alert"}},
+		{Root: root, Conditions: []RuleCondition{{"size", "gt", "lots"}}, Action: RuleAction{Type: "alert"}},
+		{Root: root, Conditions: []RuleCondition{{"name", "regex", "("}}, Action: RuleAction{Type: "alert"}},
+	}
+	for i, r := range invalid {
+		if _, err := compileRule(r); err == nil {
+			t.Errorf("invalid rule %d compiled", i)
+		}
+	}
+}
+
+func TestRules(t *testing.T) {
+	root := t.TempDir()
+	rules := []Rule{
+		{
+			Name:       "file pdfs",
+			Root:       root,
+			Conditions: []RuleCondition{{"ext", "is", ".pdf"}},
+			Action:     RuleAction{Type: "move", Dest: "Documents/{YYYY}/{MM}"},
+		},
+		{
+			Name:       "big files",
+			Root:       root,
+			Conditions: []RuleCondition{{"size", "gt", "10B"}},
+			Action:     RuleAction{Type: "alert", Message: "large file"},
+		},
+	}
+	a := NewApp()
+	waitLog := func(n int) []RuleLogEntry {
+		t.Helper()
+		deadline := time.Now().Add(5 * time.Second)
+		for {
+			log := a.RuleLog().Entries
+			if len(log) >= n {
+				return log
+			}
+			if time.Now().After(deadline) {
+				t.Fatalf("rule log has %d entries, want %d", len(log), n)
+			}
+			time.Sleep(50 * time.Millisecond)
+		}
+	}
+
+	// A dry run logs the move but leaves the file alone.
+	if err := a.SetRules(SetRulesRequest{Rules: rules, DryRun: true}); err != nil {
+		t.Fatal(err)
+	}
+	writeFiles(t, root, map[string]string{"a.pdf": "pdf"})
+	log := waitLog(1)
+	if !log[0].DryRun || log[0].Action != "move" || log[0].Dest == "" {
+		t.Errorf("dry run entry = %+v", log[0])
+	}
+	if _, err := os.Stat(filepath.Join(root, "a.pdf")); err != nil {
+		t.Errorf("dry run moved a.pdf: %v", err)
+	}
+
+	if err := a.SetRules(SetRulesRequest{Rules: rules}); err != nil {
+		t.Fatal(err)
+	}
+	defer a.StopRules()
+	writeFiles(t, root, map[string]string{"b.pdf": "pdf", "big.bin": "more than ten bytes"})
+	log = waitLog(3)
+	time.Sleep(2 * rulesDelay)
+
+	now := time.Now()
+	moved := filepath.Join(root, "Documents", now.Format("2006"), now.Format("01"), "b.pdf")
+	if _, err := os.Stat(moved); err != nil {
+		t.Errorf("b.pdf was not moved: %v", err)
+	}
+	actions := map[string]int{}
+	for _, e := range a.RuleLog().Entries {
+		if !e.DryRun {
+			actions[e.Action]++
+		}
+		if e.Error != "" {
+			t.Errorf("%+v", e)
+		}
+	}
+	// The moved file lands under the watched root but isn't moved again.
+	if actions["move"] != 1 || actions["alert"] != 1 {
+		t.Errorf("actions = %v, want one move and one alert", actions)
+	}
+}
+
+func TestRulesFlushDelay(t *testing.T) {
+	first := time.Now()
+	tests := []struct {
+		since time.Duration
+		want  time.Duration
+	}{
+		{0, rulesDelay},
+		{rulesMaxDelay - rulesDelay, rulesDelay},
+		{rulesMaxDelay - rulesDelay/2, rulesDelay / 2},
+		{rulesMaxDelay, 0},
+		{2 * rulesMaxDelay, 0},
+	}
+	for _, tt := range tests {
+		if got := rulesFlushDelay(first, first.Add(tt.since)); got != tt.want {
+			t.Errorf("%v after the first event: delay %v, want %v", tt.since, got, tt.want)
+		}
+	}
+}
+
+func TestRulesMadeExpire(t *testing.T) {
+	a := NewApp()
+	now := time.Now()
+	a.rulesMade = map[string]time.Time{
+		"/old":     now.Add(-2 * rulesMadeTTL),
+		"/recent":  now,
+		"/pending": now,
+	}
+	a.rulesPending = map[string]string{"/pending": "create"}
+	a.flushRules()
+	if _, ok := a.rulesMade["/recent"]; len(a.rulesMade) != 1 || !ok {
+		t.Errorf("made files left = %v, want only /recent", a.rulesMade)
+	}
+}
+
+func TestParseByteSize(t *testing.T) {
+	tests := []struct {
+		in   string
+		want int64
+	}{
+		{"512", 512},
+		{"10KB", 10 << 10},
+		{"1.5 gb", 3 << 29},
+		{"2TB", 2 << 40},
+	}
+	for _, tt := range tests {
+		if got, err := parseByteSize(tt.in); err != nil || got != tt.want {
+			t.Errorf("parseByteSize(%q) = %d, %v; want %d", tt.in, got, err, tt.want)
+		}
+	}
+}
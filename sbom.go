@@ -0,0 +1,407 @@
+package main
+
+import (
+	"bytes"
+	"crypto/rand"
+	"debug/buildinfo"
+	"encoding/base64"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+
+	"golang.org/x/mod/modfile"
+)
+
+type Dependency struct {
+	Name      string `json:"name"`
+	Version   string `json:"version"`
+	Ecosystem string `json:"ecosystem"`
+	PURL      string `json:"purl"`
+	Source    string `json:"source"`
+	License   string `json:"license,omitempty"`
+	SHA512    string `json:"sha512,omitempty"`
+	Dev       bool   `json:"dev,omitempty"`
+}
+
+type DependencyList struct {
+	Dependencies []Dependency `json:"dependencies"`
+}
+
+type SBOMRequest struct {
+	Root string `json:"root"`
+	// Format is "cyclonedx" or "spdx".
+	Format string `json:"format"`
+	// Dest is where ExportSBOM writes the document; GenerateSBOM ignores it.
+	Dest string `json:"dest"`
+}
+
+type SBOMDocument struct {
+	// Document is the SBOM as JSON.
+	Document string `json:"document"`
+}
+
+// ScanDependencies collects the dependencies declared by go.mod and
+// package-lock.json files under req.Root, and those embedded in the build
+// info of Go binaries found there. Each package version is listed once,
+// with the first file it was found in. go.sum files are left out: they
+// also hold modules only consulted for their go.mod, or no longer used,
+// while go.mod requires every module in the build since Go 1.17.
+func (a *App) ScanDependencies(req RootRequest) (DependencyList, error) {
+	deps, err := collectDependencies(req.Root)
+	if err != nil {
+		return DependencyList{}, err
+	}
+	return DependencyList{Dependencies: deps}, nil
+}
+
+// GenerateSBOM returns an SBOM for req.Root as CycloneDX 1.5 ("cyclonedx") or
+// SPDX 2.3 ("spdx") JSON.
+func (a *App) GenerateSBOM(req SBOMRequest) (SBOMDocument, error) {
+	root, format := req.Root, req.Format
+	deps, err := collectDependencies(root)
+	if err != nil {
+		return SBOMDocument{}, err
+	}
+
+	var doc interface{}
+	switch format {
+	case "cyclonedx":
+		doc = cycloneDXDocument(filepath.Base(root), deps)
+	case "spdx":
+		doc = spdxDocument(filepath.Base(root), deps)
+	default:
+		return SBOMDocument{}, fmt.Errorf("unknown SBOM format %q", format)
+	}
+
+	out, err := json.MarshalIndent(doc, "", "  ")
+	if err != nil {
+		return SBOMDocument{}, err
+	}
+	return SBOMDocument{Document: string(out)}, nil
+}
+
+// ExportSBOM writes the SBOM produced by GenerateSBOM to req.Dest.
+func (a *App) ExportSBOM(req SBOMRequest) error {
+	sbom, err := a.GenerateSBOM(req)
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(req.Dest, []byte(sbom.Document+"\n"), 0o644)
+}
+
+func collectDependencies(root string) ([]Dependency, error) {
+	var deps []Dependency
+	seen := make(map[string]int)
+	add := func(d Dependency) {
+		d.PURL = packageURL(d.Ecosystem, d.Name, d.Version)
+		if i, ok := seen[d.PURL]; ok {
+			if deps[i].SHA512 == "" {
+				deps[i].SHA512 = d.SHA512
+			}
+			if deps[i].License == "" {
+				deps[i].License = d.License
+			}
+			deps[i].Dev = deps[i].Dev && d.Dev
+			return
+		}
+		seen[d.PURL] = len(deps)
+		deps = append(deps, d)
+	}
+
+	err := filepath.WalkDir(root, func(path string, d fs.DirEntry, err error) error {
+		if err != nil {
+			if path == root {
+				return err
+			}
+			return nil
+		}
+		if d.IsDir() {
+			if path != root && d.Name() == ".git" {
+				return filepath.SkipDir
+			}
+			return nil
+		}
+		if !d.Type().IsRegular() {
+			return nil
+		}
+
+		switch d.Name() {
+		case "go.mod":
+			readGoModDependencies(path, add)
+		case "package-lock.json":
+			readPackageLock(path, add)
+		default:
+			readGoBinaryDependencies(path, add)
+		}
+		return nil
+	})
+
+	sort.SliceStable(deps, func(i, j int) bool { return deps[i].PURL < deps[j].PURL })
+	return deps, err
+}
+
+func readGoModDependencies(path string, add func(Dependency)) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return
+	}
+	f, err := modfile.ParseLax(path, data, nil)
+	if err != nil {
+		return
+	}
+	for _, r := range f.Require {
+		add(Dependency{Name: r.Mod.Path, Version: r.Mod.Version, Ecosystem: "Go", Source: path})
+	}
+}
+
+type packageLock struct {
+	Packages     map[string]packageLockItem `json:"packages"`
+	Dependencies map[string]json.RawMessage `json:"dependencies"`
+}
+
+type packageLockItem struct {
+	Name         string                     `json:"name"`
+	Version      string                     `json:"version"`
+	Integrity    string                     `json:"integrity"`
+	License      string                     `json:"license"`
+	Dev          bool                       `json:"dev"`
+	Link         bool                       `json:"link"`
+	Dependencies map[string]json.RawMessage `json:"dependencies"`
+}
+
+// readPackageLock handles both the "packages" map of lockfile v2 and v3,
+// keyed by install path, and the nested "dependencies" tree of v1.
+func readPackageLock(path string, add func(Dependency)) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return
+	}
+	var lock packageLock
+	if err := json.Unmarshal(data, &lock); err != nil {
+		return
+	}
+
+	if len(lock.Packages) > 0 {
+		for key, item := range lock.Packages {
+			if key == "" || item.Link || item.Version == "" {
+				continue
+			}
+			name := item.Name
+			if name == "" {
+				name = key[strings.LastIndex(key, "node_modules/")+len("node_modules/"):]
+			}
+			add(npmDependency(path, name, item))
+		}
+		return
+	}
+
+	var walk func(map[string]json.RawMessage)
+	walk = func(deps map[string]json.RawMessage) {
+		for name, raw := range deps {
+			var item packageLockItem
+			if json.Unmarshal(raw, &item) != nil || item.Version == "" {
+				continue
+			}
+			add(npmDependency(path, name, item))
+			walk(item.Dependencies)
+		}
+	}
+	walk(lock.Dependencies)
+}
+
+func npmDependency(source, name string, item packageLockItem) Dependency {
+	d := Dependency{
+		Name:      name,
+		Version:   item.Version,
+		Ecosystem: "npm",
+		Source:    source,
+		License:   item.License,
+		Dev:       item.Dev,
+	}
+	for _, integrity := range strings.Fields(item.Integrity) {
+		if digest, ok := strings.CutPrefix(integrity, "sha512-"); ok {
+			if sum, err := base64.StdEncoding.DecodeString(digest); err == nil {
+				d.SHA512 = hex.EncodeToString(sum)
+			}
+		}
+	}
+	return d
+}
+
+var executableMagic = [][]byte{
+	[]byte("\x7fELF"),
+	[]byte("MZ"),
+	{0xfe, 0xed, 0xfa, 0xce}, {0xce, 0xfa, 0xed, 0xfe},
+	{0xfe, 0xed, 0xfa, 0xcf}, {0xcf, 0xfa, 0xed, 0xfe},
+	{0xca, 0xfe, 0xba, 0xbe},
+}
+
+// readGoBinaryDependencies adds the main module and dependencies recorded
+// in a Go executable. Files that don't start like an executable are
+// skipped without being parsed.
+func readGoBinaryDependencies(path string, add func(Dependency)) {
+	f, err := os.Open(path)
+	if err != nil {
+		return
+	}
+	head := make([]byte, 4)
+	n, _ := io.ReadFull(f, head)
+	f.Close()
+
+	isExecutable := false
+	for _, magic := range executableMagic {
+		if bytes.HasPrefix(head[:n], magic) {
+			isExecutable = true
+			break
+		}
+	}
+	if !isExecutable {
+		return
+	}
+
+	info, err := buildinfo.ReadFile(path)
+	if err != nil {
+		return
+	}
+	if info.Main.Path != "" && info.Main.Version != "" && info.Main.Version != "(devel)" {
+		add(Dependency{Name: info.Main.Path, Version: info.Main.Version, Ecosystem: "Go", Source: path})
+	}
+	for _, dep := range info.Deps {
+		if dep.Replace != nil {
+			dep = dep.Replace
+		}
+		if dep.Version == "" {
+			continue
+		}
+		add(Dependency{Name: dep.Path, Version: dep.Version, Ecosystem: "Go", Source: path})
+	}
+}
+
+func packageURL(ecosystem, name, version string) string {
+	switch ecosystem {
+	case "Go":
+		return "pkg:golang/" + name + "@" + version
+	case "npm":
+		return "pkg:npm/" + strings.Replace(name, "@", "%40", 1) + "@" + version
+	}
+	return "pkg:generic/" + name + "@" + version
+}
+
+func newUUID() string {
+	var b [16]byte
+	rand.Read(b[:])
+	b[6] = b[6]&0x0f | 0x40
+	b[8] = b[8]&0x3f | 0x80
+	h := hex.EncodeToString(b[:])
+	return h[0:8] + "-" + h[8:12] + "-" + h[12:16] + "-" + h[16:20] + "-" + h[20:]
+}
+
+func cycloneDXDocument(name string, deps []Dependency) map[string]interface{} {
+	components := make([]map[string]interface{}, 0, len(deps))
+	for _, d := range deps {
+		c := map[string]interface{}{
+			"type":    "library",
+			"bom-ref": d.PURL,
+			"name":    d.Name,
+			"version": d.Version,
+			"purl":    d.PURL,
+		}
+		if d.SHA512 != "" {
+			c["hashes"] = []map[string]string{{"alg": "SHA-512", "content": d.SHA512}}
+		}
+		if d.License != "" {
+			c["licenses"] = []map[string]string{{"expression": d.License}}
+		}
+		if d.Dev {
+			c["scope"] = "excluded"
+		}
+		components = append(components, c)
+	}
+
+	return map[string]interface{}{
+		"bomFormat":    "CycloneDX",
+		"specVersion":  "1.5",
+		"serialNumber": "urn:uuid:" + newUUID(),
+		"version":      1,
+		"metadata": map[string]interface{}{
+			"timestamp": time.Now().UTC().Format(time.RFC3339),
+			"tools": map[string]interface{}{
+				"components": []map[string]string{{"type": "application", "name": "recursion"}},
+			},
+			"component": map[string]string{"type": "application", "bom-ref": "root", "name": name},
+		},
+		"components": components,
+	}
+}
+
+func spdxDocument(name string, deps []Dependency) map[string]interface{} {
+	packages := []map[string]interface{}{{
+		"name":             name,
+		"SPDXID":           "SPDXRef-Package-root",
+		"downloadLocation": "NOASSERTION",
+		"filesAnalyzed":    false,
+	}}
+	relationships := []map[string]string{{
+		"spdxElementId":      "SPDXRef-DOCUMENT",
+		"relationshipType":   "DESCRIBES",
+		"relatedSpdxElement": "SPDXRef-Package-root",
+	}}
+
+	for i, d := range deps {
+		id := fmt.Sprintf("SPDXRef-Package-%d", i+1)
+		license := d.License
+		if license == "" {
+			license = "NOASSERTION"
+		}
+		p := map[string]interface{}{
+			"name":             d.Name,
+			"SPDXID":           id,
+			"versionInfo":      d.Version,
+			"downloadLocation": "NOASSERTION",
+			"filesAnalyzed":    false,
+			"licenseConcluded": "NOASSERTION",
+			"licenseDeclared":  license,
+			"copyrightText":    "NOASSERTION",
+			"externalRefs": []map[string]string{{
+				"referenceCategory": "PACKAGE-MANAGER",
+				"referenceType":     "purl",
+				"referenceLocator":  d.PURL,
+			}},
+		}
+		if d.SHA512 != "" {
+			p["checksums"] = []map[string]string{{"algorithm": "SHA512", "checksumValue": d.SHA512}}
+		}
+		packages = append(packages, p)
+
+		r := map[string]string{
+			"spdxElementId":      "SPDXRef-Package-root",
+			"relationshipType":   "DEPENDS_ON",
+			"relatedSpdxElement": id,
+		}
+		if d.Dev {
+			r["spdxElementId"], r["relationshipType"], r["relatedSpdxElement"] = id, "DEV_DEPENDENCY_OF", "SPDXRef-Package-root"
+		}
+		relationships = append(relationships, r)
+	}
+
+	return map[string]interface{}{
+		"spdxVersion":       "SPDX-2.3",
+		"dataLicense":       "CC0-1.0",
+		"SPDXID":            "SPDXRef-DOCUMENT",
+		"name":              name,
+		"documentNamespace": "https://spdx.org/spdxdocs/" + name + "-" + newUUID(),
+		"creationInfo": map[string]interface{}{
+			"created":  time.Now().UTC().Format(time.RFC3339),
+			"creators": []string{"Tool: recursion"},
+		},
+		"packages":      packages,
+		"relationships": relationships,
+	}
+}
@@ -0,0 +1,134 @@
+package main
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+const testPackageLock = `{
+  "name": "web",
+  "lockfileVersion": 3,
+  "packages": {
+    "": {"name": "web", "version": "1.0.0"},
+    "node_modules/react": {"version": "18.2.0", "license": "MIT", "integrity": "sha512-AAAA"},
+    "node_modules/@types/node": {"version": "20.1.0", "dev": true},
+    "node_modules/foo/node_modules/react": {"version": "17.0.2"},
+    "node_modules/local": {"resolved": "packages/local", "link": true}
+  }
+}`
+
+const testPackageLockV1 = `{
+  "lockfileVersion": 1,
+  "dependencies": {
+    "lodash": {"version": "4.17.21", "dependencies": {"nested": {"version": "0.1.0"}}}
+  }
+}`
+
+func TestScanDependencies(t *testing.T) {
+	root := t.TempDir()
+	writeFiles(t, root, map[string]string{
+		"go.mod":                    "module example\n\ngo 1.23\n\nrequire github.com/BurntSushi/toml v1.5.0\n",
+		"go.sum":                    "example.com/gomodonly v1.0.0/go.mod h1:abc=\nexample.com/stale v0.3.0 h1:def=\n",
+		"web/package-lock.json":     testPackageLock,
+		"old/package-lock.json":     testPackageLockV1,
+		"zbin/not-a-binary":         "MZ but not really",
+		".git/objects/package.json": "{}",
+	})
+
+	exe, err := os.Executable()
+	if err != nil {
+		t.Fatal(err)
+	}
+	data, err := os.ReadFile(exe)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(filepath.Join(root, "zbin", "tool"), data, 0o755); err != nil {
+		t.Fatal(err)
+	}
+
+	list, err := NewApp().ScanDependencies(RootRequest{Root: root})
+	if err != nil {
+		t.Fatal(err)
+	}
+	byPURL := make(map[string]Dependency)
+	for _, d := range list.Dependencies {
+		byPURL[d.PURL] = d
+	}
+
+	tests := []struct {
+		purl   string
+		source string
+		dev    bool
+		sha512 bool
+	}{
+		{"pkg:golang/github.com/BurntSushi/toml@v1.5.0", "go.mod", false, false},
+		{"pkg:npm/react@18.2.0", "web/package-lock.json", false, true},
+		{"pkg:npm/react@17.0.2", "web/package-lock.json", false, false},
+		{"pkg:npm/%40types/node@20.1.0", "web/package-lock.json", true, false},
+		{"pkg:npm/lodash@4.17.21", "old/package-lock.json", false, false},
+		{"pkg:npm/nested@0.1.0", "old/package-lock.json", false, false},
+		{"pkg:golang/github.com/wailsapp/wails/v2@v2.11.0", "zbin/tool", false, false},
+	}
+	for _, tt := range tests {
+		d, ok := byPURL[tt.purl]
+		if !ok {
+			t.Errorf("%s missing", tt.purl)
+			continue
+		}
+		if d.Source != filepath.Join(root, filepath.FromSlash(tt.source)) {
+			t.Errorf("%s source = %s, want %s", tt.purl, d.Source, tt.source)
+		}
+		if d.Dev != tt.dev {
+			t.Errorf("%s dev = %v, want %v", tt.purl, d.Dev, tt.dev)
+		}
+		if (d.SHA512 != "") != tt.sha512 {
+			t.Errorf("%s sha512 = %q", tt.purl, d.SHA512)
+		}
+	}
+	for _, purl := range []string{"pkg:npm/local@", "pkg:golang/example.com/gomodonly@v1.0.0", "pkg:golang/example.com/stale@v0.3.0"} {
+		if _, ok := byPURL[purl]; ok {
+			t.Errorf("%s was listed", purl)
+		}
+	}
+}
+
+func TestGenerateSBOM(t *testing.T) {
+	root := t.TempDir()
+	writeFiles(t, root, map[string]string{"package-lock.json": testPackageLock})
+	a := NewApp()
+
+	tests := []struct {
+		format string
+		check  func(map[string]interface{}) bool
+	}{
+		{"cyclonedx", func(doc map[string]interface{}) bool {
+			components, _ := doc["components"].([]interface{})
+			return doc["bomFormat"] == "CycloneDX" && len(components) == 3
+		}},
+		{"spdx", func(doc map[string]interface{}) bool {
+			packages, _ := doc["packages"].([]interface{})
+			relationships, _ := doc["relationships"].([]interface{})
+			return doc["spdxVersion"] == "SPDX-2.3" && len(packages) == 4 && len(relationships) == 4
+		}},
+	}
+	for _, tt := range tests {
+		out, err := a.GenerateSBOM(SBOMRequest{Root: root, Format: tt.format})
+		if err != nil {
+			t.Fatalf("%s: %v", tt.format, err)
+		}
+		var doc map[string]interface{}
+		if err := json.Unmarshal([]byte(out.Document), &doc); err != nil {
+			t.Fatalf("%s: %v", tt.format, err)
+		}
+		if !tt.check(doc) {
+			t.Errorf("%s: unexpected document %s", tt.format, out.Document)
+		}
+	}
+
+	if _, err := a.GenerateSBOM(SBOMRequest{Root: root, Format: "xml"}); err == nil {
+		t.Error("unknown format succeeded, want error")
+	}
+}
@@ -0,0 +1,296 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"io/fs"
+	"math"
+	"math/rand/v2"
+	"os"
+	"path/filepath"
+	"time"
+)
+
+type ScanOptions struct {
+	Root string `json:"root"`
+	// Mode is "exact", which walks the whole tree, "sample", which walks
+	// random paths from the root and extrapolates, or "priority", which
+	// walks the whole tree largest-looking directories first.
+	Mode string `json:"mode"`
+	// Precision stops a sample scan once the 95% confidence interval for
+	// the byte total is within this fraction of the estimate. Defaults to
+	// 0.05.
+	Precision float64 `json:"precision"`
+	// MaxSamples caps the number of random paths. Defaults to 100000.
+	MaxSamples int `json:"maxSamples"`
+}
+
+// ScanEstimate is emitted as "scan:estimate" while a scan runs. Exact scans
+// report running totals with zero-width intervals.
+type ScanEstimate struct {
+	Root        string  `json:"root"`
+	Mode        string  `json:"mode"`
+	Samples     int     `json:"samples"`
+	DirsVisited int     `json:"dirsVisited"`
+	Files       float64 `json:"files"`
+	FilesLow    float64 `json:"filesLow"`
+	FilesHigh   float64 `json:"filesHigh"`
+	Dirs        float64 `json:"dirs"`
+	DirsLow     float64 `json:"dirsLow"`
+	DirsHigh    float64 `json:"dirsHigh"`
+	Bytes       float64 `json:"bytes"`
+	BytesLow    float64 `json:"bytesLow"`
+	BytesHigh   float64 `json:"bytesHigh"`
+	Done        bool    `json:"done"`
+}
+
+const (
+	sampleBatch      = 64
+	scanEmitInterval = 250 * time.Millisecond
+)
+
+// StartScan runs a scan of opts.Root in the background, emitting
+// "scan:estimate" events as it refines and one with Done set when it
+// finishes. A scan already running is stopped first.
+func (a *App) StartScan(opts ScanOptions) error {
+	info, err := os.Stat(opts.Root)
+	if err != nil {
+		return err
+	}
+	if !info.IsDir() {
+		return fmt.Errorf("%s is not a directory", opts.Root)
+	}
+
+	var run scanFunc
+	switch opts.Mode {
+	case "exact":
+		run = exactScan
+	case "sample":
+		run = sampleScan
+	case "priority":
+		run = priorityScan
+	default:
+		return fmt.Errorf("unknown scan mode %q", opts.Mode)
+	}
+
+	a.StopScan()
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan struct{})
+	a.scanMu.Lock()
+	a.scanCancel, a.scanDone = cancel, done
+	a.scanMu.Unlock()
+
+	go func() {
+		defer close(done)
+		est := run(ctx, opts, func(name string, data interface{}) { a.emit(name, data) })
+		est.Done = true
+		a.emit("scan:estimate", est)
+	}()
+	return nil
+}
+
+// StopScan cancels the running scan, if any, and waits for it to stop.
+func (a *App) StopScan() {
+	a.scanMu.Lock()
+	cancel, done := a.scanCancel, a.scanDone
+	a.scanCancel, a.scanDone = nil, nil
+	a.scanMu.Unlock()
+	if cancel != nil {
+		cancel()
+		<-done
+	}
+}
+
+// EstimateTree samples root until the estimate reaches opts.Precision or
+// opts.MaxSamples and returns the result without emitting events.
+func (a *App) EstimateTree(opts ScanOptions) (ScanEstimate, error) {
+	if _, err := os.Stat(opts.Root); err != nil {
+		return ScanEstimate{}, err
+	}
+	est := sampleScan(context.Background(), opts, func(string, interface{}) {})
+	est.Done = true
+	return est, nil
+}
+
+// scanFunc runs one scan mode, emitting progress through emit, and returns
+// the final estimate.
+type scanFunc func(ctx context.Context, opts ScanOptions, emit func(name string, data interface{})) ScanEstimate
+
+func exactScan(ctx context.Context, opts ScanOptions, emit func(string, interface{})) ScanEstimate {
+	est := ScanEstimate{Root: opts.Root, Mode: "exact"}
+	last := time.Now()
+	filepath.WalkDir(opts.Root, func(path string, d fs.DirEntry, err error) error {
+		if ctx.Err() != nil {
+			return ctx.Err()
+		}
+		if err != nil {
+			return nil
+		}
+		if d.IsDir() {
+			est.DirsVisited++
+			est.Dirs++
+		} else if info, err := d.Info(); err == nil {
+			est.Files++
+			est.Bytes += float64(info.Size())
+		}
+		if time.Since(last) >= scanEmitInterval {
+			last = time.Now()
+			emit("scan:estimate", exactBounds(est))
+		}
+		return nil
+	})
+	return exactBounds(est)
+}
+
+func exactBounds(est ScanEstimate) ScanEstimate {
+	est.FilesLow, est.FilesHigh = est.Files, est.Files
+	est.DirsLow, est.DirsHigh = est.Dirs, est.Dirs
+	est.BytesLow, est.BytesHigh = est.Bytes, est.Bytes
+	return est
+}
+
+func sampleScan(ctx context.Context, opts ScanOptions, emit func(string, interface{})) ScanEstimate {
+	s := newTreeSampler(rand.New(rand.NewPCG(rand.Uint64(), rand.Uint64())))
+	return s.run(ctx, opts, emit)
+}
+
+type dirSample struct {
+	files   int
+	bytes   int64
+	subdirs []string
+}
+
+// treeSampler estimates the size of a tree with Knuth's method: walk one
+// random path from the root, and count each directory on it as many times
+// as there are directories at its depth, assuming its siblings look like
+// it. The mean of many such walks is an unbiased estimate of the total.
+// Directory listings are cached, so repeated walks over the top of the
+// tree cost nothing.
+type treeSampler struct {
+	rng  *rand.Rand
+	dirs map[string]*dirSample
+	// unlisted counts directories seen in a listing but not yet read.
+	unlisted int
+	n        int
+	sum      [3]float64
+	sumSq    [3]float64
+}
+
+func newTreeSampler(rng *rand.Rand) *treeSampler {
+	return &treeSampler{rng: rng, dirs: make(map[string]*dirSample)}
+}
+
+func (s *treeSampler) run(ctx context.Context, opts ScanOptions, emit func(string, interface{})) ScanEstimate {
+	if opts.Precision <= 0 {
+		opts.Precision = 0.05
+	}
+	if opts.MaxSamples <= 0 {
+		opts.MaxSamples = 100000
+	}
+
+	est := s.estimate(opts.Root)
+	last := time.Now()
+	for s.n < opts.MaxSamples && ctx.Err() == nil {
+		for i := 0; i < sampleBatch && s.n < opts.MaxSamples; i++ {
+			s.probe(opts.Root)
+		}
+		est = s.estimate(opts.Root)
+		if s.complete() || s.n >= sampleBatch && est.BytesHigh-est.Bytes <= opts.Precision*est.Bytes {
+			break
+		}
+		if time.Since(last) >= scanEmitInterval {
+			last = time.Now()
+			emit("scan:estimate", est)
+		}
+	}
+	return est
+}
+
+func (s *treeSampler) read(dir string) *dirSample {
+	if d, ok := s.dirs[dir]; ok {
+		return d
+	}
+	d := &dirSample{}
+	entries, _ := os.ReadDir(dir)
+	for _, e := range entries {
+		if e.IsDir() {
+			d.subdirs = append(d.subdirs, filepath.Join(dir, e.Name()))
+		} else if info, err := e.Info(); err == nil {
+			d.files++
+			d.bytes += info.Size()
+		}
+	}
+	s.dirs[dir] = d
+	s.unlisted += len(d.subdirs)
+	if len(s.dirs) > 1 {
+		s.unlisted--
+	}
+	return d
+}
+
+func (s *treeSampler) probe(root string) {
+	var total [3]float64
+	weight := 1.0
+	for dir := root; ; {
+		d := s.read(dir)
+		total[0] += weight * float64(d.files)
+		total[1] += weight
+		total[2] += weight * float64(d.bytes)
+		if len(d.subdirs) == 0 {
+			break
+		}
+		weight *= float64(len(d.subdirs))
+		dir = d.subdirs[s.rng.IntN(len(d.subdirs))]
+	}
+
+	s.n++
+	for i, v := range total {
+		s.sum[i] += v
+		s.sumSq[i] += v * v
+	}
+}
+
+// complete reports whether the walks have listed every directory, in
+// which case the totals are known exactly.
+func (s *treeSampler) complete() bool {
+	return len(s.dirs) > 0 && s.unlisted == 0
+}
+
+func (s *treeSampler) estimate(root string) ScanEstimate {
+	est := ScanEstimate{Root: root, Mode: "sample", Samples: s.n, DirsVisited: len(s.dirs)}
+	if s.n == 0 {
+		return est
+	}
+
+	// The tree can't be smaller than what the walks have already listed.
+	var seen ScanEstimate
+	for _, d := range s.dirs {
+		seen.Files += float64(d.files)
+		seen.Bytes += float64(d.bytes)
+	}
+	seen.Dirs = float64(len(s.dirs))
+	if s.complete() {
+		seen.Root, seen.Mode, seen.Samples, seen.DirsVisited = est.Root, est.Mode, est.Samples, est.DirsVisited
+		return exactBounds(seen)
+	}
+
+	var mean, half [3]float64
+	n := float64(s.n)
+	for i := range mean {
+		mean[i] = s.sum[i] / n
+		if s.n > 1 {
+			variance := max(0, (s.sumSq[i]-n*mean[i]*mean[i])/(n-1))
+			half[i] = 1.96 * math.Sqrt(variance/n)
+		}
+	}
+
+	est.Files, est.FilesLow, est.FilesHigh = bounded(mean[0], half[0], seen.Files)
+	est.Dirs, est.DirsLow, est.DirsHigh = bounded(mean[1], half[1], seen.Dirs)
+	est.Bytes, est.BytesLow, est.BytesHigh = bounded(mean[2], half[2], seen.Bytes)
+	return est
+}
+
+func bounded(mean, half, floor float64) (estimate, low, high float64) {
+	estimate = max(mean, floor)
+	return estimate, max(mean-half, floor), max(mean+half, estimate)
+}
@@ -0,0 +1,115 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"math/rand/v2"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+// writeUniformTree creates a tree where every directory down to depth holds
+// fanout subdirectories and two 10-byte files.
+func writeUniformTree(t *testing.T, root string, fanout, depth int) {
+	t.Helper()
+	files := make(map[string]string)
+	var fill func(dir string, level int)
+	fill = func(dir string, level int) {
+		files[dir+"a"] = strings.Repeat("a", 10)
+		files[dir+"b"] = strings.Repeat("b", 10)
+		if level == depth {
+			return
+		}
+		for i := 0; i < fanout; i++ {
+			fill(fmt.Sprintf("%sd%d/", dir, i), level+1)
+		}
+	}
+	fill("", 0)
+	writeFiles(t, root, files)
+}
+
+func TestTreeSampler(t *testing.T) {
+	root := t.TempDir()
+	writeUniformTree(t, root, 3, 2)
+
+	tests := []struct {
+		name     string
+		probes   int
+		complete bool
+	}{
+		{"single walk", 1, false},
+		{"many walks", 500, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			s := newTreeSampler(rand.New(rand.NewPCG(1, 2)))
+			for i := 0; i < tt.probes; i++ {
+				s.probe(root)
+			}
+			est := s.estimate(root)
+			if est.Files != 26 || est.Dirs != 13 || est.Bytes != 260 {
+				t.Errorf("estimate = %v files, %v dirs, %v bytes, want 26, 13, 260", est.Files, est.Dirs, est.Bytes)
+			}
+			if s.complete() != tt.complete {
+				t.Errorf("complete = %v, want %v", s.complete(), tt.complete)
+			}
+			if est.BytesLow > est.Bytes || est.BytesHigh < est.Bytes {
+				t.Errorf("interval [%v, %v] excludes %v", est.BytesLow, est.BytesHigh, est.Bytes)
+			}
+		})
+	}
+}
+
+func TestEstimateTree(t *testing.T) {
+	root := t.TempDir()
+	writeFiles(t, root, map[string]string{
+		"top":              "0123456789",
+		"x/one":            "1",
+		"x/y/two":          "22",
+		"x/y/z/three":      "333",
+		"w/four":           "4444",
+		"w/v/u/t/s/deep":   "55555",
+		"empty/.keep":      "",
+		"wide/a/f":         "6",
+		"wide/b/f":         "6",
+		"wide/c/f":         "6",
+		"wide/c/d/e/f/g/h": "7",
+	})
+
+	exact := exactScan(context.Background(), ScanOptions{Root: root}, func(string, interface{}) {})
+	est, err := NewApp().EstimateTree(ScanOptions{Root: root, Mode: "sample"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !est.Done || est.Files != exact.Files || est.Dirs != exact.Dirs || est.Bytes != exact.Bytes {
+		t.Errorf("estimate = %+v, want exact totals %+v", est, exact)
+	}
+
+	if _, err := NewApp().EstimateTree(ScanOptions{Root: filepath.Join(root, "missing")}); err == nil {
+		t.Error("missing root succeeded, want error")
+	}
+}
+
+func TestStartScan(t *testing.T) {
+	root := t.TempDir()
+	writeUniformTree(t, root, 2, 2)
+	a := NewApp()
+
+	tests := []struct {
+		opts    ScanOptions
+		wantErr bool
+	}{
+		{ScanOptions{Root: root, Mode: "exact"}, false},
+		{ScanOptions{Root: root, Mode: "sample"}, false},
+		{ScanOptions{Root: root, Mode: "bogus"}, true},
+		{ScanOptions{Root: filepath.Join(root, "a"), Mode: "exact"}, true},
+	}
+	for _, tt := range tests {
+		err := a.StartScan(tt.opts)
+		if (err != nil) != tt.wantErr {
+			t.Errorf("StartScan(%+v) error = %v, want error %v", tt.opts, err, tt.wantErr)
+		}
+		a.StopScan()
+	}
+}
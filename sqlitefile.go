@@ -0,0 +1,508 @@
+package main
+
+import (
+	"bytes"
+	"cmp"
+	"encoding/binary"
+	"errors"
+	"math"
+	"os"
+	"sort"
+)
+
+// This file writes SQLite database files directly, following the file
+// format at https://www.sqlite.org/fileformat.html, so exports need no
+// database driver. It only ever writes a fresh database in one go: each
+// table and index becomes a packed B-tree, with the schema on page 1.
+
+const (
+	sqlitePageSize = 4096
+	// Payload thresholds from the file format, for a page without
+	// reserved bytes.
+	sqliteMaxLocalTable = sqlitePageSize - 35
+	sqliteMaxLocalIndex = (sqlitePageSize-12)*64/255 - 23
+	sqliteMinLocal      = (sqlitePageSize-12)*32/255 - 23
+
+	sqliteTableLeaf     = 0x0d
+	sqliteTableInterior = 0x05
+	sqliteIndexLeaf     = 0x0a
+	sqliteIndexInterior = 0x02
+)
+
+// sqliteTable is a table to write. Values are nil, int64, float64, string
+// or []byte. When the table has an INTEGER PRIMARY KEY, KeyColumn is its
+// index; that column is stored as the row ID.
+type sqliteTable struct {
+	Name      string
+	SQL       string
+	KeyColumn int
+	Rows      [][]any
+}
+
+type sqliteIndex struct {
+	Name    string
+	Table   string
+	SQL     string
+	Columns []int
+}
+
+type sqliteWriter struct {
+	f     *os.File
+	pages uint32
+}
+
+// writeSQLite creates a database at path holding tables and indexes.
+func writeSQLite(path string, tables []sqliteTable, indexes []sqliteIndex) error {
+	f, err := os.Create(path)
+	if err != nil {
+		return err
+	}
+	w := &sqliteWriter{f: f, pages: 1}
+	if err := w.write(tables, indexes); err != nil {
+		f.Close()
+		os.Remove(path)
+		return err
+	}
+	return f.Close()
+}
+
+func (w *sqliteWriter) write(tables []sqliteTable, indexes []sqliteIndex) error {
+	var schema [][]any
+	byName := make(map[string]*sqliteTable)
+	for i := range tables {
+		t := &tables[i]
+		byName[t.Name] = t
+		root, err := w.writeTable(t)
+		if err != nil {
+			return err
+		}
+		schema = append(schema, []any{"table", t.Name, t.Name, int64(root), t.SQL})
+	}
+	for _, ix := range indexes {
+		t := byName[ix.Table]
+		if t == nil {
+			return errors.New("index " + ix.Name + " is on unknown table " + ix.Table)
+		}
+		root, err := w.writeIndex(t, ix)
+		if err != nil {
+			return err
+		}
+		schema = append(schema, []any{"index", ix.Name, ix.Table, int64(root), ix.SQL})
+	}
+
+	// The schema table must be rooted on page 1, after the file header.
+	var cells [][]byte
+	for i, row := range schema {
+		cell, err := w.tableCell(int64(i+1), sqliteRecord(row))
+		if err != nil {
+			return err
+		}
+		cells = append(cells, cell)
+	}
+	if !sqlitePageFits(100, sqliteTableLeaf, cells) {
+		return errors.New("schema does not fit on the first page")
+	}
+	page := sqlitePage(100, sqliteTableLeaf, cells, 0)
+	copy(page, w.header())
+	_, err := w.f.WriteAt(page, 0)
+	return err
+}
+
+func (w *sqliteWriter) header() []byte {
+	h := make([]byte, 100)
+	copy(h, "SQLite format 3\x00")
+	binary.BigEndian.PutUint16(h[16:], sqlitePageSize)
+	h[18], h[19] = 1, 1 // legacy file format versions
+	h[21], h[22], h[23] = 64, 32, 32
+	binary.BigEndian.PutUint32(h[24:], 1)       // file change counter
+	binary.BigEndian.PutUint32(h[28:], w.pages) // database size in pages
+	binary.BigEndian.PutUint32(h[40:], 1)       // schema cookie
+	binary.BigEndian.PutUint32(h[44:], 4)       // schema format
+	binary.BigEndian.PutUint32(h[56:], 1)       // UTF-8
+	binary.BigEndian.PutUint32(h[92:], 1)       // version-valid-for, matching the change counter
+	binary.BigEndian.PutUint32(h[96:], 3045000)
+	return h
+}
+
+// allocate writes data as the next page and returns its number.
+func (w *sqliteWriter) allocate(data []byte) (uint32, error) {
+	w.pages++
+	_, err := w.f.WriteAt(data, int64(w.pages-1)*sqlitePageSize)
+	return w.pages, err
+}
+
+// local splits a payload into the part kept in its cell and writes the
+// rest to a chain of overflow pages, returning the cell's payload bytes
+// with the first overflow page number appended when there is one.
+func (w *sqliteWriter) local(payload []byte, maxLocal int) ([]byte, error) {
+	if len(payload) <= maxLocal {
+		return payload, nil
+	}
+	n := sqliteMinLocal + (len(payload)-sqliteMinLocal)%(sqlitePageSize-4)
+	if n > maxLocal {
+		n = sqliteMinLocal
+	}
+
+	// Write the chain back to front, so each page knows its successor.
+	rest := payload[n:]
+	var chunks [][]byte
+	for len(rest) > 0 {
+		k := min(len(rest), sqlitePageSize-4)
+		chunks = append(chunks, rest[:k])
+		rest = rest[k:]
+	}
+	next := uint32(0)
+	for i := len(chunks) - 1; i >= 0; i-- {
+		page := make([]byte, sqlitePageSize)
+		binary.BigEndian.PutUint32(page, next)
+		copy(page[4:], chunks[i])
+		pg, err := w.allocate(page)
+		if err != nil {
+			return nil, err
+		}
+		next = pg
+	}
+	out := append(append([]byte(nil), payload[:n]...), 0, 0, 0, 0)
+	binary.BigEndian.PutUint32(out[n:], next)
+	return out, nil
+}
+
+func (w *sqliteWriter) tableCell(rowid int64, record []byte) ([]byte, error) {
+	local, err := w.local(record, sqliteMaxLocalTable)
+	if err != nil {
+		return nil, err
+	}
+	cell := sqlitePutVarint(nil, uint64(len(record)))
+	cell = sqlitePutVarint(cell, uint64(rowid))
+	return append(cell, local...), nil
+}
+
+type sqliteChild struct {
+	page   uint32
+	maxKey int64
+}
+
+func (w *sqliteWriter) writeTable(t *sqliteTable) (uint32, error) {
+	var children []sqliteChild
+	var cells [][]byte
+	var last int64
+	flush := func() error {
+		pg, err := w.allocate(sqlitePage(0, sqliteTableLeaf, cells, 0))
+		children = append(children, sqliteChild{pg, last})
+		cells = nil
+		return err
+	}
+	for i, row := range t.Rows {
+		rowid := int64(i + 1)
+		if t.KeyColumn >= 0 {
+			rowid = row[t.KeyColumn].(int64)
+			row = append([]any(nil), row...)
+			row[t.KeyColumn] = nil
+		}
+		cell, err := w.tableCell(rowid, sqliteRecord(row))
+		if err != nil {
+			return 0, err
+		}
+		if !sqlitePageFits(0, sqliteTableLeaf, append(cells, cell)) {
+			if err := flush(); err != nil {
+				return 0, err
+			}
+		}
+		cells = append(cells, cell)
+		last = rowid
+	}
+	if len(cells) > 0 || len(children) == 0 {
+		if err := flush(); err != nil {
+			return 0, err
+		}
+	}
+
+	for len(children) > 1 {
+		var parents []sqliteChild
+		for i := 0; i < len(children); {
+			// Each page takes cells for children[i:k] and children[k] as
+			// its right-most pointer; never leave one child for a page of
+			// its own.
+			var cells [][]byte
+			k := i
+			for k+1 < len(children) {
+				cell := binary.BigEndian.AppendUint32(nil, children[k].page)
+				cell = sqlitePutVarint(cell, uint64(children[k].maxKey))
+				if !sqlitePageFits(0, sqliteTableInterior, append(cells, cell)) {
+					break
+				}
+				cells = append(cells, cell)
+				k++
+			}
+			if len(children)-k == 2 && k+1 < len(children) && len(cells) > 1 {
+				cells = cells[:len(cells)-1]
+				k--
+			}
+			pg, err := w.allocate(sqlitePage(0, sqliteTableInterior, cells, children[k].page))
+			if err != nil {
+				return 0, err
+			}
+			parents = append(parents, sqliteChild{pg, children[k].maxKey})
+			i = k + 1
+		}
+		children = parents
+	}
+	return children[0].page, nil
+}
+
+func (w *sqliteWriter) writeIndex(t *sqliteTable, ix sqliteIndex) (uint32, error) {
+	type entry struct {
+		key  []any
+		body []byte
+	}
+	entries := make([]entry, len(t.Rows))
+	for i, row := range t.Rows {
+		rowid := int64(i + 1)
+		if t.KeyColumn >= 0 {
+			rowid = row[t.KeyColumn].(int64)
+		}
+		key := make([]any, 0, len(ix.Columns)+1)
+		for _, c := range ix.Columns {
+			key = append(key, row[c])
+		}
+		entries[i].key = append(key, rowid)
+	}
+	sort.SliceStable(entries, func(i, j int) bool { return sqliteCompareKeys(entries[i].key, entries[j].key) < 0 })
+	for i := range entries {
+		record := sqliteRecord(entries[i].key)
+		local, err := w.local(record, sqliteMaxLocalIndex)
+		if err != nil {
+			return 0, err
+		}
+		entries[i].body = append(sqlitePutVarint(nil, uint64(len(record))), local...)
+	}
+
+	// Unlike a table, an index keeps each entry once: the entry after a
+	// full page moves up as the separator between it and the next one.
+	var children []uint32
+	var seps []int
+	for i := 0; i < len(entries) || len(children) == 0; {
+		var cells [][]byte
+		k := i
+		for k < len(entries) && sqlitePageFits(0, sqliteIndexLeaf, append(cells, entries[k].body)) {
+			cells = append(cells, entries[k].body)
+			k++
+		}
+		if k == len(entries)-1 && len(cells) > 1 {
+			cells = cells[:len(cells)-1]
+			k--
+		}
+		pg, err := w.allocate(sqlitePage(0, sqliteIndexLeaf, cells, 0))
+		if err != nil {
+			return 0, err
+		}
+		children = append(children, pg)
+		if k < len(entries) {
+			seps = append(seps, k)
+		}
+		i = k + 1
+	}
+
+	for len(children) > 1 {
+		var parents []uint32
+		var up []int
+		for i := 0; i < len(children); {
+			var cells [][]byte
+			k := i
+			for k < len(seps) {
+				cell := binary.BigEndian.AppendUint32(nil, children[k])
+				cell = append(cell, entries[seps[k]].body...)
+				if !sqlitePageFits(0, sqliteIndexInterior, append(cells, cell)) {
+					break
+				}
+				cells = append(cells, cell)
+				k++
+			}
+			if k < len(seps) && k+1 == len(children)-1 && len(cells) > 1 {
+				cells = cells[:len(cells)-1]
+				k--
+			}
+			pg, err := w.allocate(sqlitePage(0, sqliteIndexInterior, cells, children[k]))
+			if err != nil {
+				return 0, err
+			}
+			parents = append(parents, pg)
+			if k < len(seps) {
+				up = append(up, seps[k])
+			}
+			i = k + 1
+		}
+		children, seps = parents, up
+	}
+	return children[0], nil
+}
+
+func sqliteHeaderSize(typ byte) int {
+	if typ == sqliteTableInterior || typ == sqliteIndexInterior {
+		return 12
+	}
+	return 8
+}
+
+func sqlitePageFits(offset int, typ byte, cells [][]byte) bool {
+	used := offset + sqliteHeaderSize(typ)
+	for _, c := range cells {
+		used += 2 + len(c)
+	}
+	return used <= sqlitePageSize
+}
+
+// sqlitePage lays out a B-tree page: the header at offset, then the cell
+// pointers, with the cells packed against the end of the page.
+func sqlitePage(offset int, typ byte, cells [][]byte, right uint32) []byte {
+	page := make([]byte, sqlitePageSize)
+	h := page[offset:]
+	h[0] = typ
+	binary.BigEndian.PutUint16(h[3:], uint16(len(cells)))
+	if typ == sqliteTableInterior || typ == sqliteIndexInterior {
+		binary.BigEndian.PutUint32(h[8:], right)
+	}
+	ptr := offset + sqliteHeaderSize(typ)
+	end := sqlitePageSize
+	for _, c := range cells {
+		end -= len(c)
+		copy(page[end:], c)
+		binary.BigEndian.PutUint16(page[ptr:], uint16(end))
+		ptr += 2
+	}
+	// A content area starting at 65536 is written as 0, which can't
+	// happen with 4096-byte pages.
+	binary.BigEndian.PutUint16(h[5:], uint16(end))
+	return page
+}
+
+// sqliteRecord encodes values in the record format: a header of serial
+// types followed by the values.
+func sqliteRecord(values []any) []byte {
+	var types, body []byte
+	for _, v := range values {
+		switch v := v.(type) {
+		case nil:
+			types = sqlitePutVarint(types, 0)
+		case int64:
+			t, n := sqliteIntType(v)
+			types = sqlitePutVarint(types, t)
+			for i := n - 1; i >= 0; i-- {
+				body = append(body, byte(v>>(8*i)))
+			}
+		case float64:
+			types = sqlitePutVarint(types, 7)
+			body = binary.BigEndian.AppendUint64(body, math.Float64bits(v))
+		case string:
+			types = sqlitePutVarint(types, uint64(13+2*len(v)))
+			body = append(body, v...)
+		case []byte:
+			types = sqlitePutVarint(types, uint64(12+2*len(v)))
+			body = append(body, v...)
+		default:
+			panic("sqliteRecord: unsupported value type")
+		}
+	}
+	// The header size includes its own varint, which may grow it.
+	size := len(types) + 1
+	for len(sqlitePutVarint(nil, uint64(size)))+len(types) != size {
+		size++
+	}
+	out := sqlitePutVarint(nil, uint64(size))
+	return append(append(out, types...), body...)
+}
+
+// sqliteIntType returns the serial type for v and its size in bytes.
+func sqliteIntType(v int64) (uint64, int) {
+	switch {
+	case v == 0:
+		return 8, 0
+	case v == 1:
+		return 9, 0
+	case v >= -1<<7 && v < 1<<7:
+		return 1, 1
+	case v >= -1<<15 && v < 1<<15:
+		return 2, 2
+	case v >= -1<<23 && v < 1<<23:
+		return 3, 3
+	case v >= -1<<31 && v < 1<<31:
+		return 4, 4
+	case v >= -1<<47 && v < 1<<47:
+		return 5, 6
+	}
+	return 6, 8
+}
+
+// sqlitePutVarint appends v in SQLite's big-endian variable-length
+// format, where a ninth byte carries a full eight bits.
+func sqlitePutVarint(buf []byte, v uint64) []byte {
+	if v > 1<<56-1 {
+		for i := 7; i >= 0; i-- {
+			buf = append(buf, byte(v>>(8+7*i))|0x80)
+		}
+		return append(buf, byte(v))
+	}
+	var tmp [8]byte
+	n := 0
+	for {
+		tmp[n] = byte(v & 0x7f)
+		n++
+		v >>= 7
+		if v == 0 {
+			break
+		}
+	}
+	for i := n - 1; i >= 0; i-- {
+		b := tmp[i]
+		if i > 0 {
+			b |= 0x80
+		}
+		buf = append(buf, b)
+	}
+	return buf
+}
+
+// sqliteCompareKeys orders index keys as SQLite does with the BINARY
+// collation: NULL, then numbers, then text, then blobs.
+func sqliteCompareKeys(a, b []any) int {
+	for i := range a {
+		if c := sqliteCompare(a[i], b[i]); c != 0 {
+			return c
+		}
+	}
+	return 0
+}
+
+func sqliteCompare(a, b any) int {
+	rank := func(v any) int {
+		switch v.(type) {
+		case nil:
+			return 0
+		case int64, float64:
+			return 1
+		case string:
+			return 2
+		}
+		return 3
+	}
+	if ra, rb := rank(a), rank(b); ra != rb {
+		return ra - rb
+	}
+	switch a := a.(type) {
+	case int64:
+		if b, ok := b.(int64); ok {
+			return cmp.Compare(a, b)
+		}
+		return cmp.Compare(float64(a), b.(float64))
+	case float64:
+		if b, ok := b.(int64); ok {
+			return cmp.Compare(a, float64(b))
+		}
+		return cmp.Compare(a, b.(float64))
+	case string:
+		return cmp.Compare(a, b.(string))
+	case []byte:
+		return bytes.Compare(a, b.([]byte))
+	}
+	return 0
+}
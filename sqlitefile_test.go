@@ -0,0 +1,486 @@
+package main
+
+import (
+	"bytes"
+	"encoding/binary"
+	"math"
+	"os"
+	"path/filepath"
+	"reflect"
+	"sort"
+	"strings"
+	"testing"
+)
+
+// sqliteFile reads a database back following the file format, without
+// the writer's code, and fails the test on anything SQLite would reject:
+// a bad header, a page used twice or never, overlapping cells, keys out
+// of order or a B-tree of uneven depth.
+type sqliteFile struct {
+	t     *testing.T
+	data  []byte
+	pages uint32
+	seen  map[uint32]bool
+}
+
+type sqliteEntry struct {
+	rowid  int64
+	values []any
+}
+
+// sqliteContents is what readSQLiteFile found: the rows of each table and
+// the entries of each index, by name.
+type sqliteContents struct {
+	tables  map[string][]sqliteEntry
+	indexes map[string][][]any
+}
+
+func readSQLiteFile(t *testing.T, path string) sqliteContents {
+	t.Helper()
+	data, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(data) < sqlitePageSize || len(data)%sqlitePageSize != 0 {
+		t.Fatalf("file is %d bytes, not a whole number of pages", len(data))
+	}
+	h := data[:100]
+	checks := []struct {
+		what      string
+		got, want any
+	}{
+		{"magic", string(h[:16]), "SQLite format 3\x00"},
+		{"page size", int(binary.BigEndian.Uint16(h[16:])), sqlitePageSize},
+		{"format versions", [2]byte{h[18], h[19]}, [2]byte{1, 1}},
+		{"reserved bytes", h[20], byte(0)},
+		{"payload fractions", [3]byte{h[21], h[22], h[23]}, [3]byte{64, 32, 32}},
+		{"page count", int(binary.BigEndian.Uint32(h[28:])), len(data) / sqlitePageSize},
+		{"version valid for", binary.BigEndian.Uint32(h[92:]), binary.BigEndian.Uint32(h[24:])},
+		{"free pages", binary.BigEndian.Uint32(h[36:]), uint32(0)},
+		{"schema format", binary.BigEndian.Uint32(h[44:]), uint32(4)},
+		{"text encoding", binary.BigEndian.Uint32(h[56:]), uint32(1)},
+	}
+	for _, c := range checks {
+		if c.got != c.want {
+			t.Fatalf("header %s = %v, want %v", c.what, c.got, c.want)
+		}
+	}
+
+	f := &sqliteFile{t: t, data: data, pages: uint32(len(data) / sqlitePageSize), seen: map[uint32]bool{}}
+	db := sqliteContents{tables: map[string][]sqliteEntry{}, indexes: map[string][][]any{}}
+	for _, e := range f.table(1) {
+		v := e.values
+		if len(v) != 5 {
+			t.Fatalf("schema row %v has %d columns", v, len(v))
+		}
+		root := uint32(v[3].(int64))
+		switch v[0] {
+		case "table":
+			db.tables[v[1].(string)] = f.table(root)
+		case "index":
+			db.indexes[v[1].(string)] = f.index(root)
+		default:
+			t.Fatalf("schema row %v", v)
+		}
+	}
+	for n := uint32(1); n <= f.pages; n++ {
+		if !f.seen[n] {
+			t.Errorf("page %d is not used", n)
+		}
+	}
+	return db
+}
+
+// page returns page n, failing if another structure already used it.
+func (f *sqliteFile) page(n uint32) []byte {
+	f.t.Helper()
+	if n < 1 || n > f.pages {
+		f.t.Fatalf("page %d is outside the file", n)
+	}
+	if f.seen[n] {
+		f.t.Fatalf("page %d is used twice", n)
+	}
+	f.seen[n] = true
+	return f.data[(n-1)*sqlitePageSize : n*sqlitePageSize]
+}
+
+type sqliteCell struct {
+	child   uint32
+	key     int64
+	payload []byte
+}
+
+// cells parses B-tree page n.
+func (f *sqliteFile) cells(n uint32) (typ byte, cells []sqliteCell, right uint32) {
+	f.t.Helper()
+	p := f.page(n)
+	h := p
+	if n == 1 {
+		h = p[100:]
+	}
+	typ = h[0]
+	hdr := 8
+	switch typ {
+	case 0x02, 0x05:
+		hdr = 12
+		right = binary.BigEndian.Uint32(h[8:])
+	case 0x0a, 0x0d:
+	default:
+		f.t.Fatalf("page %d has type %#x", n, typ)
+	}
+	if binary.BigEndian.Uint16(h[1:]) != 0 || h[7] != 0 {
+		f.t.Errorf("page %d has free space listed", n)
+	}
+	count := int(binary.BigEndian.Uint16(h[3:]))
+	content := int(binary.BigEndian.Uint16(h[5:]))
+	if content == 0 {
+		content = 65536
+	}
+	ptrs := len(p) - len(h) + hdr
+	if ptrs+2*count > content {
+		f.t.Fatalf("page %d: cell pointers run into the content area", n)
+	}
+
+	type span struct{ start, end int }
+	var spans []span
+	for i := 0; i < count; i++ {
+		off := int(binary.BigEndian.Uint16(p[ptrs+2*i:]))
+		if off < content || off >= len(p) {
+			f.t.Fatalf("page %d: cell %d at %d is outside the content area", n, i, off)
+		}
+		c, size := f.cell(typ, p[off:])
+		spans = append(spans, span{off, off + size})
+		cells = append(cells, c)
+	}
+	sort.Slice(spans, func(i, j int) bool { return spans[i].start < spans[j].start })
+	for i := 1; i < len(spans); i++ {
+		if spans[i].start < spans[i-1].end {
+			f.t.Fatalf("page %d: cells overlap", n)
+		}
+	}
+	return typ, cells, right
+}
+
+// cell parses one cell and returns it with the bytes it takes on its page.
+func (f *sqliteFile) cell(typ byte, b []byte) (sqliteCell, int) {
+	f.t.Helper()
+	var c sqliteCell
+	i := 0
+	if typ == 0x02 || typ == 0x05 {
+		c.child = binary.BigEndian.Uint32(b)
+		i = 4
+	}
+	if typ == 0x05 {
+		key, n := sqliteVarint(b[i:])
+		c.key = int64(key)
+		return c, i + n
+	}
+	size, n := sqliteVarint(b[i:])
+	i += n
+	if typ == 0x0d {
+		key, n := sqliteVarint(b[i:])
+		c.key = int64(key)
+		i += n
+	}
+
+	// The split between the cell and its overflow pages, as the file
+	// format defines it.
+	usable := sqlitePageSize
+	maxLocal := usable - 35
+	if typ != 0x0d {
+		maxLocal = (usable-12)*64/255 - 23
+	}
+	minLocal := (usable-12)*32/255 - 23
+	local := int(size)
+	if local > maxLocal {
+		local = minLocal + (int(size)-minLocal)%(usable-4)
+		if local > maxLocal {
+			local = minLocal
+		}
+	}
+	c.payload = append([]byte(nil), b[i:i+local]...)
+	i += local
+	if local == int(size) {
+		return c, i
+	}
+	for next := binary.BigEndian.Uint32(b[i:]); next != 0; {
+		p := f.page(next)
+		next = binary.BigEndian.Uint32(p)
+		k := min(int(size)-len(c.payload), len(p)-4)
+		c.payload = append(c.payload, p[4:4+k]...)
+		if len(c.payload) == int(size) && next != 0 {
+			f.t.Fatalf("overflow chain runs past the payload")
+		}
+	}
+	if len(c.payload) != int(size) {
+		f.t.Fatalf("overflow chain holds %d of %d bytes", len(c.payload), size)
+	}
+	return c, i + 4
+}
+
+// table returns the rows of the table B-tree at root in rowid order.
+func (f *sqliteFile) table(root uint32) []sqliteEntry {
+	f.t.Helper()
+	var rows []sqliteEntry
+	leafDepth := -1
+	var walk func(n uint32, depth int)
+	walk = func(n uint32, depth int) {
+		typ, cells, right := f.cells(n)
+		if typ == 0x0d {
+			if leafDepth >= 0 && depth != leafDepth {
+				f.t.Fatalf("table %d: leaves at depths %d and %d", root, leafDepth, depth)
+			}
+			leafDepth = depth
+			for _, c := range cells {
+				if len(rows) > 0 && c.key <= rows[len(rows)-1].rowid {
+					f.t.Fatalf("table %d: rowid %d after %d", root, c.key, rows[len(rows)-1].rowid)
+				}
+				rows = append(rows, sqliteEntry{c.key, sqliteDecodeRecord(f.t, c.payload)})
+			}
+			return
+		}
+		if typ != 0x05 || len(cells) == 0 {
+			f.t.Fatalf("table %d: page %d has type %#x and %d cells", root, n, typ, len(cells))
+		}
+		// A separator is at least every rowid to its left and below every
+		// rowid to its right.
+		for i, c := range append(cells, sqliteCell{child: right}) {
+			start := len(rows)
+			walk(c.child, depth+1)
+			if len(rows) == start {
+				f.t.Fatalf("table %d: page %d has an empty subtree", root, n)
+			}
+			if i > 0 && rows[start].rowid <= cells[i-1].key {
+				f.t.Fatalf("table %d: rowid %d right of separator %d is not above it", root, rows[start].rowid, cells[i-1].key)
+			}
+			if i < len(cells) && rows[len(rows)-1].rowid > c.key {
+				f.t.Fatalf("table %d: rowid %d left of separator %d is above it", root, rows[len(rows)-1].rowid, c.key)
+			}
+		}
+	}
+	walk(root, 0)
+	return rows
+}
+
+// index returns the entries of the index B-tree at root in key order.
+func (f *sqliteFile) index(root uint32) [][]any {
+	f.t.Helper()
+	var keys [][]any
+	leafDepth := -1
+	var walk func(n uint32, depth int)
+	walk = func(n uint32, depth int) {
+		typ, cells, right := f.cells(n)
+		switch typ {
+		case 0x0a:
+			if leafDepth >= 0 && depth != leafDepth {
+				f.t.Fatalf("index %d: leaves at depths %d and %d", root, leafDepth, depth)
+			}
+			leafDepth = depth
+			if depth > 0 && len(cells) == 0 {
+				f.t.Fatalf("index %d: leaf page %d is empty", root, n)
+			}
+		case 0x02:
+			if len(cells) == 0 {
+				f.t.Fatalf("index %d: interior page %d is empty", root, n)
+			}
+		default:
+			f.t.Fatalf("index %d: page %d has type %#x", root, n, typ)
+		}
+		for _, c := range cells {
+			if typ == 0x02 {
+				walk(c.child, depth+1)
+			}
+			key := sqliteDecodeRecord(f.t, c.payload)
+			if len(keys) > 0 && sqliteCompareKeys(keys[len(keys)-1], key) >= 0 {
+				f.t.Fatalf("index %d: %v after %v", root, key, keys[len(keys)-1])
+			}
+			keys = append(keys, key)
+		}
+		if typ == 0x02 {
+			walk(right, depth+1)
+		}
+	}
+	walk(root, 0)
+	return keys
+}
+
+func sqliteVarint(b []byte) (uint64, int) {
+	var v uint64
+	for i := 0; i < 8; i++ {
+		v = v<<7 | uint64(b[i]&0x7f)
+		if b[i] < 0x80 {
+			return v, i + 1
+		}
+	}
+	return v<<8 | uint64(b[8]), 9
+}
+
+func sqliteDecodeRecord(t *testing.T, b []byte) []any {
+	t.Helper()
+	size, n := sqliteVarint(b)
+	header, body := b[n:size], b[size:]
+	var values []any
+	for len(header) > 0 {
+		typ, n := sqliteVarint(header)
+		header = header[n:]
+		var width int
+		switch {
+		case typ == 0:
+			values = append(values, nil)
+		case typ >= 1 && typ <= 6:
+			width = []int{1, 2, 3, 4, 6, 8}[typ-1]
+			v := int64(int8(body[0]))
+			for _, c := range body[1:width] {
+				v = v<<8 | int64(c)
+			}
+			values = append(values, v)
+		case typ == 7:
+			width = 8
+			values = append(values, math.Float64frombits(binary.BigEndian.Uint64(body)))
+		case typ == 8 || typ == 9:
+			values = append(values, int64(typ-8))
+		case typ >= 12 && typ%2 == 0:
+			width = int(typ-12) / 2
+			values = append(values, append([]byte(nil), body[:width]...))
+		case typ >= 13:
+			width = int(typ-13) / 2
+			values = append(values, string(body[:width]))
+		default:
+			t.Fatalf("serial type %d", typ)
+		}
+		body = body[width:]
+	}
+	if len(body) != 0 {
+		t.Fatalf("record has %d bytes past its values", len(body))
+	}
+	return values
+}
+
+// sqliteRows returns the rows of a table as written, with the INTEGER
+// PRIMARY KEY column, stored as NULL, filled in from the rowid.
+func sqliteRows(entries []sqliteEntry, keyColumn int) [][]any {
+	rows := make([][]any, len(entries))
+	for i, e := range entries {
+		rows[i] = e.values
+		if keyColumn >= 0 {
+			rows[i][keyColumn] = e.rowid
+		}
+	}
+	return rows
+}
+
+// sqliteIndexKeys returns the entries an index on columns of rows should
+// hold, in order.
+func sqliteIndexKeys(rows [][]any, keyColumn int, columns []int) [][]any {
+	keys := make([][]any, len(rows))
+	for i, row := range rows {
+		for _, c := range columns {
+			keys[i] = append(keys[i], row[c])
+		}
+		rowid := int64(i + 1)
+		if keyColumn >= 0 {
+			rowid = row[keyColumn].(int64)
+		}
+		keys[i] = append(keys[i], rowid)
+	}
+	sort.SliceStable(keys, func(i, j int) bool { return sqliteCompareKeys(keys[i], keys[j]) < 0 })
+	return keys
+}
+
+func TestSQLiteVarint(t *testing.T) {
+	tests := []struct {
+		v    uint64
+		want []byte
+	}{
+		{0, []byte{0x00}},
+		{127, []byte{0x7f}},
+		{128, []byte{0x81, 0x00}},
+		{16383, []byte{0xff, 0x7f}},
+		{1 << 56, []byte{0x80, 0xc0, 0x80, 0x80, 0x80, 0x80, 0x80, 0x80, 0x00}},
+		{^uint64(0), bytes.Repeat([]byte{0xff}, 9)},
+	}
+	for _, tt := range tests {
+		if got := sqlitePutVarint(nil, tt.v); !bytes.Equal(got, tt.want) {
+			t.Errorf("varint(%d) = % x, want % x", tt.v, got, tt.want)
+		}
+	}
+}
+
+func TestSQLiteRecord(t *testing.T) {
+	tests := []struct {
+		values []any
+		want   []byte
+	}{
+		{[]any{nil, int64(0), int64(1)}, []byte{4, 0, 8, 9}},
+		{[]any{int64(-2), "ab"}, []byte{3, 1, 17, 0xfe, 'a', 'b'}},
+		{[]any{int64(1 << 40)}, []byte{2, 5, 0x01, 0, 0, 0, 0, 0}},
+		{[]any{1.5}, []byte{2, 7, 0x3f, 0xf8, 0, 0, 0, 0, 0, 0}},
+	}
+	for _, tt := range tests {
+		if got := sqliteRecord(tt.values); !bytes.Equal(got, tt.want) {
+			t.Errorf("record(%v) = % x, want % x", tt.values, got, tt.want)
+		}
+	}
+}
+
+func TestSQLiteCompare(t *testing.T) {
+	ordered := []any{nil, int64(-5), 1.5, int64(2), "", "a", "b", []byte{}, []byte{0}}
+	for i := range ordered {
+		for j := range ordered {
+			got := sqliteCompare(ordered[i], ordered[j])
+			if (got < 0) != (i < j) || (got == 0) != (i == j) {
+				t.Errorf("compare(%#v, %#v) = %d", ordered[i], ordered[j], got)
+			}
+		}
+	}
+}
+
+func TestWriteSQLite(t *testing.T) {
+	ints := []int64{0, 1, -1, 127, -128, 1 << 15, -1 << 23, 1 << 31, -1 << 47, math.MaxInt64, math.MinInt64}
+	var rows [][]any
+	for i := 0; i < 2500; i++ {
+		// Texts around the overflow thresholds of both tables and indexes.
+		text := strings.Repeat(string(rune('a'+i%26)), []int{0, 5, 480, 1200, sqliteMaxLocalTable + 1, 9000}[i%6])
+		var blob any
+		if i%4 == 0 {
+			blob = []byte{byte(i), 0, 0xff}
+		}
+		rows = append(rows, []any{int64(3*i + 7), text, ints[i%len(ints)], float64(i) / 4, blob})
+	}
+
+	tests := []struct {
+		name    string
+		tables  []sqliteTable
+		indexes []sqliteIndex
+	}{
+		{"empty", []sqliteTable{{Name: "t", SQL: "CREATE TABLE t (a)", KeyColumn: -1}}, nil},
+		{"one row", []sqliteTable{{Name: "t", SQL: "CREATE TABLE t (a, b)", KeyColumn: -1, Rows: [][]any{{"x", nil}}}},
+			[]sqliteIndex{{Name: "t_a", Table: "t", SQL: "CREATE INDEX t_a ON t (a)", Columns: []int{0}}}},
+		{"many rows", []sqliteTable{{Name: "t", SQL: "CREATE TABLE t (id INTEGER PRIMARY KEY, s, n, f, b)", KeyColumn: 0, Rows: rows}},
+			[]sqliteIndex{
+				{Name: "t_s", Table: "t", SQL: "CREATE INDEX t_s ON t (s)", Columns: []int{1}},
+				{Name: "t_nf", Table: "t", SQL: "CREATE INDEX t_nf ON t (n, f)", Columns: []int{2, 3}},
+				{Name: "t_b", Table: "t", SQL: "CREATE INDEX t_b ON t (b)", Columns: []int{4}},
+			}},
+	}
+	for _, tt := range tests {
+		path := filepath.Join(t.TempDir(), "test.db")
+		if err := writeSQLite(path, tt.tables, tt.indexes); err != nil {
+			t.Fatalf("%s: %v", tt.name, err)
+		}
+		db := readSQLiteFile(t, path)
+		for _, table := range tt.tables {
+			got := sqliteRows(db.tables[table.Name], table.KeyColumn)
+			if len(got) != len(table.Rows) || len(got) > 0 && !reflect.DeepEqual(got, table.Rows) {
+				t.Errorf("%s: table %s reads back differently", tt.name, table.Name)
+			}
+		}
+		for _, ix := range tt.indexes {
+			table := tt.tables[0]
+			want := sqliteIndexKeys(table.Rows, table.KeyColumn, ix.Columns)
+			if got := db.indexes[ix.Name]; len(got) != len(want) || len(got) > 0 && !reflect.DeepEqual(got, want) {
+				t.Errorf("%s: index %s reads back differently", tt.name, ix.Name)
+			}
+		}
+	}
+}
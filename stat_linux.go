@@ -0,0 +1,50 @@
+package main
+
+import (
+	"io/fs"
+	"strconv"
+	"syscall"
+	"time"
+
+	"golang.org/x/sys/unix"
+)
+
+// fileBirthTime returns when path was created, or the zero time when the
+// filesystem doesn't record it.
+func fileBirthTime(path string, info fs.FileInfo) time.Time {
+	var stx unix.Statx_t
+	if err := unix.Statx(unix.AT_FDCWD, path, unix.AT_SYMLINK_NOFOLLOW, unix.STATX_BTIME, &stx); err != nil {
+		return time.Time{}
+	}
+	if stx.Mask&unix.STATX_BTIME == 0 {
+		return time.Time{}
+	}
+	return time.Unix(stx.Btime.Sec, int64(stx.Btime.Nsec))
+}
+
+// linkCount returns the hard link count of a file. For a directory on most
+// Unix filesystems that is two plus its number of subdirectories.
+func linkCount(info fs.FileInfo) uint64 {
+	if st, ok := info.Sys().(*syscall.Stat_t); ok {
+		return uint64(st.Nlink)
+	}
+	return 0
+}
+
+// diskSpace returns the bytes available to unprivileged users and the
+// total size of the filesystem holding path.
+func diskSpace(path string) (free, total uint64, err error) {
+	var st unix.Statfs_t
+	if err := unix.Statfs(path, &st); err != nil {
+		return 0, 0, err
+	}
+	return st.Bavail * uint64(st.Bsize), st.Blocks * uint64(st.Bsize), nil
+}
+
+// fileOwnerID returns the user ID owning a file, as os/user expects it.
+func fileOwnerID(info fs.FileInfo) string {
+	if st, ok := info.Sys().(*syscall.Stat_t); ok {
+		return strconv.FormatUint(uint64(st.Uid), 10)
+	}
+	return ""
+}
@@ -0,0 +1,25 @@
+//go:build !linux && !darwin && !windows
+
+package main
+
+import (
+	"errors"
+	"io/fs"
+	"time"
+)
+
+func fileBirthTime(path string, info fs.FileInfo) time.Time {
+	return time.Time{}
+}
+
+func linkCount(info fs.FileInfo) uint64 {
+	return 0
+}
+
+func diskSpace(path string) (free, total uint64, err error) {
+	return 0, 0, errors.ErrUnsupported
+}
+
+func fileOwnerID(info fs.FileInfo) string {
+	return ""
+}
@@ -0,0 +1,34 @@
+package main
+
+import (
+	"io/fs"
+	"syscall"
+	"time"
+
+	"golang.org/x/sys/windows"
+)
+
+func fileBirthTime(path string, info fs.FileInfo) time.Time {
+	attr, ok := info.Sys().(*syscall.Win32FileAttributeData)
+	if !ok {
+		return time.Time{}
+	}
+	return time.Unix(0, attr.CreationTime.Nanoseconds())
+}
+
+func linkCount(info fs.FileInfo) uint64 {
+	return 0
+}
+
+func diskSpace(path string) (free, total uint64, err error) {
+	p, err := windows.UTF16PtrFromString(path)
+	if err != nil {
+		return 0, 0, err
+	}
+	err = windows.GetDiskFreeSpaceEx(p, &free, &total, nil)
+	return free, total, err
+}
+
+func fileOwnerID(info fs.FileInfo) string {
+	return ""
+}
@@ -0,0 +1,307 @@
+package main
+
+import (
+	"bytes"
+	"io"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strconv"
+	"time"
+
+	"github.com/fsnotify/fsnotify"
+)
+
+type TailRequest struct {
+	Path string `json:"path"`
+	// Lines is how many existing lines to send first.
+	Lines int `json:"lines"`
+	// Filter, if set, drops lines that don't match it.
+	Filter string `json:"filter"`
+	// Highlight, if set, marks its matches in each line.
+	Highlight string `json:"highlight"`
+}
+
+type TailLine struct {
+	Text string `json:"text"`
+	// Matches are [start, end) byte offsets of Highlight in Text.
+	Matches [][]int `json:"matches,omitempty"`
+}
+
+type TailBatch struct {
+	ID    string     `json:"id"`
+	Path  string     `json:"path"`
+	Lines []TailLine `json:"lines"`
+	// Truncated is set when the file shrank and is being read again from
+	// the start; Rotated when a new file took its place.
+	Truncated bool `json:"truncated,omitempty"`
+	Rotated   bool `json:"rotated,omitempty"`
+}
+
+type TailStarted struct {
+	// ID is passed to StopTail and identifies the tail's batches.
+	ID string `json:"id"`
+}
+
+type StopTailRequest struct {
+	ID string `json:"id"`
+}
+
+const (
+	// tailPollInterval backs up the watcher, which may miss appends on
+	// network filesystems.
+	tailPollInterval = time.Second
+	maxTailBatch     = 1000
+	maxTailLine      = 64 << 10
+)
+
+// TailFile follows req.Path and emits "tail:lines" events with a TailBatch
+// as lines are appended. It returns an ID for StopTail; any number of tails
+// can run at once.
+func (a *App) TailFile(req TailRequest) (TailStarted, error) {
+	a.tailMu.Lock()
+	a.tailSeq++
+	id := strconv.Itoa(a.tailSeq)
+	a.tailMu.Unlock()
+
+	t, err := startLogTail(id, req, func(b TailBatch) { a.emit("tail:lines", b) })
+	if err != nil {
+		return TailStarted{}, err
+	}
+	a.tailMu.Lock()
+	if a.tails == nil {
+		a.tails = make(map[string]*logTail)
+	}
+	a.tails[id] = t
+	a.tailMu.Unlock()
+	return TailStarted{ID: id}, nil
+}
+
+// StopTail ends the tail req.ID, e.g. when its view closes.
+func (a *App) StopTail(req StopTailRequest) {
+	a.tailMu.Lock()
+	t := a.tails[req.ID]
+	delete(a.tails, req.ID)
+	a.tailMu.Unlock()
+	if t != nil {
+		t.stop()
+	}
+}
+
+func (a *App) stopTails() {
+	a.tailMu.Lock()
+	tails := a.tails
+	a.tails = nil
+	a.tailMu.Unlock()
+	for _, t := range tails {
+		t.stop()
+	}
+}
+
+type logTail struct {
+	id, path          string
+	filter, highlight *regexp.Regexp
+	send              func(TailBatch)
+
+	f       *os.File
+	offset  int64
+	partial []byte
+	batch   TailBatch
+
+	w    *fsnotify.Watcher
+	quit chan struct{}
+	done chan struct{}
+}
+
+func startLogTail(id string, req TailRequest, send func(TailBatch)) (*logTail, error) {
+	t := &logTail{id: id, path: filepath.Clean(req.Path), send: send}
+	var err error
+	if req.Filter != "" {
+		if t.filter, err = regexp.Compile(req.Filter); err != nil {
+			return nil, err
+		}
+	}
+	if req.Highlight != "" {
+		if t.highlight, err = regexp.Compile(req.Highlight); err != nil {
+			return nil, err
+		}
+	}
+	if t.f, err = os.Open(t.path); err != nil {
+		return nil, err
+	}
+
+	// Rotation replaces the file, so watch its directory rather than it.
+	if t.w, err = fsnotify.NewWatcher(); err != nil {
+		t.f.Close()
+		return nil, err
+	}
+	if err := t.w.Add(filepath.Dir(t.path)); err != nil {
+		t.w.Close()
+		t.f.Close()
+		return nil, err
+	}
+
+	if err := t.backlog(req.Lines); err != nil {
+		t.w.Close()
+		t.f.Close()
+		return nil, err
+	}
+	t.quit = make(chan struct{})
+	t.done = make(chan struct{})
+	go t.run()
+	return t, nil
+}
+
+func (t *logTail) stop() {
+	close(t.quit)
+	<-t.done
+}
+
+func (t *logTail) run() {
+	defer close(t.done)
+	defer t.f.Close()
+	defer t.w.Close()
+	ticker := time.NewTicker(tailPollInterval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-t.quit:
+			return
+		case ev, ok := <-t.w.Events:
+			if !ok {
+				return
+			}
+			if filepath.Clean(ev.Name) == t.path {
+				t.poll()
+			}
+		case <-t.w.Errors:
+		case <-ticker.C:
+			t.poll()
+		}
+	}
+}
+
+// backlog sends the last n complete lines of the file and positions the
+// tail at its end.
+func (t *logTail) backlog(n int) error {
+	info, err := t.f.Stat()
+	if err != nil {
+		return err
+	}
+	size := info.Size()
+
+	// Read backwards until n+1 line breaks are seen, so the first line
+	// kept is whole.
+	start, chunk := size, int64(64<<10)
+	var data []byte
+	for start > 0 && bytes.Count(data, []byte{'\n'}) <= n {
+		step := min(chunk, start)
+		buf := make([]byte, step)
+		if _, err := t.f.ReadAt(buf, start-step); err != nil && err != io.EOF {
+			return err
+		}
+		data = append(buf, data...)
+		start -= step
+	}
+
+	t.offset = size
+	end := bytes.LastIndexByte(data, '\n') + 1
+	t.partial = append([]byte(nil), data[end:]...)
+	lines := bytes.Split(data[:end], []byte{'\n'})
+	lines = lines[:len(lines)-1]
+	if start > 0 && len(lines) > 0 {
+		lines = lines[1:]
+	}
+	if n <= 0 {
+		lines = nil
+	}
+	if len(lines) > n {
+		lines = lines[len(lines)-n:]
+	}
+	for _, l := range lines {
+		t.addLine(l)
+	}
+	t.flush(true)
+	return nil
+}
+
+// poll reads whatever was appended since the last poll, first noticing a
+// truncated or replaced file.
+func (t *logTail) poll() {
+	info, err := os.Stat(t.path)
+	current, _ := t.f.Stat()
+	switch {
+	case err != nil:
+		// Rotated away and not yet recreated; finish the old file.
+	case current != nil && !os.SameFile(info, current):
+		t.readNew()
+		if f, err := os.Open(t.path); err == nil {
+			t.f.Close()
+			t.f = f
+			t.offset = 0
+			t.partial = nil
+			t.batch.Rotated = true
+		}
+	case info.Size() < t.offset:
+		t.offset = 0
+		t.partial = nil
+		t.batch.Truncated = true
+	}
+	t.readNew()
+	t.flush(false)
+}
+
+func (t *logTail) readNew() {
+	buf := make([]byte, 64<<10)
+	for {
+		n, err := t.f.ReadAt(buf, t.offset)
+		t.offset += int64(n)
+		data := buf[:n]
+		for {
+			i := bytes.IndexByte(data, '\n')
+			if i < 0 {
+				break
+			}
+			t.addLine(append(t.partial, data[:i]...))
+			t.partial = t.partial[:0]
+			data = data[i+1:]
+		}
+		t.partial = append(t.partial, data...)
+		if len(t.partial) > maxTailLine {
+			t.addLine(t.partial)
+			t.partial = t.partial[:0]
+		}
+		if err != nil || n == 0 {
+			return
+		}
+	}
+}
+
+func (t *logTail) addLine(line []byte) {
+	text := string(bytes.TrimSuffix(line, []byte{'\r'}))
+	if t.filter != nil && !t.filter.MatchString(text) {
+		return
+	}
+	l := TailLine{Text: text}
+	if t.highlight != nil {
+		l.Matches = t.highlight.FindAllStringIndex(text, -1)
+	}
+	t.batch.Lines = append(t.batch.Lines, l)
+	if len(t.batch.Lines) >= maxTailBatch {
+		t.flush(false)
+	}
+}
+
+// flush sends the pending lines, if there are any or force is set.
+func (t *logTail) flush(force bool) {
+	b := t.batch
+	t.batch = TailBatch{}
+	if !force && len(b.Lines) == 0 && !b.Truncated && !b.Rotated {
+		return
+	}
+	b.ID, b.Path = t.id, t.path
+	if b.Lines == nil {
+		b.Lines = []TailLine{}
+	}
+	t.send(b)
+}
@@ -0,0 +1,89 @@
+package main
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+	"time"
+)
+
+func TestLogTail(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, "app.log")
+	writeFiles(t, dir, map[string]string{"app.log": "one\ntwo\nERROR three\nfour\npart"})
+
+	batches := make(chan TailBatch, 16)
+	tail, err := startLogTail("1", TailRequest{Path: path, Lines: 2, Highlight: "ERROR|ial"}, func(b TailBatch) { batches <- b })
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer tail.stop()
+
+	next := func() TailBatch {
+		t.Helper()
+		select {
+		case b := <-batches:
+			return b
+		case <-time.After(5 * time.Second):
+			t.Fatal("no batch")
+			return TailBatch{}
+		}
+	}
+	appendTo := func(name, text string) {
+		f, err := os.OpenFile(filepath.Join(dir, name), os.O_APPEND|os.O_WRONLY|os.O_CREATE, 0o644)
+		if err != nil {
+			t.Fatal(err)
+		}
+		f.WriteString(text)
+		f.Close()
+	}
+
+	tests := []struct {
+		name      string
+		change    func()
+		lines     []TailLine
+		truncated bool
+		rotated   bool
+	}{
+		{"backlog", func() {}, []TailLine{{"ERROR three", [][]int{{0, 5}}}, {"four", nil}}, false, false},
+		{"append", func() { appendTo("app.log", "ial\r\nfive\n") }, []TailLine{{"partial", [][]int{{4, 7}}}, {"five", nil}}, false, false},
+		{"truncate", func() { os.WriteFile(path, []byte("six\n"), 0o644) }, []TailLine{{"six", nil}}, true, false},
+		{"rotate", func() {
+			os.Rename(path, filepath.Join(dir, "app.log.1"))
+			appendTo("app.log", "seven\n")
+		}, []TailLine{{"seven", nil}}, false, true},
+	}
+	for _, tt := range tests {
+		tt.change()
+		// Events may split a change over several batches; merge them.
+		got := next()
+		for len(got.Lines) < len(tt.lines) {
+			b := next()
+			got.Lines = append(got.Lines, b.Lines...)
+			got.Truncated = got.Truncated || b.Truncated
+			got.Rotated = got.Rotated || b.Rotated
+		}
+		if !reflect.DeepEqual(got.Lines, tt.lines) || got.Truncated != tt.truncated || got.Rotated != tt.rotated {
+			t.Errorf("%s: got %+v", tt.name, got)
+		}
+	}
+}
+
+func TestTailFileStop(t *testing.T) {
+	dir := t.TempDir()
+	writeFiles(t, dir, map[string]string{"a.log": "x\n"})
+	a := NewApp()
+	if _, err := a.TailFile(TailRequest{Path: filepath.Join(dir, "a.log"), Filter: "("}); err == nil {
+		t.Error("invalid filter succeeded, want error")
+	}
+	started, err := a.TailFile(TailRequest{Path: filepath.Join(dir, "a.log")})
+	if err != nil {
+		t.Fatal(err)
+	}
+	a.StopTail(StopTailRequest{ID: started.ID})
+	a.StopTail(StopTailRequest{ID: started.ID})
+	if len(a.tails) != 0 {
+		t.Errorf("%d tails left running", len(a.tails))
+	}
+}
@@ -0,0 +1,117 @@
+package main
+
+import (
+	"bytes"
+	"encoding/binary"
+	"fmt"
+	"strings"
+	"unicode/utf16"
+	"unicode/utf8"
+)
+
+// textEncoding is how a text file's bytes map to characters: "utf-8",
+// "utf-16le", "utf-16be" or "latin-1", with or without a byte order mark.
+type textEncoding struct {
+	Name string
+	BOM  bool
+}
+
+func (e textEncoding) String() string {
+	if e.BOM {
+		return e.Name + " (BOM)"
+	}
+	return e.Name
+}
+
+var (
+	bomUTF8    = []byte{0xef, 0xbb, 0xbf}
+	bomUTF16LE = []byte{0xff, 0xfe}
+	bomUTF16BE = []byte{0xfe, 0xff}
+)
+
+// decodeText detects the encoding of data and returns its contents. A byte
+// order mark decides; otherwise valid UTF-8 is taken as UTF-8. Anything
+// else is only taken as Latin-1 when every byte is a printable Latin-1
+// character or common whitespace, since control bytes mean binary data,
+// which rewriting as text would corrupt. Binary data is reported as not
+// text.
+func decodeText(data []byte) (string, textEncoding, bool) {
+	switch {
+	case bytes.HasPrefix(data, bomUTF8):
+		return string(data[3:]), textEncoding{"utf-8", true}, true
+	case bytes.HasPrefix(data, bomUTF16LE):
+		return decodeUTF16(data[2:], binary.LittleEndian), textEncoding{"utf-16le", true}, true
+	case bytes.HasPrefix(data, bomUTF16BE):
+		return decodeUTF16(data[2:], binary.BigEndian), textEncoding{"utf-16be", true}, true
+	case bytes.IndexByte(data, 0) >= 0:
+		return "", textEncoding{}, false
+	case utf8.Valid(data):
+		return string(data), textEncoding{Name: "utf-8"}, true
+	case !isLatin1Text(data):
+		return "", textEncoding{}, false
+	}
+
+	var sb strings.Builder
+	for _, b := range data {
+		sb.WriteRune(rune(b))
+	}
+	return sb.String(), textEncoding{Name: "latin-1"}, true
+}
+
+// isLatin1Text reports whether data has no bytes that are control
+// characters in Latin-1, tabs and line breaks aside.
+func isLatin1Text(data []byte) bool {
+	for _, b := range data {
+		switch {
+		case b >= 0x20 && b < 0x7f, b >= 0xa0:
+		case b == '\t', b == '\n', b == '\v', b == '\f', b == '\r':
+		default:
+			return false
+		}
+	}
+	return true
+}
+
+func decodeUTF16(data []byte, order binary.ByteOrder) string {
+	units := make([]uint16, len(data)/2)
+	for i := range units {
+		units[i] = order.Uint16(data[2*i:])
+	}
+	return string(utf16.Decode(units))
+}
+
+// encodeText is the inverse of decodeText. It fails when text has a
+// character that enc can't represent.
+func encodeText(text string, enc textEncoding) ([]byte, error) {
+	var out []byte
+	switch enc.Name {
+	case "utf-8":
+		if enc.BOM {
+			out = append(out, bomUTF8...)
+		}
+		return append(out, text...), nil
+	case "utf-16le", "utf-16be":
+		var order binary.AppendByteOrder = binary.LittleEndian
+		bom := bomUTF16LE
+		if enc.Name == "utf-16be" {
+			order, bom = binary.BigEndian, bomUTF16BE
+		}
+		if enc.BOM {
+			out = append(out, bom...)
+		}
+		for _, u := range utf16.Encode([]rune(text)) {
+			out = order.AppendUint16(out, u)
+		}
+		return out, nil
+	case "latin-1":
+		out = make([]byte, 0, len(text))
+		for _, r := range text {
+			if r > 0xff {
+				return nil, fmt.Errorf("%q can't be encoded as Latin-1", r)
+			}
+			out = append(out, byte(r))
+		}
+		return out, nil
+	}
+	return nil, fmt.Errorf("unknown encoding %q", enc.Name)
+}
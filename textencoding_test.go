@@ -0,0 +1,45 @@
+package main
+
+import (
+	"bytes"
+	"testing"
+)
+
+func TestDecodeText(t *testing.T) {
+	tests := []struct {
+		name string
+		data []byte
+		text string
+		enc  string
+		ok   bool
+	}{
+		{"ascii", []byte("plain"), "plain", "utf-8", true},
+		{"utf-8", []byte("caf\xc3\xa9"), "café", "utf-8", true},
+		{"utf-8 bom", []byte("\xef\xbb\xbfhi"), "hi", "utf-8 (BOM)", true},
+		{"utf-16le", []byte("\xff\xfeh\x00i\x00"), "hi", "utf-16le (BOM)", true},
+		{"utf-16be", []byte("\xfe\xff\x00h\x00i"), "hi", "utf-16be (BOM)", true},
+		{"latin-1", []byte("caf\xe9"), "café", "latin-1", true},
+		{"latin-1 lines", []byte("na\xefve\r\n\tcaf\xe9\n"), "naïve\r\n\tcafé\n", "latin-1", true},
+		{"binary", []byte("a\x00b"), "", "", false},
+		{"binary without nul", []byte("\x89PNG\r\n\x1a\n\xff"), "", "", false},
+		{"c1 controls", []byte("caf\x82\x9c"), "", "", false},
+	}
+	for _, tt := range tests {
+		text, enc, ok := decodeText(tt.data)
+		if ok != tt.ok || text != tt.text || ok && enc.String() != tt.enc {
+			t.Errorf("%s: got %q, %s, %v; want %q, %s, %v", tt.name, text, enc, ok, tt.text, tt.enc, tt.ok)
+			continue
+		}
+		if !ok {
+			continue
+		}
+		out, err := encodeText(text, enc)
+		if err != nil || !bytes.Equal(out, tt.data) {
+			t.Errorf("%s: round trip gave %q, %v", tt.name, out, err)
+		}
+	}
+
+	if _, err := encodeText("€", textEncoding{Name: "latin-1"}); err == nil {
+		t.Error("encoding € as Latin-1 succeeded, want error")
+	}
+}
@@ -0,0 +1,791 @@
+package main
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/gob"
+	"encoding/hex"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"regexp"
+	"regexp/syntax"
+	"sort"
+	"strings"
+	"time"
+	"unicode/utf8"
+
+	"github.com/fsnotify/fsnotify"
+)
+
+type ContentIndexStats struct {
+	Root    string `json:"root"`
+	Files   int    `json:"files"`
+	Reused  int    `json:"reused"`
+	Indexed int    `json:"indexed"`
+	Removed int    `json:"removed"`
+	// Skipped counts files left out as binary or over 4 MB.
+	Skipped  int `json:"skipped"`
+	Trigrams int `json:"trigrams"`
+}
+
+type ContentMatch struct {
+	Path   string `json:"path"`
+	Line   int    `json:"line"`
+	Column int    `json:"column"`
+	Text   string `json:"text"`
+	// Start and End are byte offsets of the match within Text.
+	Start int `json:"start"`
+	End   int `json:"end"`
+}
+
+type SearchContentRequest struct {
+	Root    string `json:"root"`
+	Pattern string `json:"pattern"`
+	// Limit bounds the matches returned; 0 means 1000.
+	Limit int `json:"limit"`
+}
+
+type ContentSearchResult struct {
+	Files      int            `json:"files"`
+	Candidates int            `json:"candidates"`
+	Matches    []ContentMatch `json:"matches"`
+	Truncated  bool           `json:"truncated"`
+}
+
+const (
+	maxIndexedFileSize = 4 << 20
+	maxMatchLineLength = 300
+	contentIndexDelay  = 500 * time.Millisecond
+)
+
+// contentIndex maps each trigram of lower-cased file contents to the IDs of
+// the files that contain it, the way Russ Cox's codesearch does. A file
+// that changes gets a new ID and its old one is marked dead rather than
+// removed from every posting list; compactIfNeeded drops dead IDs once
+// they pile up. Files left out are kept in Skipped, so they aren't read
+// again until they change.
+type contentIndex struct {
+	Root     string
+	Files    []indexedFile
+	Postings map[uint32][]uint32
+	Skipped  map[string]skippedFile
+
+	byPath map[string]uint32
+	dead   int
+}
+
+type indexedFile struct {
+	Path    string
+	Size    int64
+	ModTime int64
+	Dead    bool
+}
+
+type skippedFile struct {
+	Size    int64
+	ModTime int64
+}
+
+// IndexContent builds or refreshes the trigram index of the text files
+// under req.Root and stores it in the user cache directory. Files whose
+// size and modification time are unchanged since the last run are not
+// read.
+func (a *App) IndexContent(req RootRequest) (ContentIndexStats, error) {
+	root := filepath.Clean(req.Root)
+	a.contentMu.Lock()
+	defer a.contentMu.Unlock()
+
+	ix := a.loadContentIndexLocked(root)
+	stats, err := ix.refresh()
+	if err != nil {
+		return stats, err
+	}
+	return stats, ix.save()
+}
+
+// SearchContent runs the regular expression req.Pattern over the indexed
+// files under req.Root, reading only those whose trigrams can satisfy it.
+// The index is built first if there isn't one. Files changed since the
+// index was last refreshed, by IndexContent or WatchContentIndex, may be
+// missed. Binary files and files over 4 MB are never indexed, so never
+// found.
+func (a *App) SearchContent(req SearchContentRequest) (ContentSearchResult, error) {
+	pattern, limit := req.Pattern, req.Limit
+	root := filepath.Clean(req.Root)
+	result := ContentSearchResult{Matches: []ContentMatch{}}
+	if limit <= 0 {
+		limit = 1000
+	}
+	re, err := regexp.Compile(pattern)
+	if err != nil {
+		return result, err
+	}
+	parsed, err := syntax.Parse(pattern, syntax.Perl)
+	if err != nil {
+		return result, err
+	}
+	query := analyzeRegexp(parsed.Simplify()).query()
+
+	a.contentMu.Lock()
+	ix := a.loadContentIndexLocked(root)
+	if len(ix.Files) == 0 {
+		if _, err := ix.refresh(); err != nil {
+			a.contentMu.Unlock()
+			return result, err
+		}
+		ix.save()
+	}
+	result.Files = len(ix.Files) - ix.dead
+	var paths []string
+	for _, id := range ix.evaluate(query) {
+		paths = append(paths, ix.Files[id].Path)
+	}
+	a.contentMu.Unlock()
+
+	sort.Strings(paths)
+	result.Candidates = len(paths)
+	for _, rel := range paths {
+		path := filepath.Join(root, filepath.FromSlash(rel))
+		data, err := os.ReadFile(path)
+		if err != nil {
+			continue
+		}
+		for _, loc := range re.FindAllIndex(data, -1) {
+			if len(result.Matches) == limit {
+				result.Truncated = true
+				return result, nil
+			}
+			result.Matches = append(result.Matches, contentMatchAt(path, data, loc))
+		}
+	}
+	return result, nil
+}
+
+// WatchContentIndex keeps req.Root's trigram index current as files change,
+// replacing any previous watch.
+func (a *App) WatchContentIndex(req RootRequest) error {
+	root := filepath.Clean(req.Root)
+	a.StopWatchContentIndex()
+
+	tw, err := newTreeWatcher(root, func(ev fsnotify.Event) {
+		if ev.Has(fsnotify.Chmod) && !ev.Has(fsnotify.Write) {
+			return
+		}
+		a.contentMu.Lock()
+		defer a.contentMu.Unlock()
+		if a.contentPending == nil {
+			a.contentPending = make(map[string]bool)
+		}
+		a.contentPending[ev.Name] = true
+		if a.contentTimer == nil {
+			a.contentTimer = time.AfterFunc(contentIndexDelay, func() { a.flushContentIndex(root) })
+		}
+	})
+	if err != nil {
+		return err
+	}
+
+	a.contentMu.Lock()
+	a.contentWatch = tw
+	a.contentMu.Unlock()
+	return nil
+}
+
+func (a *App) StopWatchContentIndex() {
+	a.contentMu.Lock()
+	tw := a.contentWatch
+	a.contentWatch = nil
+	if a.contentTimer != nil {
+		a.contentTimer.Stop()
+		a.contentTimer = nil
+	}
+	a.contentPending = nil
+	a.contentMu.Unlock()
+	if tw != nil {
+		tw.Close()
+	}
+}
+
+// flushContentIndex re-reads the files the watcher reported since the last
+// flush. A changed directory is re-walked, since renames and removals of
+// whole trees are reported only for the directory.
+func (a *App) flushContentIndex(root string) {
+	a.contentMu.Lock()
+	defer a.contentMu.Unlock()
+	pending := a.contentPending
+	a.contentPending = nil
+	a.contentTimer = nil
+	if a.contentWatch == nil {
+		return
+	}
+
+	ix := a.loadContentIndexLocked(root)
+	for path := range pending {
+		rel, err := filepath.Rel(root, path)
+		if err != nil || strings.HasPrefix(rel, "..") {
+			continue
+		}
+		rel = filepath.ToSlash(rel)
+		if rel == ".git" || strings.HasPrefix(rel, ".git/") {
+			continue
+		}
+		ix.remove(rel)
+		prefix := rel + "/"
+		for p, id := range ix.byPath {
+			if strings.HasPrefix(p, prefix) {
+				ix.kill(id)
+			}
+		}
+		for p := range ix.Skipped {
+			if strings.HasPrefix(p, prefix) {
+				delete(ix.Skipped, p)
+			}
+		}
+
+		filepath.WalkDir(path, func(p string, d fs.DirEntry, err error) error {
+			if err != nil {
+				return nil
+			}
+			if d.IsDir() {
+				if d.Name() == ".git" {
+					return filepath.SkipDir
+				}
+				return nil
+			}
+			r, _ := filepath.Rel(root, p)
+			ix.indexFile(filepath.ToSlash(r), p, d)
+			return nil
+		})
+	}
+	ix.compactIfNeeded()
+	ix.save()
+}
+
+func (a *App) loadContentIndexLocked(root string) *contentIndex {
+	if ix, ok := a.contentIndexes[root]; ok {
+		return ix
+	}
+	ix := loadContentIndex(root)
+	if a.contentIndexes == nil {
+		a.contentIndexes = make(map[string]*contentIndex)
+	}
+	a.contentIndexes[root] = ix
+	return ix
+}
+
+func contentIndexFile(root string) (string, error) {
+	dir, err := os.UserCacheDir()
+	if err != nil {
+		return "", err
+	}
+	sum := sha256.Sum256([]byte(root))
+	return filepath.Join(dir, "recursion", "trigram", hex.EncodeToString(sum[:8])+".gob"), nil
+}
+
+func loadContentIndex(root string) *contentIndex {
+	ix := &contentIndex{Root: root, Postings: map[uint32][]uint32{}}
+	if file, err := contentIndexFile(root); err == nil {
+		if f, err := os.Open(file); err == nil {
+			var loaded contentIndex
+			if gob.NewDecoder(f).Decode(&loaded) == nil && loaded.Root == root && loaded.Postings != nil {
+				ix = &loaded
+			}
+			f.Close()
+		}
+	}
+
+	if ix.Skipped == nil {
+		ix.Skipped = make(map[string]skippedFile)
+	}
+	ix.byPath = make(map[string]uint32)
+	for id, f := range ix.Files {
+		if f.Dead {
+			ix.dead++
+		} else {
+			ix.byPath[f.Path] = uint32(id)
+		}
+	}
+	return ix
+}
+
+// save writes the index to a temporary file and renames it into place, so
+// a crash never leaves a truncated index behind.
+func (ix *contentIndex) save() error {
+	file, err := contentIndexFile(ix.Root)
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(filepath.Dir(file), 0o755); err != nil {
+		return err
+	}
+	tmp, err := os.CreateTemp(filepath.Dir(file), ".trigram-*")
+	if err != nil {
+		return err
+	}
+	if err := gob.NewEncoder(tmp).Encode(ix); err != nil {
+		tmp.Close()
+		os.Remove(tmp.Name())
+		return err
+	}
+	if err := tmp.Close(); err != nil {
+		os.Remove(tmp.Name())
+		return err
+	}
+	return os.Rename(tmp.Name(), file)
+}
+
+func (ix *contentIndex) refresh() (ContentIndexStats, error) {
+	stats := ContentIndexStats{Root: ix.Root}
+	seen := make(map[string]bool)
+	err := filepath.WalkDir(ix.Root, func(path string, d fs.DirEntry, err error) error {
+		if err != nil {
+			if path == ix.Root {
+				return err
+			}
+			return nil
+		}
+		if d.IsDir() {
+			if path != ix.Root && d.Name() == ".git" {
+				return filepath.SkipDir
+			}
+			return nil
+		}
+		rel, _ := filepath.Rel(ix.Root, path)
+		rel = filepath.ToSlash(rel)
+		seen[rel] = true
+		if ix.indexFile(rel, path, d) {
+			stats.Indexed++
+		} else if _, ok := ix.byPath[rel]; ok {
+			stats.Reused++
+		}
+		return nil
+	})
+	if err != nil {
+		return stats, err
+	}
+
+	for rel := range ix.byPath {
+		if !seen[rel] {
+			ix.remove(rel)
+			stats.Removed++
+		}
+	}
+	for rel := range ix.Skipped {
+		if !seen[rel] {
+			delete(ix.Skipped, rel)
+		}
+	}
+	ix.compactIfNeeded()
+	stats.Files = len(ix.byPath)
+	stats.Skipped = len(ix.Skipped)
+	stats.Trigrams = len(ix.Postings)
+	return stats, nil
+}
+
+// indexFile (re)indexes the file at path unless the index already has it
+// with the same size and modification time, reporting whether it indexed
+// it. Files that are too large or don't look like text are left out and
+// recorded as skipped.
+func (ix *contentIndex) indexFile(rel, path string, d fs.DirEntry) bool {
+	if !d.Type().IsRegular() {
+		ix.remove(rel)
+		return false
+	}
+	info, err := d.Info()
+	if err != nil {
+		ix.remove(rel)
+		return false
+	}
+	stamp := skippedFile{Size: info.Size(), ModTime: info.ModTime().UnixNano()}
+	if id, ok := ix.byPath[rel]; ok {
+		f := ix.Files[id]
+		if f.Size == stamp.Size && f.ModTime == stamp.ModTime {
+			return false
+		}
+		ix.kill(id)
+	}
+	if s, ok := ix.Skipped[rel]; ok {
+		if s == stamp {
+			return false
+		}
+		delete(ix.Skipped, rel)
+	}
+	if info.Size() > maxIndexedFileSize {
+		ix.Skipped[rel] = stamp
+		return false
+	}
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return false
+	}
+	if !isText(data) {
+		ix.Skipped[rel] = stamp
+		return false
+	}
+
+	id := uint32(len(ix.Files))
+	ix.Files = append(ix.Files, indexedFile{Path: rel, Size: info.Size(), ModTime: info.ModTime().UnixNano()})
+	ix.byPath[rel] = id
+	for t := range trigramsOf(bytes.ToLower(data)) {
+		ix.Postings[t] = append(ix.Postings[t], id)
+	}
+	return true
+}
+
+func (ix *contentIndex) remove(rel string) {
+	if id, ok := ix.byPath[rel]; ok {
+		ix.kill(id)
+	}
+	delete(ix.Skipped, rel)
+}
+
+func (ix *contentIndex) kill(id uint32) {
+	if ix.Files[id].Dead {
+		return
+	}
+	ix.Files[id].Dead = true
+	delete(ix.byPath, ix.Files[id].Path)
+	ix.dead++
+}
+
+// compactIfNeeded renumbers the live files and rewrites the posting lists
+// once dead entries make up half the index.
+func (ix *contentIndex) compactIfNeeded() {
+	if ix.dead == 0 || ix.dead*2 < len(ix.Files) {
+		return
+	}
+	remap := make([]int64, len(ix.Files))
+	var files []indexedFile
+	for id, f := range ix.Files {
+		remap[id] = -1
+		if !f.Dead {
+			remap[id] = int64(len(files))
+			files = append(files, f)
+		}
+	}
+	for t, list := range ix.Postings {
+		kept := list[:0]
+		for _, id := range list {
+			if remap[id] >= 0 {
+				kept = append(kept, uint32(remap[id]))
+			}
+		}
+		if len(kept) == 0 {
+			delete(ix.Postings, t)
+		} else {
+			ix.Postings[t] = kept
+		}
+	}
+
+	ix.Files = files
+	ix.dead = 0
+	ix.byPath = make(map[string]uint32, len(files))
+	for id, f := range files {
+		ix.byPath[f.Path] = uint32(id)
+	}
+}
+
+func (ix *contentIndex) evaluate(q *trigramQuery) []uint32 {
+	var ids []uint32
+	switch q.op {
+	case queryAll:
+		for id, f := range ix.Files {
+			if !f.Dead {
+				ids = append(ids, uint32(id))
+			}
+		}
+		return ids
+	case queryNone:
+		return nil
+	case queryAnd:
+		for i, t := range q.trigrams {
+			list := ix.Postings[packTrigram(t)]
+			if i == 0 {
+				ids = append([]uint32(nil), list...)
+			} else {
+				ids = intersectIDs(ids, list)
+			}
+		}
+		for i, sub := range q.subs {
+			list := ix.evaluate(sub)
+			if i == 0 && len(q.trigrams) == 0 {
+				ids = list
+			} else {
+				ids = intersectIDs(ids, list)
+			}
+		}
+	case queryOr:
+		for _, t := range q.trigrams {
+			ids = unionIDs(ids, ix.Postings[packTrigram(t)])
+		}
+		for _, sub := range q.subs {
+			ids = unionIDs(ids, ix.evaluate(sub))
+		}
+	}
+
+	live := ids[:0]
+	for _, id := range ids {
+		if !ix.Files[id].Dead {
+			live = append(live, id)
+		}
+	}
+	return live
+}
+
+func intersectIDs(a, b []uint32) []uint32 {
+	var out []uint32
+	for i, j := 0, 0; i < len(a) && j < len(b); {
+		switch {
+		case a[i] < b[j]:
+			i++
+		case a[i] > b[j]:
+			j++
+		default:
+			out = append(out, a[i])
+			i++
+			j++
+		}
+	}
+	return out
+}
+
+func unionIDs(a, b []uint32) []uint32 {
+	out := make([]uint32, 0, len(a)+len(b))
+	i, j := 0, 0
+	for i < len(a) && j < len(b) {
+		switch {
+		case a[i] < b[j]:
+			out = append(out, a[i])
+			i++
+		case a[i] > b[j]:
+			out = append(out, b[j])
+			j++
+		default:
+			out = append(out, a[i])
+			i++
+			j++
+		}
+	}
+	out = append(out, a[i:]...)
+	return append(out, b[j:]...)
+}
+
+// isText rejects files with NUL bytes or invalid UTF-8 in their first 8KB.
+func isText(data []byte) bool {
+	head := data[:min(len(data), 8192)]
+	if bytes.IndexByte(head, 0) >= 0 {
+		return false
+	}
+	for len(head) > 0 {
+		r, size := utf8.DecodeRune(head)
+		if r == utf8.RuneError && size == 1 && len(head) >= utf8.UTFMax {
+			return false
+		}
+		head = head[size:]
+	}
+	return true
+}
+
+func trigramsOf(data []byte) map[uint32]struct{} {
+	set := make(map[uint32]struct{})
+	for i := 0; i+3 <= len(data); i++ {
+		set[uint32(data[i])<<16|uint32(data[i+1])<<8|uint32(data[i+2])] = struct{}{}
+	}
+	return set
+}
+
+func packTrigram(t string) uint32 {
+	return uint32(t[0])<<16 | uint32(t[1])<<8 | uint32(t[2])
+}
+
+func contentMatchAt(path string, data []byte, loc []int) ContentMatch {
+	lineStart := bytes.LastIndexByte(data[:loc[0]], '\n') + 1
+	lineEnd := bytes.IndexByte(data[loc[0]:], '\n')
+	if lineEnd < 0 {
+		lineEnd = len(data)
+	} else {
+		lineEnd += loc[0]
+	}
+
+	m := ContentMatch{
+		Path:   path,
+		Line:   bytes.Count(data[:lineStart], []byte("\n")) + 1,
+		Column: loc[0] - lineStart + 1,
+		Start:  loc[0] - lineStart,
+		End:    min(loc[1], lineEnd) - lineStart,
+	}
+	text := data[lineStart:lineEnd]
+	if len(text) > maxMatchLineLength {
+		from := max(0, min(m.Start-maxMatchLineLength/4, len(text)-maxMatchLineLength))
+		text = text[from : from+maxMatchLineLength]
+		m.Start -= from
+		m.End = min(m.End-from, len(text))
+	}
+	m.Text = strings.ToValidUTF8(string(text), "�")
+	return m
+}
+
+type queryOp int
+
+const (
+	queryAll queryOp = iota
+	queryNone
+	queryAnd
+	queryOr
+)
+
+// trigramQuery is a boolean condition on the trigrams a file must contain
+// to possibly match a regular expression.
+type trigramQuery struct {
+	op       queryOp
+	trigrams []string
+	subs     []*trigramQuery
+}
+
+var (
+	matchAll  = &trigramQuery{op: queryAll}
+	matchNone = &trigramQuery{op: queryNone}
+)
+
+func andQuery(x, y *trigramQuery) *trigramQuery {
+	switch {
+	case x.op == queryNone || y.op == queryNone:
+		return matchNone
+	case x.op == queryAll:
+		return y
+	case y.op == queryAll:
+		return x
+	}
+	return &trigramQuery{op: queryAnd, subs: []*trigramQuery{x, y}}
+}
+
+func orQuery(x, y *trigramQuery) *trigramQuery {
+	switch {
+	case x.op == queryAll || y.op == queryAll:
+		return matchAll
+	case x.op == queryNone:
+		return y
+	case y.op == queryNone:
+		return x
+	}
+	return &trigramQuery{op: queryOr, subs: []*trigramQuery{x, y}}
+}
+
+const maxExactSet = 16
+
+// regexpInfo describes what a piece of a regular expression can match:
+// either exactly one of a small set of strings, or anything satisfying a
+// trigram query. Strings are lower-cased to agree with the index.
+type regexpInfo struct {
+	exact []string
+	match *trigramQuery
+}
+
+func (info regexpInfo) query() *trigramQuery {
+	if info.exact == nil {
+		return info.match
+	}
+	if len(info.exact) == 0 {
+		return matchNone
+	}
+	q := matchNone
+	for _, s := range info.exact {
+		if len(s) < 3 {
+			return matchAll
+		}
+		and := &trigramQuery{op: queryAnd}
+		seen := make(map[string]bool)
+		for i := 0; i+3 <= len(s); i++ {
+			if t := s[i : i+3]; !seen[t] {
+				seen[t] = true
+				and.trigrams = append(and.trigrams, t)
+			}
+		}
+		q = orQuery(q, and)
+	}
+	return q
+}
+
+func anyMatch() regexpInfo { return regexpInfo{match: matchAll} }
+
+func exactMatch(s ...string) regexpInfo { return regexpInfo{exact: s} }
+
+func analyzeRegexp(re *syntax.Regexp) regexpInfo {
+	switch re.Op {
+	case syntax.OpNoMatch:
+		return regexpInfo{exact: []string{}}
+	case syntax.OpEmptyMatch, syntax.OpBeginLine, syntax.OpEndLine, syntax.OpBeginText,
+		syntax.OpEndText, syntax.OpWordBoundary, syntax.OpNoWordBoundary:
+		return exactMatch("")
+	case syntax.OpLiteral:
+		return exactMatch(strings.ToLower(string(re.Rune)))
+	case syntax.OpCharClass:
+		var chars []string
+		seen := make(map[string]bool)
+		for i := 0; i+1 < len(re.Rune); i += 2 {
+			if re.Rune[i+1]-re.Rune[i] >= maxExactSet {
+				return anyMatch()
+			}
+			for r := re.Rune[i]; r <= re.Rune[i+1]; r++ {
+				c := strings.ToLower(string(r))
+				if !seen[c] {
+					seen[c] = true
+					chars = append(chars, c)
+				}
+			}
+			if len(chars) > maxExactSet {
+				return anyMatch()
+			}
+		}
+		return exactMatch(chars...)
+	case syntax.OpCapture:
+		return analyzeRegexp(re.Sub[0])
+	case syntax.OpQuest:
+		sub := analyzeRegexp(re.Sub[0])
+		if sub.exact != nil && len(sub.exact) < maxExactSet {
+			return exactMatch(append([]string{""}, sub.exact...)...)
+		}
+		return anyMatch()
+	case syntax.OpPlus:
+		return regexpInfo{match: analyzeRegexp(re.Sub[0]).query()}
+	case syntax.OpRepeat:
+		if re.Min == 0 {
+			return anyMatch()
+		}
+		return regexpInfo{match: analyzeRegexp(re.Sub[0]).query()}
+	case syntax.OpConcat:
+		info := exactMatch("")
+		for _, sub := range re.Sub {
+			info = concatInfo(info, analyzeRegexp(sub))
+		}
+		return info
+	case syntax.OpAlternate:
+		info := regexpInfo{exact: []string{}}
+		for _, sub := range re.Sub {
+			info = alternateInfo(info, analyzeRegexp(sub))
+		}
+		return info
+	}
+	return anyMatch()
+}
+
+func concatInfo(x, y regexpInfo) regexpInfo {
+	if x.exact != nil && y.exact != nil && len(x.exact)*len(y.exact) <= maxExactSet {
+		var exact []string
+		for _, a := range x.exact {
+			for _, b := range y.exact {
+				exact = append(exact, a+b)
+			}
+		}
+		return exactMatch(exact...)
+	}
+	return regexpInfo{match: andQuery(x.query(), y.query())}
+}
+
+func alternateInfo(x, y regexpInfo) regexpInfo {
+	if x.exact != nil && y.exact != nil && len(x.exact)+len(y.exact) <= maxExactSet {
+		return regexpInfo{exact: append(append([]string{}, x.exact...), y.exact...)}
+	}
+	return regexpInfo{match: orQuery(x.query(), y.query())}
+}
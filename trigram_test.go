@@ -0,0 +1,160 @@
+package main
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"sort"
+	"testing"
+	"time"
+)
+
+func newContentTree(t *testing.T) string {
+	t.Helper()
+	t.Setenv("XDG_CACHE_HOME", t.TempDir())
+	root := t.TempDir()
+	writeFiles(t, root, map[string]string{
+		"a.txt":      "hello world\nfoo bar\n",
+		"b.go":       "func Hello() {}\n",
+		"c.md":       "nothing here",
+		"bin.dat":    "\x00\x01hello",
+		".git/HEAD":  "hello ref",
+		"sub/d.conf": "key = world",
+	})
+	return root
+}
+
+func searchFiles(t *testing.T, a *App, root, pattern string) (candidates int, files []string) {
+	t.Helper()
+	result, err := a.SearchContent(SearchContentRequest{Root: root, Pattern: pattern})
+	if err != nil {
+		t.Fatalf("SearchContent(%q): %v", pattern, err)
+	}
+	seen := map[string]bool{}
+	files = []string{}
+	for _, m := range result.Matches {
+		rel, _ := filepath.Rel(root, m.Path)
+		if !seen[rel] {
+			seen[rel] = true
+			files = append(files, filepath.ToSlash(rel))
+		}
+	}
+	sort.Strings(files)
+	return result.Candidates, files
+}
+
+func TestSearchContent(t *testing.T) {
+	root := newContentTree(t)
+	a := NewApp()
+
+	tests := []struct {
+		pattern    string
+		candidates int
+		files      []string
+	}{
+		{"hello", 2, []string{"a.txt"}},
+		{"(?i)hello", 2, []string{"a.txt", "b.go"}},
+		{"wor(ld|d)", 2, []string{"a.txt", "sub/d.conf"}},
+		{"fo+ bar", 1, []string{"a.txt"}},
+		{"xyz|nothing", 1, []string{"c.md"}},
+		{"^key", 1, []string{"sub/d.conf"}},
+		{"missing text", 0, []string{}},
+	}
+	for _, tt := range tests {
+		candidates, files := searchFiles(t, a, root, tt.pattern)
+		if candidates != tt.candidates {
+			t.Errorf("%q: %d candidates, want %d", tt.pattern, candidates, tt.candidates)
+		}
+		if !reflect.DeepEqual(files, tt.files) {
+			t.Errorf("%q: matched %v, want %v", tt.pattern, files, tt.files)
+		}
+	}
+
+	result, _ := a.SearchContent(SearchContentRequest{Root: root, Pattern: "foo"})
+	if len(result.Matches) != 1 || result.Matches[0].Line != 2 || result.Matches[0].Column != 1 || result.Matches[0].Text != "foo bar" {
+		t.Errorf("foo match = %+v", result.Matches)
+	}
+	if _, err := a.SearchContent(SearchContentRequest{Root: root, Pattern: "("}); err == nil {
+		t.Error("invalid pattern succeeded, want error")
+	}
+}
+
+func TestIndexContentIncremental(t *testing.T) {
+	root := newContentTree(t)
+	a := NewApp()
+
+	if _, err := a.IndexContent(RootRequest{Root: root}); err != nil {
+		t.Fatal(err)
+	}
+
+	later := time.Now().Add(time.Hour)
+	bin := filepath.Join(root, "bin.dat")
+	info, err := os.Stat(bin)
+	if err != nil {
+		t.Fatal(err)
+	}
+	tests := []struct {
+		name                              string
+		change                            func()
+		indexed, reused, removed, skipped int
+	}{
+		{"unchanged", func() {}, 0, 4, 0, 1},
+		{"modified", func() {
+			writeFiles(t, root, map[string]string{"b.go": "func Goodbye() {}\n"})
+			os.Chtimes(filepath.Join(root, "b.go"), later, later)
+		}, 1, 3, 0, 1},
+		{"removed", func() { os.Remove(filepath.Join(root, "c.md")) }, 0, 3, 1, 1},
+		// A skipped file isn't read again while its size and time stay.
+		{"skipped rewritten in place", func() {
+			writeFiles(t, root, map[string]string{"bin.dat": "textual"})
+			os.Chtimes(bin, info.ModTime(), info.ModTime())
+		}, 0, 3, 0, 1},
+		{"skipped touched", func() { os.Chtimes(bin, later, later) }, 1, 3, 0, 0},
+	}
+	for _, tt := range tests {
+		tt.change()
+		stats, err := a.IndexContent(RootRequest{Root: root})
+		if err != nil {
+			t.Fatal(err)
+		}
+		if stats.Indexed != tt.indexed || stats.Reused != tt.reused || stats.Removed != tt.removed || stats.Skipped != tt.skipped {
+			t.Errorf("%s: stats = %+v, want indexed %d reused %d removed %d skipped %d", tt.name, stats, tt.indexed, tt.reused, tt.removed, tt.skipped)
+		}
+	}
+
+	// A fresh App reads the saved index and sees the edits.
+	fresh := NewApp()
+	if _, files := searchFiles(t, fresh, root, "(?i)hello"); !reflect.DeepEqual(files, []string{"a.txt"}) {
+		t.Errorf("after reload, hello matched %v", files)
+	}
+	if candidates, _ := searchFiles(t, fresh, root, "Goodbye"); candidates != 1 {
+		t.Errorf("after reload, Goodbye has %d candidates, want 1", candidates)
+	}
+	if candidates, _ := searchFiles(t, fresh, root, "nothing"); candidates != 0 {
+		t.Errorf("removed file is still a candidate")
+	}
+}
+
+func TestWatchContentIndex(t *testing.T) {
+	root := newContentTree(t)
+	a := NewApp()
+	if _, err := a.IndexContent(RootRequest{Root: root}); err != nil {
+		t.Fatal(err)
+	}
+	if err := a.WatchContentIndex(RootRequest{Root: root}); err != nil {
+		t.Fatal(err)
+	}
+	defer a.StopWatchContentIndex()
+
+	writeFiles(t, root, map[string]string{"sub/new.txt": "brand new words"})
+	deadline := time.Now().Add(5 * time.Second)
+	for {
+		if _, files := searchFiles(t, a, root, "brand new"); len(files) == 1 {
+			break
+		}
+		if time.Now().After(deadline) {
+			t.Fatal("watcher never indexed the new file")
+		}
+		time.Sleep(50 * time.Millisecond)
+	}
+}
@@ -0,0 +1,76 @@
+package main
+
+import (
+	"io/fs"
+	"path/filepath"
+
+	"github.com/fsnotify/fsnotify"
+)
+
+// treeWatcher watches root and every directory below it, adding
+// directories as they are created. fsnotify only watches single
+// directories, so recursion is done here.
+type treeWatcher struct {
+	root string
+	w    *fsnotify.Watcher
+	done chan struct{}
+}
+
+func newTreeWatcher(root string, fn func(fsnotify.Event)) (*treeWatcher, error) {
+	w, err := fsnotify.NewWatcher()
+	if err != nil {
+		return nil, err
+	}
+	tw := &treeWatcher{root: root, w: w, done: make(chan struct{})}
+	if err := tw.addTree(root); err != nil {
+		w.Close()
+		return nil, err
+	}
+
+	go func() {
+		defer close(tw.done)
+		for {
+			select {
+			case ev, ok := <-w.Events:
+				if !ok {
+					return
+				}
+				if ev.Has(fsnotify.Create) {
+					tw.addTree(ev.Name)
+				}
+				fn(ev)
+			case _, ok := <-w.Errors:
+				if !ok {
+					return
+				}
+			}
+		}
+	}()
+	return tw, nil
+}
+
+// addTree watches dir and its subdirectories. Only a failure on dir itself
+// is returned; subdirectories that can't be watched are skipped.
+func (tw *treeWatcher) addTree(dir string) error {
+	return filepath.WalkDir(dir, func(path string, d fs.DirEntry, err error) error {
+		if err != nil {
+			if path == dir {
+				return err
+			}
+			return nil
+		}
+		if !d.IsDir() {
+			return nil
+		}
+		if err := tw.w.Add(path); err != nil && path == dir {
+			return err
+		}
+		return nil
+	})
+}
+
+func (tw *treeWatcher) Close() error {
+	err := tw.w.Close()
+	<-tw.done
+	return err
+}